@@ -2,48 +2,161 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/app"
+	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/driver/desktop"
 	"fyne.io/fyne/v2/layout"
 	"fyne.io/fyne/v2/storage"
 	"fyne.io/fyne/v2/widget"
 
 	"github.com/oumi/k2p/internal/config"
 	"github.com/oumi/k2p/internal/converter"
+	"github.com/oumi/k2p/internal/imageprocessing"
 	"github.com/oumi/k2p/internal/orchestrator"
+	"github.com/oumi/k2p/internal/pdf"
+	"github.com/oumi/k2p/internal/version"
 )
 
 func main() {
-	a := app.New()
+	// --version [--json] prints build info without opening the window (for CI scripts)
+	if hasArg("--version") {
+		info := version.Get()
+		if hasArg("--json") {
+			out, err := info.JSON()
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			fmt.Println(out)
+		} else {
+			fmt.Println(info.String())
+		}
+		return
+	}
+
+	// Hidden: --capture-backend synthetic runs the pipeline on generated pages (for CI)
+	captureBackend := argValue("--capture-backend")
+	if _, err := orchestrator.NewOrchestratorForBackend(captureBackend); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2) // usage error
+	}
+
+	// --config <file> loads options from a YAML settings file; non-empty form
+	// fields override it and the defaults fill the rest. The file is read on
+	// every run, so edits apply without restarting
+	configPath := argValue("--config")
+
+	// A stable ID gives the app its own preferences store
+	a := app.NewWithID("com.github.oumi.k2p")
 	w := a.NewWindow("k2p - Kindle to PDF")
 	w.Resize(fyne.NewSize(600, 700))
 
 	// UI Components references (for binding)
 	var (
-		outputDir    *widget.Entry
-		inputFile    *widget.Entry
-		pageTurnKey  *widget.Select
-		quality      *widget.Entry
-		pdfQuality   *widget.Select
-		pageDelay    *widget.Entry
-		startupDelay *widget.Entry
-		trimH        *widget.Entry
-		trimTop      *widget.Entry
-		trimBottom   *widget.Entry
-		verbose      *widget.Check
-		autoConfirm  *widget.Check
-		logArea      *widget.Entry
-		startBtn     *widget.Button
-		statusLabel  *widget.Label
+		outputDir          *widget.Entry
+		onConflict         *widget.Select
+		appendTo           *widget.Entry
+		incrementalPDF     *widget.Entry
+		inputFile          *widget.Entry
+		mergeInputs        *widget.Entry
+		mergeOutput        *widget.Entry
+		imagesInput        *widget.Entry
+		imagesOutput       *widget.Entry
+		pageTurnKey        *widget.Select
+		targetApp          *widget.Entry
+		quality            *widget.Entry
+		captureFormat      *widget.Select
+		pdfQuality         *widget.Select
+		outputFormat       *widget.Select
+		epubChapter        *widget.Entry
+		epubImages         *widget.Check
+		stampPages         *widget.Check
+		stampPos           *widget.Select
+		pageSize           *widget.Select
+		pageMargin         *widget.Entry
+		bgColor            *widget.Entry
+		dpi                *widget.Entry
+		nUp                *widget.Select
+		optimize           *widget.Check
+		pageDelay          *widget.Entry
+		adaptiveDelay      *widget.Check
+		minPageDelay       *widget.Entry
+		maxPageDelay       *widget.Entry
+		startupDelay       *widget.Entry
+		timeout            *widget.Entry
+		commandTimeout     *widget.Entry
+		postCaptureDelay   *widget.Entry
+		activationTries    *widget.Entry
+		spaceSettle        *widget.Entry
+		verifyFirst        *widget.Check
+		endMinPages        *widget.Entry
+		uiEndDetect        *widget.Check
+		stallPages         *widget.Entry
+		pageStep           *widget.Entry
+		sheetColumns       *widget.Entry
+		sheetThumbSize     *widget.Entry
+		maxConcurrency     *widget.Entry
+		inMemoryPages      *widget.Entry
+		directionThreshold *widget.Entry
+		diffRegion         *widget.Entry
+		numberRegion       *widget.Entry
+		numberStallTurns   *widget.Entry
+		similarityAlgo     *widget.Select
+		similaritySize     *widget.Entry
+		trimAggregate      *widget.Select
+		blackThreshold     *widget.Entry
+		whiteThreshold     *widget.Entry
+		trimH              *widget.Entry
+		rotate             *widget.Select
+		autoRotate         *widget.Check
+		uniformPages       *widget.Check
+		invert             *widget.Check
+		normalizePaper     *widget.Check
+		trimTop            *widget.Entry
+		trimBottom         *widget.Entry
+		verbose            *widget.Check
+		dumpScripts        *widget.Check
+		verboseTiming      *widget.Check
+		autoConfirm        *widget.Check
+		batch              *widget.Check
+		watch              *widget.Check
+		stateFile          *widget.Entry
+		webhook            *widget.Entry
+		outputSummary      *widget.Select
+		skipPreflight      *widget.Check
+		allowBlack         *widget.Check
+		firstPageOnly      *widget.Check
+		onFocusLost        *widget.Select
+		focusCache         *widget.Entry
+		turnRetries        *widget.Entry
+		turnRefocus        *widget.Check
+		advanceRetries     *widget.Entry
+		skipCover          *widget.Check
+		noTrimCover        *widget.Check
+		dedupAll           *widget.Check
+		notifyCheck        *widget.Check
+		mdHeadings         *widget.Check
+		mdImageDir         *widget.Entry
+		countdown          *widget.Check
+		logArea            *widget.Entry
+		startBtn           *widget.Button
+		stopBtn            *widget.Button
+		statusLabel        *widget.Label
 	)
 
 	// Get defaults
@@ -68,6 +181,27 @@ func main() {
 		}, w)
 	})
 
+	// An output file that already exists: confirm, replace or keep and version
+	onConflict = widget.NewSelect([]string{"ask", "overwrite", "version"}, nil)
+	onConflict.SetSelected(defaults.OnConflict)
+
+	// Existing PDF the new pages are appended to (generate mode only)
+	appendTo = widget.NewEntry()
+	appendTo.SetPlaceHolder("New PDF")
+	// Keep <output>.partial.pdf up to date every N pages
+	incrementalPDF = widget.NewEntry()
+	incrementalPDF.SetPlaceHolder("0 = off")
+	appendToBtn := widget.NewButton("Browse", func() {
+		fd := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+			if reader != nil {
+				appendTo.SetText(reader.URI().Path())
+				reader.Close()
+			}
+		}, w)
+		fd.SetFilter(storage.NewExtensionFileFilter([]string{".pdf"}))
+		fd.Show()
+	})
+
 	// Input (for pdf2md)
 	inputFile = widget.NewEntry()
 	inputFile.SetPlaceHolder("/path/to/book.pdf")
@@ -81,26 +215,204 @@ func main() {
 		fd.Show()
 	})
 
+	// Merge: one input PDF per line, joined in order
+	mergeInputs = widget.NewMultiLineEntry()
+	mergeInputs.SetPlaceHolder("/path/to/part1.pdf\n/path/to/part2.pdf")
+	mergeInputs.SetMinRowsVisible(4)
+	mergeAddBtn := widget.NewButton("Add PDF...", func() {
+		fd := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+			if reader == nil {
+				return
+			}
+			reader.Close()
+			text := strings.TrimRight(mergeInputs.Text, "\n")
+			if text != "" {
+				text += "\n"
+			}
+			mergeInputs.SetText(text + reader.URI().Path())
+		}, w)
+		fd.SetFilter(storage.NewExtensionFileFilter([]string{".pdf"}))
+		fd.Show()
+	})
+	mergeOutput = widget.NewEntry()
+	mergeOutput.SetPlaceHolder("/path/to/merged.pdf")
+	mergeOutputBtn := widget.NewButton("Save As", func() {
+		fd := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+			if writer != nil {
+				mergeOutput.SetText(writer.URI().Path())
+				writer.Close()
+			}
+		}, w)
+		fd.SetFileName("merged.pdf")
+		fd.Show()
+	})
+
+	// Images: a folder of images or a CBZ/ZIP archive into one PDF
+	imagesInput = widget.NewEntry()
+	imagesInput.SetPlaceHolder("/path/to/book.cbz or image folder")
+	imagesArchiveBtn := widget.NewButton("Archive...", func() {
+		fd := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+			if reader != nil {
+				imagesInput.SetText(reader.URI().Path())
+				reader.Close()
+			}
+		}, w)
+		fd.SetFilter(storage.NewExtensionFileFilter([]string{".cbz", ".zip"}))
+		fd.Show()
+	})
+	imagesFolderBtn := widget.NewButton("Folder...", func() {
+		dialog.ShowFolderOpen(func(uri fyne.ListableURI, err error) {
+			if uri != nil {
+				imagesInput.SetText(uri.Path())
+			}
+		}, w)
+	})
+	imagesOutput = widget.NewEntry()
+	imagesOutput.SetPlaceHolder("/path/to/book.pdf")
+	imagesOutputBtn := widget.NewButton("Save As", func() {
+		fd := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+			if writer != nil {
+				imagesOutput.SetText(writer.URI().Path())
+				writer.Close()
+			}
+		}, w)
+		fd.SetFileName("book.pdf")
+		fd.Show()
+	})
+
 	// Options
 	pageTurnKey = widget.NewSelect([]string{"Auto (Right/Left)", "Right", "Left"}, nil)
 	pageTurnKey.SetSelected("Auto (Right/Left)")
+	// Reader app to capture; any app that turns pages with the arrow keys
+	targetApp = widget.NewEntry()
+	targetApp.SetText(defaults.TargetApp)
 
 	quality = widget.NewEntry()
 	quality.SetText(strconv.Itoa(defaults.ScreenshotQuality))
+	// BMP captures are faster to write; pages are encoded afterwards
+	captureFormat = widget.NewSelect([]string{"png", "bmp"}, nil)
+	captureFormat.SetSelected(defaults.CaptureFormat)
 
 	pdfQuality = widget.NewSelect([]string{"High", "Medium", "Low"}, nil)
 	// Capitalize default for UI match (internal is "high", UI is "High")
 	// Or just default to High since we know it
 	pdfQuality.SetSelected("High")
 
+	// Output format (EPUB runs OCR on each page)
+	outputFormat = widget.NewSelect([]string{"PDF", "EPUB"}, nil)
+	outputFormat.SetSelected("PDF")
+
+	epubChapter = widget.NewEntry()
+	epubChapter.SetText(strconv.Itoa(defaults.EPUBPagesPerChapter))
+	epubImages = widget.NewCheck("Embed Page Images", nil)
+
+	stampPages = widget.NewCheck("Stamp Page Numbers", nil)
+	stampPos = widget.NewSelect(pdf.ValidStampPositions, nil)
+	stampPos.SetSelected(defaults.StampPosition)
+
+	pageSize = widget.NewSelect(pdf.ValidPageSizes, nil)
+	pageSize.SetSelected(defaults.PageSize)
+	pageMargin = widget.NewEntry()
+	pageMargin.SetText("0")
+	// Letterbox and padding colour, e.g. #000000 for dark comics
+	bgColor = widget.NewEntry()
+	bgColor.SetPlaceHolder("#RRGGBB (white)")
+	dpi = widget.NewEntry()
+	dpi.SetPlaceHolder("DPI (auto)")
+	nUp = widget.NewSelect([]string{"1", "2", "4"}, nil)
+	nUp.SetSelected(strconv.Itoa(defaults.NUp))
+	optimize = widget.NewCheck("Optimize (qpdf/gs)", nil)
+
 	pageDelay = widget.NewEntry()
 	// Convert duration to int ms
 	pageDelay.SetText(strconv.Itoa(int(defaults.PageDelay.Milliseconds())))
 
+	// Adaptive pacing: poll for the new page between the min and max delays
+	adaptiveDelay = widget.NewCheck("Adaptive", nil)
+	minPageDelay = widget.NewEntry()
+	minPageDelay.SetText(strconv.Itoa(int(defaults.MinPageDelay.Milliseconds())))
+	maxPageDelay = widget.NewEntry()
+	maxPageDelay.SetText(strconv.Itoa(int(defaults.MaxPageDelay.Milliseconds())))
+
 	startupDelay = widget.NewEntry()
 	// Convert duration to int seconds
 	startupDelay.SetText(strconv.Itoa(int(defaults.StartupDelay.Seconds())))
 
+	// Whole-conversion deadline in minutes
+	timeout = widget.NewEntry()
+	timeout.SetPlaceHolder("0 = none")
+
+	// Per osascript/screencapture call, in seconds
+	commandTimeout = widget.NewEntry()
+	commandTimeout.SetText(strconv.Itoa(int(defaults.CommandTimeout.Seconds())))
+
+	// Wait after the PDF is written so the screen recording indicator clears, in ms (0 = none)
+	postCaptureDelay = widget.NewEntry()
+	postCaptureDelay.SetText(strconv.Itoa(int(defaults.PostCaptureDelay.Milliseconds())))
+
+	// Kindle activations tried before giving up (slow Space switches)
+	activationTries = widget.NewEntry()
+	activationTries.SetText(strconv.Itoa(defaults.ActivationAttempts))
+	// Extra wait after the switch to Kindle's Space, in ms
+	spaceSettle = widget.NewEntry()
+	spaceSettle.SetPlaceHolder("0 ms")
+	// Recaptures the first page while it still shows the Space transition
+	verifyFirst = widget.NewCheck("Verify First Page", nil)
+
+	endMinPages = widget.NewEntry()
+	endMinPages.SetText(strconv.Itoa(defaults.EndDetectionMinPages))
+	uiEndDetect = widget.NewCheck("Detect End Screen", nil)
+	// Stop after this many near-identical pages in a row
+	stallPages = widget.NewEntry()
+	stallPages.SetPlaceHolder("Stall pages (0 = off)")
+	pageStep = widget.NewEntry()
+	pageStep.SetPlaceHolder("1 (every page)")
+	sheetColumns = widget.NewEntry()
+	sheetColumns.SetText(strconv.Itoa(defaults.ContactSheetColumns))
+	sheetThumbSize = widget.NewEntry()
+	sheetThumbSize.SetText(strconv.Itoa(defaults.ContactSheetThumbSize))
+
+	maxConcurrency = widget.NewEntry()
+	maxConcurrency.SetText(strconv.Itoa(defaults.MaxConcurrency))
+	// Write the PDF from decoded pages when the book has at most this many
+	inMemoryPages = widget.NewEntry()
+	inMemoryPages.SetPlaceHolder("0 = off")
+
+	directionThreshold = widget.NewEntry()
+	directionThreshold.SetText(strconv.FormatFloat(defaults.DirectionChangeThreshold, 'f', -1, 64))
+
+	diffRegion = widget.NewEntry()
+	diffRegion.SetPlaceHolder("x,y,w,h (whole page)")
+	// Page indicator area; the book ends when it stops changing
+	numberRegion = widget.NewEntry()
+	numberRegion.SetPlaceHolder("x,y,w,h (off)")
+	numberStallTurns = widget.NewEntry()
+	numberStallTurns.SetText(strconv.Itoa(defaults.PageNumberStallTurns))
+
+	similarityAlgo = widget.NewSelect(imageprocessing.ValidSimilarityAlgorithms, nil)
+	similarityAlgo.SetSelected(defaults.SimilarityAlgorithm)
+	similaritySize = widget.NewEntry()
+	similaritySize.SetText(strconv.Itoa(defaults.SimilaritySize))
+
+	trimAggregate = widget.NewSelect([]string{"min", "p10", "p25"}, nil)
+	trimAggregate.SetSelected(defaults.TrimAggregate)
+
+	// Border color thresholds for margin detection (8-bit levels)
+	blackThreshold = widget.NewEntry()
+	blackThreshold.SetText(strconv.Itoa(defaults.BlackThreshold))
+	whiteThreshold = widget.NewEntry()
+	whiteThreshold.SetText(strconv.Itoa(defaults.WhiteThreshold))
+
+	// Clockwise rotation, applied before trimming
+	rotate = widget.NewSelect([]string{"0", "90", "180", "270"}, nil)
+	rotate.SetSelected("0")
+	// Turns pages against the book's orientation (e.g. spreads) to match it
+	autoRotate = widget.NewCheck("Auto-Rotate", nil)
+	// Pads pages to the largest page size after trimming
+	uniformPages = widget.NewCheck("Uniform Pages", nil)
+	invert = widget.NewCheck("Invert (Dark Mode)", nil)
+	normalizePaper = widget.NewCheck("White Paper (Sepia)", nil)
+
 	// Trimming
 	trimH = widget.NewEntry()
 	trimH.SetText("0")
@@ -111,7 +423,245 @@ func main() {
 
 	// Flags
 	verbose = widget.NewCheck("Verbose Logging", nil)
+	dumpScripts = widget.NewCheck("Dump AppleScript", nil)
+	verboseTiming = widget.NewCheck("Stage Timing", nil)
 	autoConfirm = widget.NewCheck("Auto Confirm", nil)
+	batch = widget.NewCheck("Batch (multiple books)", nil)
+	watch = widget.NewCheck("Watch for new books", nil)
+	// Books converted by batch/watch, skipped after a restart (empty: off)
+	stateFile = widget.NewEntry()
+	stateFile.SetPlaceHolder("e.g. <Output Dir>/" + orchestrator.StateFileName + " (empty = off)")
+	// JSON summary POSTed after every conversion
+	webhook = widget.NewEntry()
+	webhook.SetPlaceHolder("https://example.local/hook (optional)")
+	// Final summary in the log: human-readable block or one JSON line
+	outputSummary = widget.NewSelect([]string{"text", "json"}, nil)
+	outputSummary.SetSelected(defaults.OutputSummary)
+	skipPreflight = widget.NewCheck("Skip Preflight", nil)
+	allowBlack = widget.NewCheck("Allow Black First Page", nil)
+	// A per-run choice like Batch and Watch, so it is not persisted
+	firstPageOnly = widget.NewCheck("First Page Only", nil)
+	onFocusLost = widget.NewSelect([]string{"abort", "pause", "refocus"}, nil)
+	onFocusLost.SetSelected(defaults.OnFocusLost)
+	focusCache = widget.NewEntry()
+	focusCache.SetPlaceHolder("Cache ms (0 = off)")
+	// Page turn attempts; Refocus brings Kindle back between them
+	turnRetries = widget.NewEntry()
+	turnRetries.SetText(strconv.Itoa(defaults.TurnRetries))
+	turnRefocus = widget.NewCheck("Refocus", nil)
+	// Extra turns when a capture still shows the previous page
+	advanceRetries = widget.NewEntry()
+	advanceRetries.SetPlaceHolder("Re-turns (0 = off)")
+	skipCover = widget.NewCheck("Skip Cover", nil)
+	noTrimCover = widget.NewCheck("Don't Trim Cover", nil)
+	dedupAll = widget.NewCheck("Dedup All", nil)
+	notifyCheck = widget.NewCheck("Notify", nil)
+	countdown = widget.NewCheck("Countdown", nil)
+	// PDF to Markdown structure
+	mdHeadings = widget.NewCheck("Detect Headings", nil)
+	mdImageDir = widget.NewEntry()
+	mdImageDir.SetPlaceHolder("/path/to/images (optional)")
+	countdown.SetChecked(defaults.ShowCountdown)
+
+	// Restore last-used settings and save each change. The widgets above hold
+	// the ApplyDefaults values, so unset keys keep the defaults, and an emptied
+	// numeric field parses as 0, which ApplyDefaults again treats as "default".
+	// Batch and Watch are per-run choices and are not persisted
+	prefs := a.Preferences()
+	// Page Step was saved as "captureEvery" by earlier builds
+	migratePreference(prefs, "captureEvery", "pageStep")
+	for key, e := range map[string]*widget.Entry{
+		"outputDir":                outputDir,
+		"inputFile":                inputFile,
+		"screenshotQuality":        quality,
+		"epubPagesPerChapter":      epubChapter,
+		"pageMargin":               pageMargin,
+		"backgroundColor":          bgColor,
+		"dpi":                      dpi,
+		"pageDelayMs":              pageDelay,
+		"minPageDelayMs":           minPageDelay,
+		"maxPageDelayMs":           maxPageDelay,
+		"startupDelaySec":          startupDelay,
+		"timeoutMin":               timeout,
+		"commandTimeoutSec":        commandTimeout,
+		"postCaptureDelayMs":       postCaptureDelay,
+		"activationAttempts":       activationTries,
+		"spaceSwitchSettleMs":      spaceSettle,
+		"endDetectionMinPages":     endMinPages,
+		"stallPages":               stallPages,
+		"pageStep":                 pageStep,
+		"contactSheetColumns":      sheetColumns,
+		"contactSheetThumbSize":    sheetThumbSize,
+		"foregroundCacheMs":        focusCache,
+		"turnRetries":              turnRetries,
+		"advanceRetries":           advanceRetries,
+		"incrementalPDF":           incrementalPDF,
+		"maxConcurrency":           maxConcurrency,
+		"inMemoryPages":            inMemoryPages,
+		"directionChangeThreshold": directionThreshold,
+		"diffRegion":               diffRegion,
+		"pageNumberRegion":         numberRegion,
+		"pageNumberStallTurns":     numberStallTurns,
+		"similaritySize":           similaritySize,
+		"stateFile":                stateFile,
+		"webhook":                  webhook,
+		"targetApp":                targetApp,
+		"markdownImageDir":         mdImageDir,
+		"trimHorizontal":           trimH,
+		"trimTop":                  trimTop,
+		"trimBottom":               trimBottom,
+		"blackThreshold":           blackThreshold,
+		"whiteThreshold":           whiteThreshold,
+	} {
+		persistEntry(prefs, key, e)
+	}
+	for key, sel := range map[string]*widget.Select{
+		"pageTurnKey":         pageTurnKey,
+		"pdfQuality":          pdfQuality,
+		"outputFormat":        outputFormat,
+		"outputSummary":       outputSummary,
+		"stampPosition":       stampPos,
+		"pageSize":            pageSize,
+		"nUp":                 nUp,
+		"onFocusLost":         onFocusLost,
+		"onConflict":          onConflict,
+		"similarityAlgorithm": similarityAlgo,
+		"captureFormat":       captureFormat,
+		"trimAggregate":       trimAggregate,
+		"rotate":              rotate,
+	} {
+		persistSelect(prefs, key, sel)
+	}
+	for key, c := range map[string]*widget.Check{
+		"epubEmbedImages":     epubImages,
+		"stampPageNumbers":    stampPages,
+		"optimize":            optimize,
+		"verbose":             verbose,
+		"dumpAppleScript":     dumpScripts,
+		"verboseTiming":       verboseTiming,
+		"autoConfirm":         autoConfirm,
+		"skipPreflight":       skipPreflight,
+		"allowBlackFirstPage": allowBlack,
+		"turnRefocus":         turnRefocus,
+		"skipCover":           skipCover,
+		"noTrimCover":         noTrimCover,
+		"dedupAll":            dedupAll,
+		"notify":              notifyCheck,
+		"markdownHeadings":    mdHeadings,
+		"uiEndDetection":      uiEndDetect,
+		"autoRotate":          autoRotate,
+		"uniformPages":        uniformPages,
+		"verifyFirstCapture":  verifyFirst,
+		"adaptiveDelay":       adaptiveDelay,
+		"invert":              invert,
+		"normalizePaper":      normalizePaper,
+		"countdown":           countdown,
+	} {
+		persistCheck(prefs, key, c)
+	}
+
+	// Presets fill the quality, delay, trimming and layout fields
+	// Saved presets are kept in preferences next to the built-in ones
+	savedPresets := loadSavedPresets(prefs)
+	presetNames := func() []string {
+		var names []string
+		for _, p := range config.BuiltinPresets {
+			names = append(names, p.Name)
+		}
+		var saved []string
+		for name := range savedPresets {
+			saved = append(saved, name)
+		}
+		sort.Strings(saved)
+		return append(names, saved...)
+	}
+	applyPreset := func(o config.ConversionOptions) {
+		quality.SetText(strconv.Itoa(o.ScreenshotQuality))
+		if o.PDFQuality != "" {
+			pdfQuality.SetSelected(strings.ToUpper(o.PDFQuality[:1]) + o.PDFQuality[1:])
+		}
+		pageDelay.SetText(strconv.Itoa(int(o.PageDelay.Milliseconds())))
+		trimH.SetText(strconv.Itoa(o.TrimHorizontal))
+		trimTop.SetText(strconv.Itoa(o.TrimTop))
+		trimBottom.SetText(strconv.Itoa(o.TrimBottom))
+		if o.PageSize != "" {
+			pageSize.SetSelected(o.PageSize)
+		}
+		if o.NUp != 0 {
+			nUp.SetSelected(strconv.Itoa(o.NUp))
+		}
+	}
+	presetOptions := func() config.ConversionOptions {
+		atoi := func(e *widget.Entry) int {
+			val, _ := strconv.Atoi(e.Text)
+			return val
+		}
+		nUpValue, _ := strconv.Atoi(nUp.Selected)
+		return config.ConversionOptions{
+			ScreenshotQuality: atoi(quality),
+			PDFQuality:        strings.ToLower(pdfQuality.Selected),
+			PageDelay:         time.Duration(atoi(pageDelay)) * time.Millisecond,
+			TrimHorizontal:    atoi(trimH),
+			TrimTop:           atoi(trimTop),
+			TrimBottom:        atoi(trimBottom),
+			PageSize:          pageSize.Selected,
+			NUp:               nUpValue,
+		}
+	}
+	presetSelect := widget.NewSelect(presetNames(), func(name string) {
+		if o, ok := savedPresets[name]; ok {
+			applyPreset(o)
+		} else if p, ok := config.FindBuiltinPreset(name); ok {
+			applyPreset(p.Options)
+		}
+	})
+	presetSelect.PlaceHolder = "(current settings)"
+	savePresetBtn := widget.NewButton("Save as Preset", func() {
+		name := widget.NewEntry()
+		dialog.ShowForm("Save Preset", "Save", "Cancel",
+			[]*widget.FormItem{widget.NewFormItem("Name", name)},
+			func(ok bool) {
+				presetName := strings.TrimSpace(name.Text)
+				if !ok || presetName == "" {
+					return
+				}
+				if _, builtin := config.FindBuiltinPreset(presetName); builtin {
+					dialog.ShowError(fmt.Errorf("%q is a built-in preset; choose another name", presetName), w)
+					return
+				}
+				savedPresets[presetName] = presetOptions()
+				saveSavedPresets(prefs, savedPresets)
+				presetSelect.SetOptions(presetNames())
+				presetSelect.SetSelected(presetName)
+			}, w)
+	})
+
+	// Rough output size for the current quality and trimming; the total needs
+	// the page count, which is only known after capturing
+	estimateLabel := widget.NewLabel("")
+	updateEstimate := func() {
+		atoi := func(e *widget.Entry) int {
+			val, _ := strconv.Atoi(e.Text)
+			return val
+		}
+		opts := config.ApplyDefaults(&config.ConversionOptions{
+			ScreenshotQuality: atoi(quality),
+			TrimHorizontal:    atoi(trimH),
+			TrimTop:           atoi(trimTop),
+			TrimBottom:        atoi(trimBottom),
+		})
+		perPage := float64(orchestrator.EstimatePageBytes(opts)) / (1024 * 1024)
+		per200 := float64(orchestrator.EstimateOutputBytes(opts, 200)) / (1024 * 1024)
+		estimateLabel.SetText(fmt.Sprintf("~%.1f MB/page, total unknown (~%.0f MB per 200 pages)", perPage, per200))
+	}
+	for _, e := range []*widget.Entry{quality, trimH, trimTop, trimBottom} {
+		persist := e.OnChanged
+		e.OnChanged = func(text string) {
+			persist(text)
+			updateEstimate()
+		}
+	}
+	updateEstimate()
 
 	// --- 2. Layouts ---
 
@@ -128,21 +678,57 @@ func main() {
 		return container.New(layout.NewFormLayout(), widget.NewLabel(label), content)
 	}
 
+	// Runs trim-preview on one image with the trim fields (handler attached below)
+	trimPreviewBtn := widget.NewButton("Preview Trim...", nil)
+
 	// Tab 1: Generate PDF
 	tabGenerate := container.NewVBox(
 		widget.NewLabelWithStyle("Generate PDF from Kindle", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		formRow("Preset:", presetSelect, savePresetBtn),
 		formRow("Output Dir:", outputDir, outputDirBtn),
+		formRow("If File Exists:", onConflict),
+		formRow("Append To:", appendTo, appendToBtn),
+		formRow("Partial PDF every:", incrementalPDF),
 		widget.NewSeparator(),
 		widget.NewLabel("Trimming (Pixels):"),
+		formRow("Rotate (°):", rotate, autoRotate, uniformPages),
+		formRow("Colors:", invert, normalizePaper),
 		formRow("Horizontal:", trimH),
 		formRow("Top / Bottom:", trimTop, trimBottom),
+		formRow("Check:", trimPreviewBtn),
+		formRow("Est. Size:", estimateLabel),
 		widget.NewSeparator(),
 		widget.NewLabel("Settings:"),
+		formRow("Reader App:", targetApp),
 		formRow("Page Turn:", pageTurnKey),
-		formRow("Qual (1-100):", quality),
+		formRow("Qual (1-100):", quality, captureFormat),
 		formRow("PDF Qual:", pdfQuality),
+		formRow("Format:", outputFormat),
+		formRow("EPUB Chapter:", epubChapter, epubImages),
+		formRow("Page Numbers:", stampPages, stampPos),
+		formRow("Page / Margin / DPI:", pageSize, pageMargin, dpi),
+		formRow("Background:", bgColor),
+		formRow("Pages / Sheet:", nUp, optimize),
 		formRow("Delays (ms/s):", pageDelay, startupDelay),
-		container.NewHBox(verbose, autoConfirm),
+		formRow("Adaptive (min/max ms):", adaptiveDelay, minPageDelay, maxPageDelay),
+		formRow("Timeouts (min/s):", timeout, commandTimeout),
+		formRow("Post-Capture Delay (ms):", postCaptureDelay),
+		formRow("Activation Tries / Settle:", activationTries, spaceSettle, verifyFirst),
+		formRow("End Min Pages:", endMinPages, uiEndDetect, stallPages),
+		formRow("Page Step:", pageStep),
+		formRow("Max Workers / In-Memory Pages:", maxConcurrency, inMemoryPages),
+		formRow("Turn Threshold:", directionThreshold),
+		formRow("Diff Region:", diffRegion),
+		formRow("Page No. Region / Turns:", numberRegion, numberStallTurns),
+		formRow("Similarity:", similarityAlgo, similaritySize),
+		container.NewHBox(verbose, dumpScripts, verboseTiming, autoConfirm, batch, watch),
+		formRow("State File:", stateFile),
+		formRow("Webhook:", webhook),
+		formRow("Summary:", outputSummary),
+		container.NewHBox(skipPreflight, allowBlack, firstPageOnly),
+		formRow("Focus Lost:", onFocusLost, focusCache),
+		formRow("Turn Retries:", turnRetries, turnRefocus, advanceRetries),
+		container.NewHBox(skipCover, noTrimCover, countdown, dedupAll, notifyCheck),
 	)
 
 	// Tab 2: Detect Margins
@@ -152,6 +738,9 @@ func main() {
 		widget.NewSeparator(),
 		formRow("Page Turn:", pageTurnKey),
 		formRow("Delays (ms/s):", pageDelay, startupDelay),
+		formRow("Aggregate:", trimAggregate),
+		formRow("Black / White:", blackThreshold, whiteThreshold),
+		formRow("Rotate (°):", rotate),
 		container.NewHBox(verbose, autoConfirm),
 		widget.NewSeparator(),
 		widget.NewLabelWithStyle("Result:", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
@@ -171,18 +760,74 @@ func main() {
 		widget.NewLabelWithStyle("PDF to Markdown", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
 		formRow("Input PDF:", inputFile, inputFileBtn),
 		formRow("Output Dir:", outputDir, outputDirBtn), // Reuse output dir
+		formRow("Images Dir:", mdImageDir),
+		container.NewHBox(mdHeadings),
+	)
+
+	// Tab 4: Merge PDFs
+	tabMerge := container.NewVBox(
+		widget.NewLabelWithStyle("Merge PDFs", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		widget.NewLabel("Joins the PDFs in the listed order (e.g. parts of a split book)."),
+		formRow("Input PDFs:", mergeInputs, mergeAddBtn),
+		formRow("Output PDF:", mergeOutput, mergeOutputBtn),
+	)
+
+	// Tab 5: Images to PDF
+	tabImages := container.NewVBox(
+		widget.NewLabelWithStyle("Images to PDF", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		widget.NewLabel("Converts a folder of PNG/JPEG images or a CBZ/ZIP archive, in file name order."),
+		formRow("Input:", imagesInput, imagesArchiveBtn, imagesFolderBtn),
+		formRow("Output PDF:", imagesOutput, imagesOutputBtn),
+	)
+
+	// Tab 6: Contact Sheet
+	tabContactSheet := container.NewVBox(
+		widget.NewLabelWithStyle("Contact Sheet", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		widget.NewLabel("Captures the book and tiles page thumbnails on PDF sheets."),
+		widget.NewSeparator(),
+		formRow("Columns:", sheetColumns),
+		formRow("Thumbnail (px):", sheetThumbSize),
+		formRow("Page Step:", pageStep),
+		formRow("Page Turn:", pageTurnKey),
+		formRow("Page Size:", pageSize),
+		container.NewHBox(verbose, autoConfirm),
+	)
+
+	// Tab 7: Benchmark Page Delay
+	benchLabel := widget.NewLabel("")
+	benchLabel.TextStyle = fyne.TextStyle{Monospace: true}
+	tabBenchmark := container.NewVBox(
+		widget.NewLabelWithStyle("Benchmark Page Delay", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		widget.NewLabel("Turns about 10 pages with delays from 1000ms down to 200ms."),
+		widget.NewSeparator(),
+		formRow("Page Turn:", pageTurnKey),
+		container.NewHBox(verbose),
+		widget.NewSeparator(),
+		widget.NewLabelWithStyle("Result:", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		benchLabel,
 	)
 
 	tabs := container.NewAppTabs(
 		container.NewTabItem("Generate", container.NewPadded(tabGenerate)),
 		container.NewTabItem("Detect", container.NewPadded(tabDetect)),
 		container.NewTabItem("PDF2MD", container.NewPadded(tabPdf2Md)),
+		container.NewTabItem("Merge", container.NewPadded(tabMerge)),
+		container.NewTabItem("Images", container.NewPadded(tabImages)),
+		container.NewTabItem("Contact Sheet", container.NewPadded(tabContactSheet)),
+		container.NewTabItem("Benchmark", container.NewPadded(tabBenchmark)),
 	)
 
 	// --- 3. Logs & Actions ---
 	logArea = widget.NewMultiLineEntry()
 	logArea.TextStyle = fyne.TextStyle{Monospace: true}
 	logArea.Disable() // Read-only
+	// Unchecked: the view stays put while new logs arrive
+	autoScroll := widget.NewCheck("Auto-scroll", nil)
+	autoScroll.SetChecked(true)
+	copyLogsBtn := widget.NewButton("Copy Logs", func() {
+		a.Clipboard().SetContent(logArea.Text)
+		statusLabel.SetText("Logs copied to clipboard")
+	})
 	logScroll := container.NewScroll(logArea)
 	logScroll.SetMinSize(fyne.NewSize(0, 200))
 
@@ -192,12 +837,39 @@ func main() {
 	startBtn = widget.NewButton("Start Conversion", nil) // Handler attached below
 	startBtn.Importance = widget.HighImportance
 
+	// Stop cancels the running conversion (the only way to end watch mode)
+	var cancelRun context.CancelFunc
+	stopBtn = widget.NewButton("Stop", func() {
+		if cancelRun != nil {
+			cancelRun()
+		}
+	})
+	stopBtn.Disable()
+
+	// Preview of the first and latest captured pages, to spot a capture of the
+	// desktop instead of Kindle right away
+	newPreview := func() *canvas.Image {
+		img := canvas.NewImageFromImage(nil)
+		img.FillMode = canvas.ImageFillContain
+		img.SetMinSize(fyne.NewSize(90, 120))
+		return img
+	}
+	firstPreview := newPreview()
+	latestPreview := newPreview()
+	previewLabel := widget.NewLabel("")
+	previewBox := container.NewHBox(
+		container.NewVBox(widget.NewLabel("First"), firstPreview),
+		container.NewVBox(widget.NewLabel("Latest"), latestPreview),
+		previewLabel,
+	)
+
 	// Better layout: Top part is tabs, Bottom is logs.
 	// We want logs to expand.
 	split := container.NewVSplit(
 		tabs,
 		container.NewBorder(
-			container.NewVBox(startBtn, statusLabel, widget.NewLabel("Logs:")),
+			container.NewVBox(container.NewGridWithColumns(2, startBtn, stopBtn), statusLabel, previewBox,
+				container.NewHBox(widget.NewLabel("Logs:"), layout.NewSpacer(), autoScroll, copyLogsBtn)),
 			nil, nil, nil,
 			logScroll,
 		),
@@ -206,22 +878,40 @@ func main() {
 
 	w.SetContent(split)
 
+	// Dropping a PDF onto the window fills the PDF2MD input and switches to it
+	w.SetOnDropped(func(_ fyne.Position, uris []fyne.URI) {
+		if len(uris) == 0 {
+			return
+		}
+		uri := uris[0]
+		if !strings.EqualFold(uri.Extension(), ".pdf") {
+			dialog.ShowError(fmt.Errorf("%s is not a PDF file", uri.Name()), w)
+			return
+		}
+		inputFile.SetText(uri.Path())
+		selectTab(tabs, "PDF2MD")
+	})
+
 	// --- 4. Logic ---
 
 	// Log Writer
-	logWriter := &uiWriter{entry: logArea}
-
-	startBtn.OnTapped = func() {
-		startBtn.Disable()
-		statusLabel.SetText("Running...")
-		logArea.SetText("") // Clear logs
+	logWriter := &uiWriter{entry: logArea, autoScroll: autoScroll}
 
-		// Collect Config
+	// collectOptions builds the conversion options from the form for the selected tab
+	collectOptions := func() *config.ConversionOptions {
 		mode := "generate"
 		if tabs.Selected().Text == "Detect" {
 			mode = "detect"
 		} else if tabs.Selected().Text == "PDF2MD" {
 			mode = "pdf2md"
+		} else if tabs.Selected().Text == "Merge" {
+			mode = "merge"
+		} else if tabs.Selected().Text == "Images" {
+			mode = "images2pdf"
+		} else if tabs.Selected().Text == "Contact Sheet" {
+			mode = "contact-sheet"
+		} else if tabs.Selected().Text == "Benchmark" {
+			mode = "benchmark"
 		}
 
 		// Helper to parse int
@@ -229,95 +919,266 @@ func main() {
 			val, _ := strconv.Atoi(e.Text)
 			return val
 		}
+		parseFloat := func(e *widget.Entry) float64 {
+			val, _ := strconv.ParseFloat(e.Text, 64)
+			return val
+		}
+		// An empty field leaves the option unset; 0 is kept
+		parseOptionalMillis := func(e *widget.Entry) *time.Duration {
+			if strings.TrimSpace(e.Text) == "" {
+				return nil
+			}
+			d := time.Duration(parseInt(e)) * time.Millisecond
+			return &d
+		}
 
 		// Helper for page turn
-		ptKey := "right"
-		if pageTurnKey.Selected == "Left" {
+		ptKey := "auto"
+		switch pageTurnKey.Selected {
+		case "Right":
+			ptKey = "right"
+		case "Left":
 			ptKey = "left"
 		}
-		// "Auto" -> "right" (orchestrator handles auto-detection logic if configured)
-		// Wait, Orchestrator expects "right" or "left".
-		// If "Auto" is selected, we should verify what orchestrator expects.
-		// Current logic in Orchestrator: if options.PageTurnKey != "left", it attempts auto-detect.
-		// So passing "right" (default) allows auto-detect.
+
+		nUpValue, _ := strconv.Atoi(nUp.Selected)
+		rotateValue, _ := strconv.Atoi(rotate.Selected)
+
+		// Merge and images2pdf take their own input and output file
+		input, output := inputFile.Text, ""
+		if mode == "merge" {
+			input, output = mergeInputs.Text, strings.TrimSpace(mergeOutput.Text)
+		} else if mode == "images2pdf" {
+			input, output = strings.TrimSpace(imagesInput.Text), strings.TrimSpace(imagesOutput.Text)
+		}
+
+		// Only generate mode writes a PDF that can be appended to
+		appendPath := ""
+		if mode == "generate" {
+			appendPath = strings.TrimSpace(appendTo.Text)
+		}
 
 		opts := &config.ConversionOptions{
-			OutputDir:         outputDir.Text,
-			Mode:              mode,
-			InputFile:         inputFile.Text,
-			PageTurnKey:       ptKey,
-			ScreenshotQuality: parseInt(quality),
-			PDFQuality:        strings.ToLower(pdfQuality.Selected),
-			PageDelay:         time.Duration(parseInt(pageDelay)) * time.Millisecond,
-			StartupDelay:      time.Duration(parseInt(startupDelay)) * time.Second,
-			TrimHorizontal:    parseInt(trimH),
-			TrimTop:           parseInt(trimTop),
-			TrimBottom:        parseInt(trimBottom),
-			Verbose:           verbose.Checked,
+			OutputDir:                outputDir.Text,
+			AppendTo:                 appendPath,
+			IncrementalPDF:           parseInt(incrementalPDF),
+			Mode:                     mode,
+			InputFile:                input,
+			OutputFile:               output,
+			MarkdownHeadings:         mdHeadings.Checked,
+			MarkdownImageDir:         strings.TrimSpace(mdImageDir.Text),
+			PageTurnKey:              ptKey,
+			TargetApp:                strings.TrimSpace(targetApp.Text),
+			ScreenshotQuality:        parseInt(quality),
+			CaptureFormat:            captureFormat.Selected,
+			PDFQuality:               strings.ToLower(pdfQuality.Selected),
+			OutputFormat:             strings.ToLower(outputFormat.Selected),
+			EPUBPagesPerChapter:      parseInt(epubChapter),
+			EPUBEmbedImages:          epubImages.Checked,
+			StampPageNumbers:         stampPages.Checked,
+			StampPosition:            stampPos.Selected,
+			PageSize:                 pageSize.Selected,
+			PageMargin:               float64(parseInt(pageMargin)),
+			BackgroundColor:          bgColor.Text,
+			DPI:                      parseInt(dpi),
+			NUp:                      nUpValue,
+			ContactSheetColumns:      parseInt(sheetColumns),
+			ContactSheetThumbSize:    parseInt(sheetThumbSize),
+			Optimize:                 optimize.Checked,
+			PageDelay:                time.Duration(parseInt(pageDelay)) * time.Millisecond,
+			AdaptiveDelay:            adaptiveDelay.Checked,
+			MinPageDelay:             time.Duration(parseInt(minPageDelay)) * time.Millisecond,
+			MaxPageDelay:             time.Duration(parseInt(maxPageDelay)) * time.Millisecond,
+			StartupDelay:             time.Duration(parseInt(startupDelay)) * time.Second,
+			Timeout:                  time.Duration(parseInt(timeout)) * time.Minute,
+			CommandTimeout:           time.Duration(parseInt(commandTimeout)) * time.Second,
+			PostCaptureDelay:         parseOptionalMillis(postCaptureDelay),
+			ActivationAttempts:       parseInt(activationTries),
+			SpaceSwitchSettle:        time.Duration(parseInt(spaceSettle)) * time.Millisecond,
+			VerifyFirstCapture:       verifyFirst.Checked,
+			EndDetectionMinPages:     parseInt(endMinPages),
+			UIEndDetection:           uiEndDetect.Checked,
+			StallPages:               parseInt(stallPages),
+			PageStep:                 parseInt(pageStep),
+			MaxConcurrency:           parseInt(maxConcurrency),
+			InMemoryPages:            parseInt(inMemoryPages),
+			DirectionChangeThreshold: parseFloat(directionThreshold),
+			DiffRegion:               diffRegion.Text,
+			PageNumberRegion:         strings.TrimSpace(numberRegion.Text),
+			PageNumberStallTurns:     parseInt(numberStallTurns),
+			StateFile:                strings.TrimSpace(stateFile.Text),
+			Webhook:                  strings.TrimSpace(webhook.Text),
+			OutputSummary:            outputSummary.Selected,
+			SimilarityAlgorithm:      similarityAlgo.Selected,
+			SimilaritySize:           parseInt(similaritySize),
+			TrimAggregate:            trimAggregate.Selected,
+			BlackThreshold:           parseInt(blackThreshold),
+			WhiteThreshold:           parseInt(whiteThreshold),
+			Rotate:                   rotateValue,
+			AutoRotate:               autoRotate.Checked,
+			UniformPages:             uniformPages.Checked,
+			Invert:                   invert.Checked,
+			NormalizePaper:           normalizePaper.Checked,
+			TrimHorizontal:           parseInt(trimH),
+			TrimTop:                  parseInt(trimTop),
+			TrimBottom:               parseInt(trimBottom),
+			Verbose:                  verbose.Checked,
+			DumpAppleScript:          dumpScripts.Checked,
+			VerboseTiming:            verboseTiming.Checked,
+			SkipPreflight:            skipPreflight.Checked,
+			AllowBlackFirstPage:      allowBlack.Checked,
+			FirstPageOnly:            firstPageOnly.Checked && mode == "generate",
+			OnFocusLost:              onFocusLost.Selected,
+			OnConflict:               onConflict.Selected,
+			ForegroundCache:          time.Duration(parseInt(focusCache)) * time.Millisecond,
+			TurnRetries:              parseInt(turnRetries),
+			TurnRefocus:              turnRefocus.Checked,
+			AdvanceRetries:           parseInt(advanceRetries),
+			SkipCover:                skipCover.Checked,
+			NoTrimCover:              noTrimCover.Checked,
+			DedupAll:                 dedupAll.Checked,
+			Notify:                   notifyCheck.Checked,
+			NoCountdown:              !countdown.Checked,
 			// AutoConfirm is always true in GUI mode: pressing Start IS the confirmation.
 			// Setting this to false would cause fmt.Scanln() in orchestrator to block
 			// indefinitely since GUI processes have no stdin.
 			AutoConfirm: true,
 		}
+		return opts
+	}
 
-		finalOpts := config.ApplyDefaults(opts)
+	// effectiveOptions layers the form over the --config file and the defaults
+	effectiveOptions := func() (*config.ConversionOptions, error) {
+		var file *config.ConversionOptions
+		if configPath != "" {
+			var err error
+			if file, err = config.LoadConfig(configPath); err != nil {
+				return nil, err
+			}
+		}
+		return config.MergeOptions(collectOptions(), file), nil
+	}
+
+	startBtn.OnTapped = func() {
+		startBtn.Disable()
+		statusLabel.SetText("Running...")
+		logWriter.Reset() // Clear logs
+		firstPreview.Image = nil
+		firstPreview.Refresh()
+		latestPreview.Image = nil
+		latestPreview.Refresh()
+		previewLabel.SetText("")
+
+		finalOpts, err := effectiveOptions()
+		if err == nil {
+			err = finalOpts.Validate()
+		}
+		if err != nil {
+			dialog.ShowError(err, w)
+			startBtn.Enable()
+			statusLabel.SetText("Invalid settings")
+			return
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancelRun = cancel
+		stopBtn.Enable()
 
 		// Run in Goroutine
 		go func() {
+			// Widgets are only touched on the Fyne main thread (fyne.Do)
+			status := "Done"
 			defer func() {
-				startBtn.Enable()
-				statusLabel.SetText("Done")
+				cancel()
+				fyne.Do(func() {
+					stopBtn.Disable()
+					startBtn.Enable()
+					statusLabel.SetText(status)
+				})
 			}()
 
-			ctx := context.Background()
-
-			// Capture stdout/stderr
-			// Creating a pipe to capture fmt.Println from orchestrator
-			// Fyne doesn't support easy redirection of os.Stdout globally for the app
-			// without pipes.
-
-			// We will just write a wrapper that logs to both our UI and Stdout
-			// BUT, Orchestrator uses fmt.Printf directly.
-			// We MUST hijack os.Stdout/Stderr to see orchestrator logs.
-			pr, pw, _ := os.Pipe()
-			oldStdout := os.Stdout
-			oldStderr := os.Stderr
-			os.Stdout = pw
-			os.Stderr = pw
-
-			outC := make(chan string)
-
-			// Copy pipe to UI
-			go func() {
-				buf := make([]byte, 1024)
-				for {
-					n, err := pr.Read(buf)
-					if n > 0 {
-						chunk := string(buf[:n])
-						logWriter.Write([]byte(chunk)) // Append to UI
-						// Also write to real stdout for debugging
-						oldStdout.Write(buf[:n])
-					}
-					if err != nil {
-						break
-					}
-				}
-				close(outC)
-			}()
+			// Orchestrator messages go straight to the log area (and the real
+			// stdout for debugging) instead of through a redirected os.Stdout
+			logOut := io.MultiWriter(logWriter, os.Stdout)
 
 			var err error
 			var result *orchestrator.ConversionResult
+			successMsg := "Conversion Completed Successfully!"
 			if finalOpts.Mode == "pdf2md" {
-				fmt.Printf("Converting PDF to Markdown...\nInput: %s\n", finalOpts.InputFile)
+				fmt.Fprintf(logOut, "Converting PDF to Markdown...\nInput: %s\n", finalOpts.InputFile)
 				outputPath := finalOpts.OutputDir
 				if outputPath == "" {
 					outputPath = finalOpts.InputFile + ".md"
 				}
 				conv := converter.NewConverter()
-				err = conv.ConvertPDFToMarkdown(ctx, finalOpts.InputFile, outputPath)
+				var md *converter.MarkdownResult
+				md, err = conv.ConvertPDFToMarkdown(ctx, finalOpts.InputFile, outputPath, converter.MarkdownOptions{
+					Headings: finalOpts.MarkdownHeadings,
+					ImageDir: finalOpts.MarkdownImageDir,
+				})
+				if err == nil {
+					fmt.Fprintf(logOut, "Extracted %d characters from %d of %d pages (%d headings, %d images)\nOutput: %s\n",
+						md.Characters, md.TextPages, md.Pages, md.Headings, md.Images, outputPath)
+					for _, warning := range md.Warnings {
+						fmt.Fprintf(logOut, "Warning: %s\n", warning)
+						successMsg = "Converted with a warning:\n" + warning
+					}
+				}
 			} else {
-				orch := orchestrator.NewOrchestrator()
-				result, err = orch.ConvertCurrentBook(ctx, finalOpts)
+				// The backend was validated at startup
+				orch, _ := orchestrator.NewOrchestratorForBackend(captureBackend)
+				orch.SetLogWriter(logOut)
+				// Pause mode: wait for the user in a dialog; Kindle is reactivated after Resume
+				orch.SetFocusLostPrompt(func() bool {
+					answer := make(chan bool)
+					fyne.Do(func() {
+						d := dialog.NewConfirm("Kindle Lost Focus",
+							"Kindle is no longer the frontmost app. Press Resume to bring it back and continue.",
+							func(ok bool) { answer <- ok }, w)
+						d.SetConfirmText("Resume")
+						d.SetDismissText("Abort")
+						d.Show()
+					})
+					return <-answer
+				})
+				// Show the first page of each book and the most recent page
+				orch.SetProgressFunc(func(ev orchestrator.ProgressEvent) {
+					if ev.Image == nil {
+						return
+					}
+					fyne.Do(func() {
+						if ev.PageNumber == 1 {
+							firstPreview.Image = ev.Image
+							firstPreview.Refresh()
+						}
+						latestPreview.Image = ev.Image
+						latestPreview.Refresh()
+						previewLabel.SetText(fmt.Sprintf("Page %d", ev.PageNumber))
+					})
+				})
+				if finalOpts.Mode == "generate" && watch.Checked {
+					_, err = orch.Watch(ctx, finalOpts)
+				} else if finalOpts.Mode == "generate" && batch.Checked {
+					// Ask for the next book with a dialog; the batch ends when the user picks "Finish"
+					nextBook := func(bookNum int) bool {
+						answer := make(chan bool)
+						fyne.Do(func() {
+							d := dialog.NewConfirm("Next Book",
+								fmt.Sprintf("Open book %d in Kindle, then press Continue.", bookNum),
+								func(ok bool) { answer <- ok }, w)
+							d.SetConfirmText("Continue")
+							d.SetDismissText("Finish")
+							d.Show()
+						})
+						return <-answer
+					}
+					_, err = orch.ConvertBatch(ctx, finalOpts, nextBook)
+				} else {
+					result, err = orch.ConvertCurrentBook(ctx, finalOpts)
+					if err == nil && len(result.Warnings) > 0 {
+						successMsg = "Completed with warnings:\n" + strings.Join(result.Warnings, "\n")
+					}
+				}
 			}
 
 			// Update result display if in detect mode
@@ -337,59 +1198,357 @@ func main() {
 					margins.Top, margins.Bottom, margins.Left, margins.Right,
 					margins.Top, margins.Bottom, maxH,
 				)
-				resultLabel.SetText(resText)
+				fyne.Do(func() { resultLabel.SetText(resText) })
 			} else if finalOpts.Mode == "detect" {
 				// Clear on failure or if no result
-				resultLabel.SetText("")
+				fyne.Do(func() { resultLabel.SetText("") })
 			}
 
-			// Restore stdout
-			pw.Close()
-			os.Stdout = oldStdout
-			os.Stderr = oldStderr
-			<-outC // wait for copier
+			// Show the recommended delay and apply it to the Generate settings
+			if err == nil && finalOpts.Mode == "benchmark" && result != nil {
+				ms := int(result.RecommendedPageDelay.Milliseconds())
+				fyne.Do(func() {
+					benchLabel.SetText(fmt.Sprintf("Recommended page delay: %dms", ms))
+					pageDelay.SetText(strconv.Itoa(ms))
+				})
+			}
 
 			if err != nil {
-				dialog.ShowError(err, w)
-				statusLabel.SetText("Failed")
+				// Show the remediation hint below the error, if there is one
+				if hint := orchestrator.Hint(err); hint != "" {
+					logWriter.Write([]byte(fmt.Sprintf("\nError: %v\n→ %s\n", err, hint)))
+					err = fmt.Errorf("%w\n\n→ %s", err, hint)
+				}
+				if orchestrator.ExitCode(err) == orchestrator.ExitEnvironment {
+					status = "Kindle not ready"
+				} else {
+					status = "Failed"
+				}
+				fyne.Do(func() { dialog.ShowError(err, w) })
 			} else {
-				dialog.ShowInformation("Success", "Conversion Completed Successfully!", w)
+				fyne.Do(func() { dialog.ShowInformation("Success", successMsg, w) })
+			}
+		}()
+	}
+
+	// Trim preview: trim a saved screenshot with the current values and show it
+	trimPreviewBtn.OnTapped = func() {
+		fd := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+			if reader == nil {
+				return
+			}
+			input := reader.URI().Path()
+			reader.Close()
+
+			atoi := func(e *widget.Entry) int {
+				val, _ := strconv.Atoi(e.Text)
+				return val
+			}
+			rotateValue, _ := strconv.Atoi(rotate.Selected)
+			opts := config.ApplyDefaults(&config.ConversionOptions{
+				Mode:           "trim-preview",
+				InputFile:      input,
+				TrimTop:        atoi(trimTop),
+				TrimBottom:     atoi(trimBottom),
+				TrimHorizontal: atoi(trimH),
+				Rotate:         rotateValue,
+				BlackThreshold: atoi(blackThreshold),
+				WhiteThreshold: atoi(whiteThreshold),
+			})
+			orch := orchestrator.NewOrchestrator()
+			orch.SetLogWriter(io.MultiWriter(logWriter, os.Stdout))
+			result, err := orch.ConvertCurrentBook(context.Background(), opts)
+			if err != nil {
+				dialog.ShowError(err, w)
+				return
+			}
+
+			preview := canvas.NewImageFromFile(result.OutputPath)
+			preview.FillMode = canvas.ImageFillContain
+			preview.SetMinSize(fyne.NewSize(360, 480))
+			dialog.ShowCustom("Trim Preview", "Close", container.NewBorder(
+				nil, widget.NewLabel(result.OutputPath), nil, nil, preview), w)
+		}, w)
+		fd.SetFilter(storage.NewExtensionFileFilter([]string{".png", ".jpg", ".jpeg"}))
+		fd.Show()
+	}
+
+	// --- 5. Menu & Shortcuts ---
+	// Each entry reuses the matching button handler, so it behaves identically
+	start := func() {
+		if !startBtn.Disabled() {
+			startBtn.OnTapped()
+		}
+	}
+	cancelRunning := func() {
+		if !stopBtn.Disabled() {
+			stopBtn.OnTapped()
+		}
+	}
+	detect := func() {
+		selectTab(tabs, "Detect")
+		start()
+	}
+	openPDF := func() {
+		selectTab(tabs, "PDF2MD")
+		inputFileBtn.OnTapped()
+	}
+	// Checks Kindle, permissions, the output folder and optional tools; the
+	// full report with remedies goes to the log
+	runDoctor := func() {
+		opts, err := effectiveOptions()
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		opts.Mode = "doctor"
+		logWriter.Reset()
+		go func() {
+			orch, _ := orchestrator.NewOrchestratorForBackend(captureBackend)
+			orch.SetLogWriter(io.MultiWriter(logWriter, os.Stdout))
+			result, err := orch.ConvertCurrentBook(context.Background(), opts)
+			if err != nil {
+				fyne.Do(func() {
+					dialog.ShowError(fmt.Errorf("%w\n\nSee the log for how to fix each problem", err), w)
+				})
+				return
+			}
+			msg := "All required checks passed"
+			if len(result.Warnings) > 0 {
+				msg += "\n\nOptional features unavailable:\n" + strings.Join(result.Warnings, "\n")
 			}
+			fyne.Do(func() { dialog.ShowInformation("Doctor", msg, w) })
 		}()
 	}
+	// Shows the merged options a Start would run with, for the selected tab
+	showSettings := func() {
+		opts, err := effectiveOptions()
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		text := config.FormatYAML(opts)
+		if err := opts.Validate(); err != nil {
+			text = fmt.Sprintf("# Invalid: %v\n%s", err, text)
+		}
+		settings := widget.NewMultiLineEntry()
+		settings.TextStyle = fyne.TextStyle{Monospace: true}
+		settings.SetText(text)
+		scroll := container.NewVScroll(settings)
+		scroll.SetMinSize(fyne.NewSize(520, 480))
+		dialog.ShowCustom("Effective Settings ("+opts.Mode+")", "Close", scroll, w)
+	}
+
+	startKey := &desktop.CustomShortcut{KeyName: fyne.KeyR, Modifier: fyne.KeyModifierShortcutDefault}
+	cancelKey := &desktop.CustomShortcut{KeyName: fyne.KeyPeriod, Modifier: fyne.KeyModifierShortcutDefault}
+	openKey := &desktop.CustomShortcut{KeyName: fyne.KeyO, Modifier: fyne.KeyModifierShortcutDefault}
+
+	openItem := fyne.NewMenuItem("Open PDF...", openPDF)
+	openItem.Shortcut = openKey
+	quitItem := fyne.NewMenuItem("Quit", func() { a.Quit() })
+	quitItem.IsQuit = true
+	startItem := fyne.NewMenuItem("Start", start)
+	startItem.Shortcut = startKey
+	cancelItem := fyne.NewMenuItem("Cancel", cancelRunning)
+	cancelItem.Shortcut = cancelKey
+
+	w.SetMainMenu(fyne.NewMainMenu(
+		fyne.NewMenu("File",
+			openItem,
+			fyne.NewMenuItem("Choose Output Dir...", outputDirBtn.OnTapped),
+			fyne.NewMenuItemSeparator(),
+			quitItem,
+		),
+		fyne.NewMenu("Run",
+			startItem,
+			cancelItem,
+			fyne.NewMenuItem("Detect Margins", detect),
+			fyne.NewMenuItem("Run Doctor", runDoctor),
+			fyne.NewMenuItemSeparator(),
+			fyne.NewMenuItem("Show Effective Settings", showSettings),
+		),
+	))
+
+	// Also register the keys on the canvas so they work without a menu bar;
+	// while the menu has the same shortcut, Fyne runs the menu item instead
+	w.Canvas().AddShortcut(startKey, func(fyne.Shortcut) { start() })
+	w.Canvas().AddShortcut(cancelKey, func(fyne.Shortcut) { cancelRunning() })
+	w.Canvas().AddShortcut(openKey, func(fyne.Shortcut) { openPDF() })
 
 	w.ShowAndRun()
 }
 
+// selectTab switches to the tab with the given title
+func selectTab(tabs *container.AppTabs, title string) {
+	for _, item := range tabs.Items {
+		if item.Text == title {
+			tabs.Select(item)
+			return
+		}
+	}
+}
+
+// hasArg reports whether a command-line argument was given
+// Arguments are scanned by hand because macOS may pass extra ones (e.g. -psn_*)
+func hasArg(name string) bool {
+	for _, arg := range os.Args[1:] {
+		if arg == name {
+			return true
+		}
+	}
+	return false
+}
+
+// argValue returns the value following a command-line argument ("" if absent)
+func argValue(name string) string {
+	for i := 1; i+1 < len(os.Args); i++ {
+		if os.Args[i] == name {
+			return os.Args[i+1]
+		}
+	}
+	return ""
+}
+
+// persistEntry restores an entry from preferences and saves it on every change
+func persistEntry(prefs fyne.Preferences, key string, e *widget.Entry) {
+	e.SetText(prefs.StringWithFallback(key, e.Text))
+	e.OnChanged = func(text string) {
+		prefs.SetString(key, text)
+	}
+}
+
+// migratePreference moves a value saved under a renamed key to the new key,
+// unless the new key already holds one
+func migratePreference(prefs fyne.Preferences, oldKey, newKey string) {
+	old := prefs.String(oldKey)
+	if old == "" {
+		return
+	}
+	if prefs.String(newKey) == "" {
+		prefs.SetString(newKey, old)
+	}
+	prefs.RemoveValue(oldKey)
+}
+
+// persistSelect restores a select from preferences and saves it on every change
+// A stored value that is no longer an option is ignored
+func persistSelect(prefs fyne.Preferences, key string, sel *widget.Select) {
+	stored := prefs.String(key)
+	for _, opt := range sel.Options {
+		if opt == stored {
+			sel.SetSelected(stored)
+			break
+		}
+	}
+	sel.OnChanged = func(value string) {
+		prefs.SetString(key, value)
+	}
+}
+
+// persistCheck restores a check from preferences and saves it on every change
+func persistCheck(prefs fyne.Preferences, key string, c *widget.Check) {
+	c.SetChecked(prefs.BoolWithFallback(key, c.Checked))
+	c.OnChanged = func(checked bool) {
+		prefs.SetBool(key, checked)
+	}
+}
+
+// savedPresetsKey is the preference holding user presets as JSON (name -> options)
+const savedPresetsKey = "savedPresets"
+
+// loadSavedPresets reads the user's presets from preferences
+// Unreadable data is ignored so a bad entry never blocks startup
+func loadSavedPresets(prefs fyne.Preferences) map[string]config.ConversionOptions {
+	presets := make(map[string]config.ConversionOptions)
+	if data := prefs.String(savedPresetsKey); data != "" {
+		if err := json.Unmarshal([]byte(data), &presets); err != nil {
+			return make(map[string]config.ConversionOptions)
+		}
+		// Presets saved by earlier builds hold Page Step as CaptureEvery
+		var old map[string]struct{ CaptureEvery int }
+		if json.Unmarshal([]byte(data), &old) == nil {
+			for name, o := range old {
+				if p := presets[name]; p.PageStep == 0 && o.CaptureEvery != 0 {
+					p.PageStep = o.CaptureEvery
+					presets[name] = p
+				}
+			}
+		}
+	}
+	return presets
+}
+
+// saveSavedPresets writes the user's presets to preferences
+func saveSavedPresets(prefs fyne.Preferences, presets map[string]config.ConversionOptions) {
+	data, err := json.Marshal(presets)
+	if err != nil {
+		return
+	}
+	prefs.SetString(savedPresetsKey, string(data))
+}
+
+// Log size that triggers truncation, and roughly how much is kept afterwards
+const (
+	maxLogBytes  = 100000
+	logKeepBytes = 50000
+)
+
 // uiWriter implements io.Writer and appends to a MultiLineEntry
+// Writes come from conversion goroutines: the text is built under a mutex and
+// the widget is only touched on the Fyne main thread via fyne.Do
 type uiWriter struct {
+	mu    sync.Mutex
+	text  string
 	entry *widget.Entry
+
+	// Follow the newest line when checked (nil = always follow)
+	autoScroll *widget.Check
 }
 
 func (w *uiWriter) Write(p []byte) (n int, err error) {
-	// Must run on main thread
-	// But we are in a goroutine context usually
-	// Fyne is thread-safe mostly for SetText? No, usually safer to use Append or binding.
-	// entry.Append is not a method of MultiLineEntry in older fyne?
-	// Checking v2... SetText is available.
-	// For large logs, appending string is expensive.
-	// But for this use case, it's fine.
-
-	text := string(p)
-	// Remove ANSI codes if any (Orchestrator might use colors?)
-	// For now, raw.
-
-	// We should probably limit the log size
-	current := w.entry.Text
-	if len(current) > 100000 {
-		current = current[50000:] // Truncate old logs
-	}
-
-	w.entry.SetText(current + text)
-	// Auto-scroll happens if we refresh/cursor?
-	// MultiLineEntry auto-scrolls to cursor usually.
-	w.entry.CursorRow = len(strings.Split(w.entry.Text, "\n"))
-	w.entry.Refresh()
+	w.mu.Lock()
+	w.text = truncateLog(w.text+string(p), maxLogBytes, logKeepBytes)
+	text := w.text
+	w.mu.Unlock()
+
+	fyne.Do(func() {
+		row, col := w.entry.CursorRow, w.entry.CursorColumn
+		w.entry.SetText(text)
+		lastRow := strings.Count(text, "\n")
+		if w.autoScroll == nil || w.autoScroll.Checked {
+			// Keep the newest line in view
+			w.entry.CursorRow = lastRow
+		} else if row <= lastRow {
+			// Truncation may have removed the rows the cursor was on
+			w.entry.CursorRow, w.entry.CursorColumn = row, col
+		}
+		w.entry.Refresh()
+	})
 
 	return len(p), nil
 }
+
+// Reset clears the log (call on the main thread)
+func (w *uiWriter) Reset() {
+	w.mu.Lock()
+	w.text = ""
+	w.mu.Unlock()
+	w.entry.SetText("")
+}
+
+// truncateLog drops the oldest lines once text exceeds max bytes
+// About keep bytes remain, starting at a line boundary so neither a line nor a
+// UTF-8 rune is cut; a single huge line is cut at the next rune boundary
+func truncateLog(text string, max, keep int) string {
+	if len(text) <= max {
+		return text
+	}
+	cut := len(text) - keep
+	if i := strings.IndexByte(text[cut:], '\n'); i >= 0 {
+		return text[cut+i+1:]
+	}
+	for cut < len(text) && !utf8.RuneStart(text[cut]) {
+		cut++
+	}
+	return text[cut:]
+}
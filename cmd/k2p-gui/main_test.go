@@ -0,0 +1,34 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncateLog(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		max  int
+		keep int
+		want string
+	}{
+		{"under the limit", "one\ntwo\n", 100, 50, "one\ntwo\n"},
+		{"cut at the next line", "aaaa\nbbbb\ncccc\n", 10, 8, "cccc\n"},
+		{"line boundary keeps whole lines", "line1\nline2\nline3\n", 12, 10, "line3\n"},
+		{"single line cut at a rune boundary", strings.Repeat("あ", 10), 20, 7, "ああ"},
+		{"ASCII single line", "abcdefghij", 5, 3, "hij"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := truncateLog(tt.text, tt.max, tt.keep)
+			if got != tt.want {
+				t.Errorf("truncateLog(%q, %d, %d) = %q, want %q", tt.text, tt.max, tt.keep, got, tt.want)
+			}
+			if !utf8.ValidString(got) {
+				t.Errorf("expected valid UTF-8, got %q", got)
+			}
+		})
+	}
+}
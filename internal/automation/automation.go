@@ -2,12 +2,34 @@ package automation
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"os/exec"
 	"strings"
+	"time"
+
+	"github.com/oumi/k2p/internal/hint"
+)
+
+// Kindle state errors; wrap them with context and match with errors.Is
+var (
+	ErrKindleNotInstalled = hint.New(
+		"Kindle app is not installed",
+		"Install Kindle from the Mac App Store, sign in, and open a book",
+	)
+	ErrNoBookOpen = hint.New(
+		"no book is currently open in Kindle app",
+		"Open a book in Kindle (not the library view), then start again",
+	)
+	ErrKindleNotForeground = hint.New(
+		"Kindle app is not in foreground",
+		"Click the Kindle window, then start again",
+	)
 )
 
-// KindleAutomation handles interaction with the macOS Kindle application
+// KindleAutomation handles interaction with the macOS Kindle application, or
+// with another reader app set with SetTarget
 type KindleAutomation interface {
 	// IsKindleInstalled checks if Kindle app is installed
 	IsKindleInstalled() (bool, error)
@@ -18,27 +40,96 @@ type KindleAutomation interface {
 	// IsKindleInForeground checks if Kindle app is in foreground
 	IsKindleInForeground() (bool, error)
 
+	// BringKindleToForeground activates Kindle and verifies it came to front
+	BringKindleToForeground() error
+
 	// TurnNextPage navigates to next page
 	// direction: "right" or "left" for arrow key direction
 	TurnNextPage(direction string) error
+
+	// TurnPages turns count pages with a single AppleScript call, checking
+	// the foreground again before every keystroke
+	TurnPages(direction string, count int) error
+
+	// GetBookTitle returns the title of the currently open book
+	// (the name of Kindle's front window, empty if no window is open)
+	GetBookTitle() (string, error)
+
+	// GetKindleVersion returns the reader app's version (e.g. "7.35"), so
+	// capture quirks can be matched to app releases
+	GetKindleVersion() (string, error)
+
+	// HasMorePages reports false when Kindle shows its end-of-book screen
+	// Best effort: true whenever no end-of-book text is found
+	HasMorePages() (bool, error)
+
+	// SetCommandTimeout limits how long a single AppleScript call may run
+	// Zero keeps DefaultCommandTimeout
+	SetCommandTimeout(timeout time.Duration)
+
+	// SetTarget selects the reader app to automate
+	// The zero Target keeps KindleTarget
+	SetTarget(target Target)
+
+	// SetSession sets the per-run foreground cache and script log
+	// nil turns both off
+	SetSession(session *Session)
 }
 
+const (
+	// foregroundTimeout is how long BringKindleToForeground waits for Kindle to come to front
+	foregroundTimeout = 3 * time.Second
+
+	// foregroundPollInterval is how often the frontmost app is re-checked after activation
+	foregroundPollInterval = 100 * time.Millisecond
+
+	// batchTurnDelay separates the keystrokes of one TurnPages call, so the
+	// reader app renders each page instead of dropping queued keys
+	batchTurnDelay = 150 * time.Millisecond
+
+	// DefaultCommandTimeout is how long one osascript call may run before it is killed
+	// A call blocked on a permission dialog would otherwise hang the whole run
+	DefaultCommandTimeout = 10 * time.Second
+)
+
 // AppleScriptAutomation implements KindleAutomation using AppleScript
-type AppleScriptAutomation struct{}
+type AppleScriptAutomation struct {
+	commandTimeout time.Duration
+	target         Target
+	session        *Session
+}
 
 // NewKindleAutomation creates a new KindleAutomation instance
 func NewKindleAutomation() KindleAutomation {
 	return &AppleScriptAutomation{}
 }
 
-// IsKindleInstalled checks if Kindle app is installed
+// SetCommandTimeout limits how long a single AppleScript call may run
+// Zero keeps DefaultCommandTimeout
+func (a *AppleScriptAutomation) SetCommandTimeout(timeout time.Duration) {
+	a.commandTimeout = timeout
+}
+
+// SetTarget selects the reader app to automate
+// The zero Target keeps KindleTarget
+func (a *AppleScriptAutomation) SetTarget(target Target) {
+	a.target = target
+}
+
+// SetSession sets the per-run foreground cache and script log
+// nil turns both off
+func (a *AppleScriptAutomation) SetSession(session *Session) {
+	a.session = session
+}
+
+// IsKindleInstalled checks if the target app is running
 func (a *AppleScriptAutomation) IsKindleInstalled() (bool, error) {
-	script := `
+	script := fmt.Sprintf(`
 tell application "System Events"
-	return exists application process "Kindle"
+	return exists application process %s
 end tell
-`
-	output, err := runAppleScript(script)
+`, QuoteAppleScript(a.target.orDefault().Process))
+	output, err := a.session.runAppleScript(script, a.commandTimeout)
 	if err != nil {
 		return false, fmt.Errorf("failed to check Kindle installation: %w", err)
 	}
@@ -47,11 +138,11 @@ end tell
 }
 
 // IsBookOpen detects if a book is currently open
-// This checks if Kindle has a window open
+// This checks if the target app has a window open
 func (a *AppleScriptAutomation) IsBookOpen() (bool, error) {
-	script := `
+	script := fmt.Sprintf(`
 tell application "System Events"
-	tell process "Kindle"
+	tell process %s
 		if exists then
 			return count of windows > 0
 		else
@@ -59,8 +150,8 @@ tell application "System Events"
 		end if
 	end tell
 end tell
-`
-	output, err := runAppleScript(script)
+`, QuoteAppleScript(a.target.orDefault().Process))
+	output, err := a.session.runAppleScript(script, a.commandTimeout)
 	if err != nil {
 		return false, fmt.Errorf("failed to check if book is open: %w", err)
 	}
@@ -70,23 +161,97 @@ end tell
 
 // IsKindleInForeground checks if Kindle app is in foreground
 func (a *AppleScriptAutomation) IsKindleInForeground() (bool, error) {
-	script := `
+	return a.session.IsAppFrontmost(a.target, a.commandTimeout)
+}
+
+// BringKindleToForeground activates Kindle and polls until it is frontmost
+func (a *AppleScriptAutomation) BringKindleToForeground() error {
+	return a.session.ActivateApp(a.target, foregroundTimeout, a.commandTimeout)
+}
+
+// IsAppFrontmost reports whether the target's process is the frontmost application
+// The screenshot capturer uses it too, so focus is checked one way everywhere
+// The zero Target is Kindle; timeout limits the osascript call (zero uses
+// DefaultCommandTimeout). A recent positive result is reused while the
+// session's foreground cache is on
+func (s *Session) IsAppFrontmost(target Target, timeout time.Duration) (bool, error) {
+	target = target.orDefault()
+	if s == nil {
+		return s.isAppFrontmost(target, timeout)
+	}
+	return s.cache.check(target, func() (bool, error) {
+		return s.isAppFrontmost(target, timeout)
+	})
+}
+
+// isAppFrontmost runs the frontmost check for a resolved target, uncached
+func (s *Session) isAppFrontmost(target Target, timeout time.Duration) (bool, error) {
+	script := fmt.Sprintf(`
 tell application "System Events"
 	set frontApp to name of first application process whose frontmost is true
-	return frontApp is "Kindle"
+	return frontApp is %s
 end tell
-`
-	output, err := runAppleScript(script)
+`, QuoteAppleScript(target.Process))
+	output, err := s.runAppleScript(script, timeout)
 	if err != nil {
-		return false, fmt.Errorf("failed to check if Kindle is in foreground: %w", err)
+		return false, fmt.Errorf("failed to check if %s is in foreground: %w", target.Process, err)
 	}
 
 	return strings.TrimSpace(output) == "true", nil
 }
 
+// ActivateApp brings the target app to front and polls for up to wait until it is frontmost
+// Fullscreen Kindle lives in its own Space, so the switch is not immediate
+// The zero Target is Kindle; timeout limits each osascript call (zero uses
+// DefaultCommandTimeout)
+func (s *Session) ActivateApp(target Target, wait, timeout time.Duration) error {
+	target = target.orDefault()
+	// The application name may differ from the process name (Kindle)
+	script := fmt.Sprintf(`
+tell application %s
+	activate
+end tell
+`, QuoteAppleScript(target.Application))
+	if _, err := s.runAppleScript(script, timeout); err != nil {
+		return fmt.Errorf("failed to activate %s: %w", target.Application, err)
+	}
+
+	// Uncached: activation must see the app actually come to front
+	return waitFrontmost(func() (bool, error) { return s.isAppFrontmost(target, timeout) }, wait)
+}
+
+// waitFrontmost polls frontmost every foregroundPollInterval until it reports
+// true, fails, or wait has passed
+func waitFrontmost(frontmost func() (bool, error), wait time.Duration) error {
+	deadline := time.Now().Add(wait)
+	for {
+		inForeground, err := frontmost()
+		if err != nil {
+			return err
+		}
+		if inForeground {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%w after activation (waited %s)", ErrKindleNotForeground, wait)
+		}
+		time.Sleep(foregroundPollInterval)
+	}
+}
+
 // TurnNextPage navigates to next page by sending arrow key
 // direction: "right" for right arrow, "left" for left arrow
 func (a *AppleScriptAutomation) TurnNextPage(direction string) error {
+	return a.TurnPages(direction, 1)
+}
+
+// TurnPages sends count arrow keys in one osascript call, batchTurnDelay apart
+// Saves a process spawn per page when pages are skipped between captures
+func (a *AppleScriptAutomation) TurnPages(direction string, count int) error {
+	if count < 1 {
+		return nil
+	}
+
 	// CRITICAL: Verify Kindle is in foreground before sending keystroke
 	// If Kindle lost focus, we MUST NOT send keystrokes to avoid
 	// accidentally operating other applications
@@ -95,7 +260,7 @@ func (a *AppleScriptAutomation) TurnNextPage(direction string) error {
 		return fmt.Errorf("failed to check Kindle foreground status: %w", err)
 	}
 	if !inForeground {
-		return fmt.Errorf("Kindle is not in foreground - terminating to prevent accidental operations on other apps")
+		return fmt.Errorf("%w - terminating to prevent accidental operations on other apps", ErrKindleNotForeground)
 	}
 
 	// Use key code for arrow keys (without modifiers)
@@ -107,30 +272,155 @@ func (a *AppleScriptAutomation) TurnNextPage(direction string) error {
 		keyCode = "124"
 	}
 
+	script := turnPagesScript(QuoteAppleScript(a.target.orDefault().Process), keyCode, count)
+	output, err := a.session.runAppleScript(script, a.commandTimeout+time.Duration(count-1)*batchTurnDelay)
+	if err != nil {
+		return fmt.Errorf("failed to turn page: %w", err)
+	}
+	if output = strings.TrimSpace(output); output != "sent" {
+		if count > 1 {
+			return fmt.Errorf("%w - stopped turning pages (%s of %d) to prevent accidental operations on other apps",
+				ErrKindleNotForeground, strings.TrimPrefix(output, "not frontmost after "), count)
+		}
+		return fmt.Errorf("%w - keystroke not sent to prevent accidental operations on other apps", ErrKindleNotForeground)
+	}
+
+	return nil
+}
+
+// turnPagesScript sends count key codes to process (an AppleScript string)
+// Re-checks the frontmost app before every keystroke: focus can change
+// between the check in TurnPages and each key, and no key may reach another
+// app. Returns "sent", or "not frontmost after N" when it stops early
+func turnPagesScript(process, keyCode string, count int) string {
+	return fmt.Sprintf(`
+set sent to 0
+tell application "System Events"
+	repeat %d times
+		if sent > 0 then delay %.2f
+		set frontApp to name of first application process whose frontmost is true
+		if frontApp is not %s then
+			return "not frontmost after " & sent
+		end if
+		tell process %s
+			key code %s
+		end tell
+		set sent to sent + 1
+	end repeat
+end tell
+return "sent"
+`, count, batchTurnDelay.Seconds(), process, process, keyCode)
+}
+
+// GetBookTitle returns the title of the currently open book
+// Kindle shows the book title as the name of its front window
+func (a *AppleScriptAutomation) GetBookTitle() (string, error) {
 	script := fmt.Sprintf(`
 tell application "System Events"
-	tell process "Kindle"
-		key code %s
+	tell process %s
+		if (count of windows) > 0 then
+			return name of front window
+		else
+			return ""
+		end if
 	end tell
 end tell
-`, keyCode)
+`, QuoteAppleScript(a.target.orDefault().Process))
+	output, err := a.session.runAppleScript(script, a.commandTimeout)
+	if err != nil {
+		return "", fmt.Errorf("failed to get book title: %w", err)
+	}
+
+	return strings.TrimSpace(output), nil
+}
 
-	_, err = runAppleScript(script)
+// GetKindleVersion returns the version of the target app
+// Window layout and the end-of-book screen differ between Kindle releases
+func (a *AppleScriptAutomation) GetKindleVersion() (string, error) {
+	script := fmt.Sprintf(`return version of application %s`, QuoteAppleScript(a.target.orDefault().Application))
+	output, err := a.session.runAppleScript(script, a.commandTimeout)
 	if err != nil {
-		return fmt.Errorf("failed to turn page: %w", err)
+		return "", fmt.Errorf("failed to get Kindle version: %w", err)
 	}
 
-	return nil
+	return strings.TrimSpace(output), nil
+}
+
+// endOfBookMarkers are texts of Kindle's end-of-book screen (lowercase)
+var endOfBookMarkers = []string{
+	"you've reached the end",
+	"you’ve reached the end",
+	"rate this book",
+	"before you go",
+}
+
+// HasMorePages looks for the end-of-book screen in Kindle's accessibility tree
+// Kindle draws pages itself, so only the end screen's controls carry text
+func (a *AppleScriptAutomation) HasMorePages() (bool, error) {
+	script := fmt.Sprintf(`
+set found to {}
+tell application "System Events"
+	tell process %s
+		if (count of windows) is 0 then return ""
+		repeat with e in (entire contents of front window)
+			try
+				set end of found to (name of e as text)
+			end try
+			try
+				set end of found to (value of e as text)
+			end try
+		end repeat
+	end tell
+end tell
+set AppleScript's text item delimiters to linefeed
+return found as text
+`, QuoteAppleScript(a.target.orDefault().Process))
+	output, err := a.session.runAppleScript(script, a.commandTimeout)
+	if err != nil {
+		return true, fmt.Errorf("failed to read Kindle UI: %w", err)
+	}
+
+	return !hasEndOfBookMarker(output), nil
+}
+
+// hasEndOfBookMarker reports whether UI text contains an end-of-book marker
+func hasEndOfBookMarker(text string) bool {
+	text = strings.ToLower(text)
+	for _, marker := range endOfBookMarkers {
+		if strings.Contains(text, marker) {
+			return true
+		}
+	}
+	return false
 }
 
 // runAppleScript executes an AppleScript and returns the output
-func runAppleScript(script string) (string, error) {
-	cmd := exec.Command("osascript", "-e", script)
+// The osascript process is killed if it is still running after timeout
+// (zero uses DefaultCommandTimeout). Calls are dumped to the session's
+// script log, if any
+func (s *Session) runAppleScript(script string, timeout time.Duration) (string, error) {
+	if timeout <= 0 {
+		timeout = DefaultCommandTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "osascript", "-e", script)
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
+	var log *scriptLog
+	if s != nil {
+		log = s.log
+	}
+	log.dumpScript(script)
+	start := time.Now()
 	err := cmd.Run()
+	log.dumpResult(stdout.String(), stderr.String(), err, time.Since(start))
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return "", fmt.Errorf("AppleScript timeout after %s", timeout)
+	}
 	if err != nil {
 		return "", fmt.Errorf("AppleScript error: %w, stderr: %s", err, stderr.String())
 	}
@@ -1,7 +1,11 @@
 package automation
 
 import (
+	"bytes"
+	"errors"
+	"strings"
 	"testing"
+	"time"
 )
 
 // Note: These tests require Kindle app to be installed and may require manual setup
@@ -62,6 +66,31 @@ func TestIsKindleInForeground(t *testing.T) {
 	t.Logf("Kindle in foreground: %v", inForeground)
 }
 
+func TestBringKindleToForeground(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test")
+	}
+
+	automation := NewKindleAutomation()
+
+	installed, _ := automation.IsKindleInstalled()
+	if !installed {
+		t.Skip("Kindle not installed")
+	}
+
+	if err := automation.BringKindleToForeground(); err != nil {
+		t.Fatalf("failed to bring Kindle to foreground: %v", err)
+	}
+
+	inForeground, err := automation.IsKindleInForeground()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !inForeground {
+		t.Error("expected Kindle in foreground after BringKindleToForeground")
+	}
+}
+
 func TestTurnNextPage(t *testing.T) {
 	if testing.Short() {
 		t.Skip("Skipping integration test")
@@ -92,12 +121,35 @@ func TestTurnNextPage(t *testing.T) {
 	}
 }
 
+// The frontmost app is stored before the comparison: "name of first
+// application process whose frontmost is true is not ..." parses as a filter
+// on the process list and fails to compile
+func TestTurnPagesScript(t *testing.T) {
+	script := turnPagesScript(QuoteAppleScript("Kindle"), "123", 3)
+
+	for _, want := range []string{
+		"repeat 3 times",
+		"if sent > 0 then delay 0.15",
+		"set frontApp to name of first application process whose frontmost is true\n\t\tif frontApp is not \"Kindle\" then",
+		"return \"not frontmost after \" & sent",
+		"tell process \"Kindle\"\n\t\t\tkey code 123",
+		"return \"sent\"",
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("expected %q in the script, got:\n%s", want, script)
+		}
+	}
+	if strings.Contains(script, "is true is not") {
+		t.Errorf("expected the frontmost check to be split, got:\n%s", script)
+	}
+}
+
 // Unit tests for AppleScript execution
 
 func TestRunAppleScript(t *testing.T) {
 	// Test simple AppleScript
 	script := `return "hello"`
-	output, err := runAppleScript(script)
+	output, err := (*Session)(nil).runAppleScript(script, DefaultCommandTimeout)
 
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
@@ -111,9 +163,146 @@ func TestRunAppleScript(t *testing.T) {
 func TestRunAppleScriptError(t *testing.T) {
 	// Test invalid AppleScript
 	script := `this is not valid applescript`
-	_, err := runAppleScript(script)
+	_, err := (*Session)(nil).runAppleScript(script, DefaultCommandTimeout)
 
 	if err == nil {
 		t.Error("expected error for invalid AppleScript")
 	}
 }
+
+func TestHasEndOfBookMarker(t *testing.T) {
+	tests := []struct {
+		text string
+		want bool
+	}{
+		{"", false},
+		{"Chapter 1\nThe Beginning", false},
+		{"You've reached the end of The Book", true},
+		{"YOU’VE REACHED THE END", true},
+		{"Close\nRate this book\nWrite a review", true},
+	}
+	for _, tt := range tests {
+		if got := hasEndOfBookMarker(tt.text); got != tt.want {
+			t.Errorf("hasEndOfBookMarker(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestTargetForApp(t *testing.T) {
+	for _, name := range []string{"", "Kindle", " Amazon Kindle "} {
+		if got := TargetForApp(name); got != KindleTarget {
+			t.Errorf("TargetForApp(%q) = %+v, expected Kindle", name, got)
+		}
+	}
+	if got := TargetForApp("Books"); got != (Target{Application: "Books", Process: "Books"}) {
+		t.Errorf("expected Books to be used for both names, got %+v", got)
+	}
+	if got := (Target{}).orDefault(); got != KindleTarget {
+		t.Errorf("expected the zero target to be Kindle, got %+v", got)
+	}
+	if got := QuoteAppleScript(`My "Reader" \ 2`); got != `"My \"Reader\" \\ 2"` {
+		t.Errorf("unexpected AppleScript string %s", got)
+	}
+}
+
+func TestScriptLog(t *testing.T) {
+	var buf bytes.Buffer
+	session := NewSession(0, &buf)
+
+	// Fails without osascript; the call is dumped either way
+	session.runAppleScript("return \"ping\"\nreturn 1", time.Second)
+
+	dump := buf.String()
+	if !strings.Contains(dump, "[AppleScript] run:\n\treturn \"ping\"\n\treturn 1\n") {
+		t.Errorf("expected the indented script in the dump, got:\n%s", dump)
+	}
+	if !strings.Contains(dump, "[AppleScript] done in ") || !strings.Contains(dump, "stdout=") {
+		t.Errorf("expected the raw result in the dump, got:\n%s", dump)
+	}
+
+	// Sessions are independent: one without a log dumps nothing
+	buf.Reset()
+	NewSession(0, nil).runAppleScript("return 1", time.Second)
+	(*Session)(nil).runAppleScript("return 1", time.Second)
+	if buf.Len() != 0 {
+		t.Errorf("expected no dump when off, got:\n%s", buf.String())
+	}
+}
+
+func TestForegroundCache(t *testing.T) {
+	now := time.Unix(0, 0)
+	cache := &foregroundCache{ttl: 2 * time.Second, now: func() time.Time { return now }}
+	calls := 0
+	frontmost := true
+	check := func() (bool, error) {
+		calls++
+		return frontmost, nil
+	}
+
+	cache.check(KindleTarget, check)
+	cache.check(KindleTarget, check)
+	if calls != 1 {
+		t.Errorf("expected a fresh positive result to be reused, got %d checks", calls)
+	}
+
+	// Another target is checked in full
+	cache.check(Target{Application: "Books", Process: "Books"}, check)
+	if calls != 2 {
+		t.Errorf("expected a check for another target, got %d checks", calls)
+	}
+
+	now = now.Add(3 * time.Second)
+	frontmost = false
+	if got, _ := cache.check(KindleTarget, check); got || calls != 3 {
+		t.Errorf("expected an expired result to be re-checked, got %v after %d checks", got, calls)
+	}
+
+	// Negative results are never cached
+	frontmost = true
+	if got, _ := cache.check(KindleTarget, check); !got || calls != 4 {
+		t.Errorf("expected a check after a negative result, got %v after %d checks", got, calls)
+	}
+
+	cache.ttl = 0
+	cache.check(KindleTarget, check)
+	cache.check(KindleTarget, check)
+	if calls != 6 {
+		t.Errorf("expected every call to check when off, got %d checks", calls)
+	}
+}
+
+// Activation polls until the app is frontmost instead of sleeping a fixed time
+func TestWaitFrontmost(t *testing.T) {
+	polls := 0
+	frontmostAfter := func(n int) func() (bool, error) {
+		polls = 0
+		return func() (bool, error) {
+			polls++
+			return polls >= n, nil
+		}
+	}
+
+	start := time.Now()
+	if err := waitFrontmost(frontmostAfter(3), 5*time.Second); err != nil {
+		t.Fatalf("expected the app to come to front, got %v", err)
+	}
+	if polls != 3 {
+		t.Errorf("expected to stop polling once frontmost, got %d polls", polls)
+	}
+	if elapsed := time.Since(start); elapsed >= time.Second {
+		t.Errorf("expected to continue as soon as the app is frontmost, waited %s", elapsed)
+	}
+
+	err := waitFrontmost(frontmostAfter(1000), 250*time.Millisecond)
+	if !errors.Is(err, ErrKindleNotForeground) {
+		t.Errorf("expected ErrKindleNotForeground after the wait, got %v", err)
+	}
+	if polls < 2 {
+		t.Errorf("expected several polls before giving up, got %d", polls)
+	}
+
+	failed := errors.New("osascript failed")
+	if err := waitFrontmost(func() (bool, error) { return false, failed }, 5*time.Second); !errors.Is(err, failed) {
+		t.Errorf("expected the check error, got %v", err)
+	}
+}
@@ -0,0 +1,45 @@
+package automation
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// scriptLog writes every AppleScript call of a Session: the script before it
+// runs and its raw stdout/stderr afterwards. A nil *scriptLog writes nothing
+type scriptLog struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// dumpScript writes a script about to run to the log
+func (l *scriptLog) dumpScript(script string) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	fmt.Fprintf(l.w, "[AppleScript] run:\n%s\n", indent(strings.TrimSpace(script)))
+}
+
+// dumpResult writes the raw outcome of a script to the log
+func (l *scriptLog) dumpResult(stdout, stderr string, err error, elapsed time.Duration) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	fmt.Fprintf(l.w, "[AppleScript] done in %s: stdout=%q stderr=%q", elapsed.Round(time.Millisecond), stdout, stderr)
+	if err != nil {
+		fmt.Fprintf(l.w, " error=%v", err)
+	}
+	fmt.Fprintln(l.w)
+}
+
+// indent prefixes every line of s with a tab
+func indent(s string) string {
+	return "\t" + strings.ReplaceAll(s, "\n", "\n\t")
+}
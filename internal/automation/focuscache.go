@@ -0,0 +1,41 @@
+package automation
+
+import (
+	"sync"
+	"time"
+)
+
+// foregroundCache remembers a positive frontmost check for a short window, so
+// the per-page checks before every keystroke and capture can skip osascript
+// Only "frontmost" is cached: a negative result or an error clears it, and the
+// first check of a Session always runs
+type foregroundCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	target  Target
+	checked time.Time
+	now     func() time.Time
+}
+
+// check returns the cached result for target if it is fresh, or runs check
+// and remembers a positive result
+func (c *foregroundCache) check(target Target, check func() (bool, error)) (bool, error) {
+	c.mu.Lock()
+	if c.ttl > 0 && !c.checked.IsZero() && c.target == target && c.now().Sub(c.checked) < c.ttl {
+		c.mu.Unlock()
+		return true, nil
+	}
+	c.mu.Unlock()
+
+	frontmost, err := check()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err != nil || !frontmost {
+		c.checked = time.Time{}
+	} else if c.ttl > 0 {
+		c.target = target
+		c.checked = c.now()
+	}
+	return frontmost, err
+}
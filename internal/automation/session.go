@@ -0,0 +1,28 @@
+package automation
+
+import (
+	"io"
+	"time"
+)
+
+// Session holds the per-run AppleScript settings shared by the automation
+// and the screenshot capturer, whose focus checks both run AppleScript: the
+// foreground cache and the script log. Create one per conversion, so runs
+// with different options do not affect each other
+// A nil *Session caches nothing and logs nothing
+type Session struct {
+	cache *foregroundCache
+	log   *scriptLog
+}
+
+// NewSession creates the settings for one run: a positive frontmost check is
+// reused for up to cacheTTL (0: off), and every AppleScript call is written
+// to dump (nil: off)
+// Keystrokes still re-check focus in the same script that sends them
+func NewSession(cacheTTL time.Duration, dump io.Writer) *Session {
+	s := &Session{cache: &foregroundCache{ttl: cacheTTL, now: time.Now}}
+	if dump != nil {
+		s.log = &scriptLog{w: dump}
+	}
+	return s
+}
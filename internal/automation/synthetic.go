@@ -0,0 +1,49 @@
+package automation
+
+import "time"
+
+// SyntheticAutomation pretends a book is open in a frontmost Kindle
+// It pairs with screenshot.NewSyntheticCapturer to run the whole pipeline
+// without macOS, e.g. in CI. Page turns do nothing
+type SyntheticAutomation struct{}
+
+// NewSyntheticAutomation creates a KindleAutomation that never touches Kindle
+func NewSyntheticAutomation() KindleAutomation {
+	return &SyntheticAutomation{}
+}
+
+// IsKindleInstalled always reports true
+func (a *SyntheticAutomation) IsKindleInstalled() (bool, error) { return true, nil }
+
+// IsBookOpen always reports true
+func (a *SyntheticAutomation) IsBookOpen() (bool, error) { return true, nil }
+
+// IsKindleInForeground always reports true
+func (a *SyntheticAutomation) IsKindleInForeground() (bool, error) { return true, nil }
+
+// BringKindleToForeground does nothing
+func (a *SyntheticAutomation) BringKindleToForeground() error { return nil }
+
+// TurnNextPage does nothing; the synthetic capturer advances on every capture
+func (a *SyntheticAutomation) TurnNextPage(direction string) error { return nil }
+
+// TurnPages does nothing, like TurnNextPage
+func (a *SyntheticAutomation) TurnPages(direction string, count int) error { return nil }
+
+// GetBookTitle returns a fixed title
+func (a *SyntheticAutomation) GetBookTitle() (string, error) { return "Synthetic Book", nil }
+
+// GetKindleVersion returns a fixed version
+func (a *SyntheticAutomation) GetKindleVersion() (string, error) { return "synthetic", nil }
+
+// SetCommandTimeout does nothing; no commands are run
+func (a *SyntheticAutomation) SetCommandTimeout(timeout time.Duration) {}
+
+// SetTarget does nothing; there is no app to address
+func (a *SyntheticAutomation) SetTarget(target Target) {}
+
+// SetSession does nothing; no AppleScript is run
+func (a *SyntheticAutomation) SetSession(session *Session) {}
+
+// HasMorePages always reports true; synthetic books end through end detection
+func (a *SyntheticAutomation) HasMorePages() (bool, error) { return true, nil }
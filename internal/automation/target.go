@@ -0,0 +1,41 @@
+package automation
+
+import "strings"
+
+// Target identifies the reader app to automate
+type Target struct {
+	// Application name used to activate the app (e.g. "Amazon Kindle")
+	Application string
+
+	// Process name System Events lists the app under (e.g. "Kindle")
+	Process string
+}
+
+// KindleTarget is the default target
+// The application is named "Amazon Kindle" but its process is "Kindle"
+var KindleTarget = Target{Application: "Amazon Kindle", Process: "Kindle"}
+
+// TargetForApp returns the target for a reader app name
+// "" and the Kindle names give KindleTarget; any other app (e.g. "Kindle
+// Previewer 3" or "Books") is activated and addressed by that name
+func TargetForApp(name string) Target {
+	name = strings.TrimSpace(name)
+	switch name {
+	case "", KindleTarget.Process, KindleTarget.Application:
+		return KindleTarget
+	}
+	return Target{Application: name, Process: name}
+}
+
+// orDefault returns KindleTarget for an unset target
+func (t Target) orDefault() Target {
+	if t == (Target{}) {
+		return KindleTarget
+	}
+	return t
+}
+
+// QuoteAppleScript returns s as an AppleScript string literal
+func QuoteAppleScript(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
@@ -2,50 +2,364 @@ package config
 
 import (
 	"fmt"
+	"image"
+	"image/color"
+	"net/url"
+	"runtime"
+	"strconv"
+	"strings"
 	"time"
 )
 
 // ConversionOptions holds all configuration options for conversion
 type ConversionOptions struct {
 	// Output directory (empty = current directory)
-	OutputDir string
+	OutputDir string `yaml:"outputDir"`
 
 	// Screenshot quality (1-100, default: 95)
-	ScreenshotQuality int
+	ScreenshotQuality int `yaml:"screenshotQuality"`
+
+	// Format screencapture writes: "png" (default) or "bmp"
+	// BMP is uncompressed, so each capture finishes sooner on large displays;
+	// pages are encoded as PNG/JPEG afterwards
+	CaptureFormat string `yaml:"captureFormat"`
 
 	// Delay between page turns (default: 500ms)
-	PageDelay time.Duration
+	PageDelay time.Duration `yaml:"pageDelay"`
+
+	// Replace the fixed PageDelay with polling captures until the page
+	// differs from the one before the turn and has settled, waiting at least
+	// MinPageDelay (default: 100ms) and at most MaxPageDelay (default: 3s)
+	AdaptiveDelay bool          `yaml:"adaptiveDelay"`
+	MinPageDelay  time.Duration `yaml:"minPageDelay"`
+	MaxPageDelay  time.Duration `yaml:"maxPageDelay"`
 
 	// Delay before starting automation (default: 3s)
-	StartupDelay time.Duration
+	StartupDelay time.Duration `yaml:"startupDelay"`
+
+	// Maximum wait for Kindle to come to front after activation (default: 5s)
+	// Polled every 100ms, so fast machines continue as soon as Kindle is frontmost
+	ActivationTimeout time.Duration `yaml:"activationTimeout"`
+
+	// Activations tried before capture gives up on Kindle coming to front
+	// (default: 1). Each retry activates again after a doubling pause from 1s;
+	// raise it when the switch to Kindle's fullscreen Space is slow
+	ActivationAttempts int `yaml:"activationAttempts"`
+
+	// Extra wait after Kindle comes to front, before the foreground is checked
+	// again and the page is captured (default: 0, capture right away)
+	// Set it when the first capture still shows the Space switch animation
+	SpaceSwitchSettle time.Duration `yaml:"spaceSwitchSettle"`
+
+	// Capture the first page again after a short pause and recapture while
+	// the two frames differ, in case the first capture still showed the
+	// switch to Kindle's fullscreen Space. Only the first page is delayed
+	VerifyFirstCapture bool `yaml:"verifyFirstCapture"`
+
+	// Delay after PDF generation so macOS clears the screen recording indicator
+	// (unset: 1s, 0: no delay)
+	PostCaptureDelay *time.Duration `yaml:"postCaptureDelay"`
+
+	// Maximum run time of a single osascript/screencapture call (default: 10s)
+	// A call blocked on e.g. a permission dialog fails and is retried instead of hanging
+	CommandTimeout time.Duration `yaml:"commandTimeout"`
 
-	// Show countdown timer during startup delay
-	ShowCountdown bool
+	// Deadline for a whole conversion (0 = none); when it passes, capture
+	// stops and the output is written from the pages captured so far
+	Timeout time.Duration `yaml:"timeout"`
+
+	// Show countdown timer during startup delay (default: true)
+	// Also shown with AutoConfirm, to give time to click into Kindle
+	ShowCountdown bool `yaml:"showCountdown"`
+
+	// Sleep through the startup delay silently instead of counting down
+	NoCountdown bool `yaml:"noCountdown"`
 
 	// PDF quality setting (low/medium/high, default: high)
-	PDFQuality string
+	PDFQuality string `yaml:"pdfQuality"`
 
 	// Enable verbose logging
-	Verbose bool
+	Verbose bool `yaml:"verbose"`
+
+	// Log every AppleScript call (script, raw stdout/stderr) for debugging
+	// activation and focus problems
+	DumpAppleScript bool `yaml:"dumpAppleScript"`
+
+	// Print the time spent in each stage (checks, activation, capture, page
+	// turns, trimming, output...) at the end of a text summary
+	VerboseTiming bool `yaml:"verboseTiming"`
 
 	// Auto-confirm overwrite without prompting
-	AutoConfirm bool
+	AutoConfirm bool `yaml:"autoConfirm"`
 
-	// Operation mode: "detect" (analyze margins) or "generate" (create PDF)
+	// Operation mode: "detect" (analyze margins), "generate" (create PDF),
+	// "benchmark" (measure the lowest reliable page delay), "trim-preview"
+	// (trim InputFile into OutputFile to check trim values), "merge"
+	// (concatenate the PDFs listed in InputFile into OutputFile),
+	// "images2pdf" (the images in the directory or CBZ/ZIP archive InputFile,
+	// in natural name order, into the PDF OutputFile) or "contact-sheet"
+	// (capture like generate, then tile page thumbnails with page-number
+	// captions on PDF sheets)
 	// Default: "generate"
-	Mode string
+	Mode string `yaml:"mode"`
+
+	// Clockwise page rotation in degrees: 0 (default), 90, 180 or 270
+	// Applied before trimming, so trim margins refer to the rotated page
+	Rotate int `yaml:"rotate"`
+
+	// Rotate pages whose orientation differs from most pages by 90°
+	// clockwise, after trimming, so all PDF pages share one orientation.
+	// Without it, landscape pages keep their own page shape
+	AutoRotate bool `yaml:"autoRotate"`
+
+	// Pad every page to the largest page width and height, centred on
+	// BackgroundColor (default: the page's own background), after trimming
+	// and AutoRotate, so pages whose size differs slightly still give one
+	// PDF page size
+	UniformPages bool `yaml:"uniformPages"`
+
+	// Invert page colors (dark-mode captures to black on white), after Rotate
+	Invert bool `yaml:"invert"`
+
+	// Remap a tinted paper colour (e.g. Kindle's sepia theme) to white, keeping
+	// text contrast and coloured illustrations; runs after Invert
+	NormalizePaper bool `yaml:"normalizePaper"`
 
 	// Custom trim margins in pixels (default: 0 = no trimming)
 	// Used when Mode == "generate" and any value is non-zero
-	TrimTop        int
-	TrimBottom     int
-	TrimHorizontal int
+	TrimTop        int `yaml:"trimTop"`
+	TrimBottom     int `yaml:"trimBottom"`
+	TrimHorizontal int `yaml:"trimHorizontal"`
+
+	// How detect mode combines per-page margins: "min" (default, safe for
+	// every page), "p10" or "p25" (percentile; ignores outliers such as
+	// full-bleed images, which then get cropped into)
+	TrimAggregate string `yaml:"trimAggregate"`
+
+	// 8-bit levels at or below / at or above which a pixel counts as black /
+	// white border when margins are detected (default: 60 / 195). Lower
+	// WhiteThreshold for grayish, anti-aliased page backgrounds
+	BlackThreshold int `yaml:"blackThreshold"`
+	WhiteThreshold int `yaml:"whiteThreshold"`
+
+	// Page turn key: "right", "left", or "auto" to detect it (default: "auto")
+	PageTurnKey string `yaml:"pageTurnKey"`
+
+	// Reader app to capture (default: "Kindle"), e.g. "Kindle Previewer 3" or
+	// "Books"; any app that turns pages with the arrow keys works
+	TargetApp string `yaml:"targetApp"`
+
+	// Input file path for PDF to Markdown conversion and trim preview, or the
+	// image directory or CBZ/ZIP archive for images2pdf
+	// Merge mode takes a comma-separated list (see ParseInputFiles)
+	InputFile string `yaml:"inputFile"`
+
+	// Output file path for trim preview (default: <input>_trimmed.png), merge
+	// and images2pdf mode (required)
+	OutputFile string `yaml:"outputFile"`
+
+	// PDF to Markdown: promote large-font lines to # / ## headings
+	MarkdownHeadings bool `yaml:"markdownHeadings"`
+
+	// PDF to Markdown: directory to extract embedded images to, referenced
+	// from the Markdown (empty: no images)
+	MarkdownImageDir string `yaml:"markdownImageDir"`
+
+	// Every this many captured pages, add them to <output>.partial.pdf, so a
+	// crash or failed run leaves a valid PDF of the pages captured so far
+	// (pages as captured, untrimmed). Removed once the output is written.
+	// PDF output with PNG/JPEG captures only (0 or unset: off)
+	IncrementalPDF int `yaml:"incrementalPDF"`
+
+	// Existing PDF to append the newly captured pages to, in place of a new
+	// output file (generate mode, PDF output only)
+	AppendTo string `yaml:"appendTo"`
+
+	// Output file name without extension (default: kindle_book_<timestamp>)
+	// Sanitized before use; watch mode sets this to the book title
+	OutputFileName string `yaml:"outputFileName"`
+
+	// What to do when the output file already exists: "ask" (default,
+	// confirm the overwrite; AutoConfirm answers yes), "overwrite" (replace
+	// it without asking) or "version" (keep it and write <name>_1, _2, ...)
+	OnConflict string `yaml:"onConflict"`
+
+	// Post a Notification Center alert when a conversion finishes or fails
+	Notify bool `yaml:"notify"`
+
+	// Final summary format: "text" (the human-readable block, default) or
+	// "json" (the Webhook document as one line, for logging pipelines)
+	OutputSummary string `yaml:"outputSummary"`
+
+	// http(s) URL that receives a JSON summary (output path, pages, size,
+	// duration, error) after every conversion; failures are only logged
+	Webhook string `yaml:"webhook"`
+
+	// Batch/watch progress file listing converted books, which are skipped
+	// after a restart (empty: off, nothing is skipped or recorded)
+	StateFile string `yaml:"stateFile"`
+
+	// Watch mode: how often the open book title is polled (default: 3s)
+	WatchInterval time.Duration `yaml:"watchInterval"`
+
+	// Watch mode: how long a new title must stay unchanged before converting (default: 5s)
+	WatchDebounce time.Duration `yaml:"watchDebounce"`
+
+	// What to do when Kindle loses the foreground mid-capture:
+	// "abort" (default, stop the run), "pause" (wait for the user, then
+	// bring Kindle back) or "refocus" (bring Kindle back once automatically)
+	OnFocusLost string `yaml:"onFocusLost"`
+
+	// Attempts per page turn (default: 3), separate from capture retries
+	// With TurnRefocus, a turn that failed because Kindle lost the foreground
+	// brings Kindle back before the next attempt; OnFocusLost still applies
+	// once the attempts are used up
+	TurnRetries int  `yaml:"turnRetries"`
+	TurnRefocus bool `yaml:"turnRefocus"`
+
+	// Times to send the turn again when a capture still shows the previous
+	// page, i.e. Kindle ignored the key while rendering (0 = off). A page that
+	// stays the same is kept, so end detection works as before
+	AdvanceRetries int `yaml:"advanceRetries"`
+
+	// How long a positive foreground check is reused (0 = off, check before
+	// every keystroke and capture). Saves an osascript call per page; focus is
+	// always checked in full at startup, and every keystroke still verifies it
+	// in the script that sends the key
+	ForegroundCache time.Duration `yaml:"foregroundCache"`
+
+	// Skip the Screen Recording / Accessibility permission preflight
+	SkipPreflight bool `yaml:"skipPreflight"`
+
+	// Allow an entirely black first capture (e.g. a dark cover)
+	// By default a black first page aborts the run, since it usually means
+	// Screen Recording permission is missing
+	AllowBlackFirstPage bool `yaml:"allowBlackFirstPage"`
+
+	// Screenshot region compared by direction and end detection, as "x,y,w,h"
+	// in screenshot pixels (empty = whole screenshot). Use it to ignore a static
+	// header/footer or a page number that changes on every page
+	DiffRegion string `yaml:"diffRegion"`
+
+	// Screenshot region of the page number or location indicator, as
+	// "x,y,w,h" (empty = off). When it stays the same for PageNumberStallTurns
+	// turns in a row (default: 3), the book has ended even if an animation
+	// keeps the rest of the screen changing
+	PageNumberRegion     string `yaml:"pageNumberRegion"`
+	PageNumberStallTurns int    `yaml:"pageNumberStallTurns"`
+
+	// Remove pages that repeat an earlier, non-adjacent page (e.g. recurring ads)
+	// Matches are found by dHash and confirmed pixel by pixel (default: off)
+	DedupAll bool `yaml:"dedupAll"`
+
+	// Similarity metric for direction and end detection (default: "pixel")
+	// "downscale-mad", "ssim" or "dhash"; thresholds were tuned for "pixel"
+	SimilarityAlgorithm string `yaml:"similarityAlgorithm"`
+
+	// Sample size (N for an NxN grid or thumbnail) the similarity metric works
+	// at for direction and end detection (default: 128, range 16-1024)
+	// Smaller is faster on large Retina captures but less accurate; "dhash"
+	// ignores it
+	SimilaritySize int `yaml:"similaritySize"`
+
+	// Similarity below which two captures count as different pages when
+	// detecting the page turn direction (0-1, default: 0.90). Raise it for books
+	// whose pages differ only slightly. Unrelated to end-of-book detection,
+	// which requires 99.5% similarity over 5 pages
+	DirectionChangeThreshold float64 `yaml:"directionChangeThreshold"`
+
+	// Minimum number of captured pages before end-of-book detection starts (default: 5)
+	// Values below the 5-page detection window behave like 5
+	EndDetectionMinPages int `yaml:"endDetectionMinPages"`
+
+	// Also end the capture when Kindle's UI shows the end-of-book screen
+	// (checked after every page; adds one accessibility query per page)
+	UIEndDetection bool `yaml:"uiEndDetection"`
+
+	// Watchdog: stop when this many consecutive captures are near-identical
+	// (98% similarity, looser than end-of-book detection), e.g. because Kindle
+	// froze; the pages captured so far are kept (0 or unset: off, else more
+	// than the 5 end-of-book screens, so a normal end is not taken for a stall)
+	StallPages int `yaml:"stallPages"`
+
+	// Capture only every Nth page (0 or 1: every page), e.g. to skim a book
+	// or build a contact sheet with NUp. The N page turns between captures
+	// are sent in one AppleScript call, 150ms apart, that re-checks the
+	// foreground before every key; PageDelay follows each batch, before the
+	// next capture
+	PageStep int `yaml:"pageStep"`
+
+	// Capture only the page Kindle is showing (e.g. the cover) as a PNG at
+	// the output path, without turning pages or writing a PDF (generate mode)
+	FirstPageOnly bool `yaml:"firstPageOnly"`
+
+	// Leave the first captured page (the cover) out of the output
+	SkipCover bool `yaml:"skipCover"`
+
+	// Do not apply custom trimming to the cover, so it stays full-bleed
+	NoTrimCover bool `yaml:"noTrimCover"`
+
+	// Maximum number of pages processed in parallel by image post-processing
+	// passes such as trimming (default: number of CPUs)
+	MaxConcurrency int `yaml:"maxConcurrency"`
+
+	// Keep up to this many captured pages decoded in memory and write the PDF
+	// from them (0: off): rotation, colour adjustments and trimming work on
+	// the decoded pages, so no page files are written or read back after
+	// capture. Captures still arrive as files. A book with more captures
+	// (end-of-book screens included), AutoRotate, UniformPages, contact
+	// sheets or EPUB output use the page files as usual
+	InMemoryPages int `yaml:"inMemoryPages"`
+
+	// Stamp each PDF page with its page number (default: off)
+	StampPageNumbers bool `yaml:"stampPageNumbers"`
+
+	// Page number position: "bottom-right" (default), "bottom-left", "bottom-center",
+	// "top-right", "top-left", "top-center"
+	StampPosition string `yaml:"stampPosition"`
+
+	// Page number font size in points (default: 10)
+	StampFontSize float64 `yaml:"stampFontSize"`
 
-	// Page turn key: "right" or "left" (default: "right")
-	PageTurnKey string
+	// PDF page size: "auto" (default, page matches each image), "A4", "A5",
+	// "Letter" or "Legal". Fixed sizes scale and center each image
+	PageSize string `yaml:"pageSize"`
 
-	// Input file path for PDF to Markdown conversion
-	InputFile string
+	// Margin around the image in points for fixed page sizes (default: 0)
+	PageMargin float64 `yaml:"pageMargin"`
+
+	// Filler colour as hex "#RRGGBB" (e.g. "#000000" for dark comics) for
+	// the letterbox around images on fixed page sizes and n-up sheets, and
+	// for UniformPages padding ("" = white pages; padding uses each page's
+	// own background)
+	BackgroundColor string `yaml:"backgroundColor"`
+
+	// Image resolution recorded in the PDF, which sets the printed size with
+	// "auto" page size (0 = default: the image's DPI tag, or 72). Retina
+	// screenshots print at their on-screen size with 144
+	DPI int `yaml:"dpi"`
+
+	// Shrink the finished PDF with qpdf or gs if installed (default: off)
+	Optimize bool `yaml:"optimize"`
+
+	// Kindle pages per PDF page: 1 (default), 2 (side by side, landscape) or
+	// 4 (2x2 grid). An "auto" page size uses A4 sheets
+	NUp int `yaml:"nUp"`
+
+	// Contact sheet mode: thumbnails per row (default: 5) and the longest
+	// side of each thumbnail in pixels (default: 400)
+	ContactSheetColumns   int `yaml:"contactSheetColumns"`
+	ContactSheetThumbSize int `yaml:"contactSheetThumbSize"`
+
+	// Output format: "pdf" or "epub" (default: "pdf")
+	// EPUB output runs OCR on every captured page
+	OutputFormat string `yaml:"outputFormat"`
+
+	// Number of pages grouped into one EPUB chapter (default: 10)
+	EPUBPagesPerChapter int `yaml:"epubPagesPerChapter"`
+
+	// Embed the captured page images in the EPUB alongside the recognized text
+	EPUBEmbedImages bool `yaml:"epubEmbedImages"`
 }
 
 // ApplyDefaults applies default values to any unset options
@@ -53,20 +367,59 @@ func ApplyDefaults(opts *ConversionOptions) *ConversionOptions {
 	// Create a copy to avoid modifying original if nil (though usually not nil here)
 	merged := &ConversionOptions{
 		// Set defaults first
-		ScreenshotQuality: 100,
-		PageDelay:         500 * time.Millisecond,
-		StartupDelay:      3 * time.Second,
-		ShowCountdown:     true,
-		PDFQuality:        "high",
-		Verbose:           false,
-		AutoConfirm:       false,
-		Mode:              "generate",
-		TrimTop:           0,
-		TrimBottom:        0,
-		TrimHorizontal:    0,
+		ScreenshotQuality:  100,
+		CaptureFormat:      "png",
+		PageDelay:          500 * time.Millisecond,
+		MinPageDelay:       100 * time.Millisecond,
+		MaxPageDelay:       3 * time.Second,
+		StartupDelay:       3 * time.Second,
+		ActivationTimeout:  5 * time.Second,
+		ActivationAttempts: 1,
+		CommandTimeout:     10 * time.Second,
+		ShowCountdown:      true,
+		PDFQuality:         "high",
+		Verbose:            false,
+		AutoConfirm:        false,
+		Mode:               "generate",
+		TrimTop:            0,
+		TrimBottom:         0,
+		TrimHorizontal:     0,
 
-		PageTurnKey: "right",
+		PageTurnKey:    "auto",
+		TargetApp:      "Kindle",
+		TrimAggregate:  "min",
+		BlackThreshold: 60,
+		WhiteThreshold: 195,
+
+		EndDetectionMinPages:     5,
+		DirectionChangeThreshold: 0.90,
+		SimilarityAlgorithm:      "pixel",
+		SimilaritySize:           128,
+		OnFocusLost:              "abort",
+		OnConflict:               "ask",
+		PageNumberStallTurns:     3,
+		TurnRetries:              3,
+		MaxConcurrency:           runtime.NumCPU(),
+
+		StampPosition: "bottom-right",
+		StampFontSize: 10,
+		PageSize:      "auto",
+		NUp:           1,
+
+		ContactSheetColumns:   5,
+		ContactSheetThumbSize: 400,
+
+		OutputFormat:        "pdf",
+		EPUBPagesPerChapter: 10,
+
+		OutputSummary: "text",
+
+		WatchInterval: 3 * time.Second,
+		WatchDebounce: 5 * time.Second,
 	}
+	// A pointer, so that an explicit 0 turns the delay off
+	postCaptureDelay := 1 * time.Second
+	merged.PostCaptureDelay = &postCaptureDelay
 
 	if opts == nil {
 		return merged
@@ -79,12 +432,42 @@ func ApplyDefaults(opts *ConversionOptions) *ConversionOptions {
 	if opts.ScreenshotQuality != 0 {
 		merged.ScreenshotQuality = opts.ScreenshotQuality
 	}
+	if opts.CaptureFormat != "" {
+		merged.CaptureFormat = opts.CaptureFormat
+	}
 	if opts.PageDelay != 0 {
 		merged.PageDelay = opts.PageDelay
 	}
+	if opts.AdaptiveDelay {
+		merged.AdaptiveDelay = true
+	}
+	if opts.MinPageDelay != 0 {
+		merged.MinPageDelay = opts.MinPageDelay
+	}
+	if opts.MaxPageDelay != 0 {
+		merged.MaxPageDelay = opts.MaxPageDelay
+	}
 	if opts.StartupDelay != 0 {
 		merged.StartupDelay = opts.StartupDelay
 	}
+	if opts.ActivationTimeout != 0 {
+		merged.ActivationTimeout = opts.ActivationTimeout
+	}
+	if opts.ActivationAttempts != 0 {
+		merged.ActivationAttempts = opts.ActivationAttempts
+	}
+	if opts.SpaceSwitchSettle != 0 {
+		merged.SpaceSwitchSettle = opts.SpaceSwitchSettle
+	}
+	if opts.PostCaptureDelay != nil {
+		merged.PostCaptureDelay = opts.PostCaptureDelay
+	}
+	if opts.CommandTimeout != 0 {
+		merged.CommandTimeout = opts.CommandTimeout
+	}
+	if opts.Timeout != 0 {
+		merged.Timeout = opts.Timeout
+	}
 	if opts.PDFQuality != "" {
 		merged.PDFQuality = opts.PDFQuality
 	}
@@ -94,12 +477,20 @@ func ApplyDefaults(opts *ConversionOptions) *ConversionOptions {
 	if opts.Verbose {
 		merged.Verbose = true
 	}
+	if opts.DumpAppleScript {
+		merged.DumpAppleScript = true
+	}
+	if opts.VerboseTiming {
+		merged.VerboseTiming = true
+	}
 	if opts.AutoConfirm {
 		merged.AutoConfirm = true
 	}
 
-	// ShowCountdown is not exposed in CLI, so we stick to default (true)
-	// unless we decide to expose it later.
+	// ShowCountdown defaults to true, so turning it off needs NoCountdown
+	if opts.NoCountdown {
+		merged.ShowCountdown = false
+	}
 
 	if opts.Mode != "" {
 		merged.Mode = opts.Mode
@@ -114,13 +505,197 @@ func ApplyDefaults(opts *ConversionOptions) *ConversionOptions {
 		merged.TrimHorizontal = opts.TrimHorizontal
 	}
 
-	if opts.PageTurnKey != "" {
-		merged.PageTurnKey = opts.PageTurnKey
+	if key := NormalizePageTurnKey(opts.PageTurnKey); key != "" {
+		merged.PageTurnKey = key
+	}
+	if opts.TargetApp != "" {
+		merged.TargetApp = opts.TargetApp
 	}
 
+	if opts.TrimAggregate != "" {
+		merged.TrimAggregate = opts.TrimAggregate
+	}
+	if opts.BlackThreshold != 0 {
+		merged.BlackThreshold = opts.BlackThreshold
+	}
+	if opts.WhiteThreshold != 0 {
+		merged.WhiteThreshold = opts.WhiteThreshold
+	}
 	if opts.InputFile != "" {
 		merged.InputFile = opts.InputFile
 	}
+	if opts.OutputFile != "" {
+		merged.OutputFile = opts.OutputFile
+	}
+	if opts.MarkdownHeadings {
+		merged.MarkdownHeadings = true
+	}
+	if opts.MarkdownImageDir != "" {
+		merged.MarkdownImageDir = opts.MarkdownImageDir
+	}
+	if opts.IncrementalPDF != 0 {
+		merged.IncrementalPDF = opts.IncrementalPDF
+	}
+	if opts.AppendTo != "" {
+		merged.AppendTo = opts.AppendTo
+	}
+
+	if opts.SkipPreflight {
+		merged.SkipPreflight = true
+	}
+	if opts.AllowBlackFirstPage {
+		merged.AllowBlackFirstPage = true
+	}
+	if opts.EndDetectionMinPages != 0 {
+		merged.EndDetectionMinPages = opts.EndDetectionMinPages
+	}
+	if opts.UIEndDetection {
+		merged.UIEndDetection = true
+	}
+	if opts.StallPages != 0 {
+		merged.StallPages = opts.StallPages
+	}
+
+	if opts.OutputFileName != "" {
+		merged.OutputFileName = opts.OutputFileName
+	}
+	if opts.OnConflict != "" {
+		merged.OnConflict = opts.OnConflict
+	}
+	if opts.Notify {
+		merged.Notify = true
+	}
+	if opts.OutputSummary != "" {
+		merged.OutputSummary = opts.OutputSummary
+	}
+	if opts.Webhook != "" {
+		merged.Webhook = opts.Webhook
+	}
+	if opts.StateFile != "" {
+		merged.StateFile = opts.StateFile
+	}
+	if opts.WatchInterval != 0 {
+		merged.WatchInterval = opts.WatchInterval
+	}
+	if opts.WatchDebounce != 0 {
+		merged.WatchDebounce = opts.WatchDebounce
+	}
+
+	if opts.TurnRetries != 0 {
+		merged.TurnRetries = opts.TurnRetries
+	}
+	if opts.TurnRefocus {
+		merged.TurnRefocus = true
+	}
+	if opts.AdvanceRetries != 0 {
+		merged.AdvanceRetries = opts.AdvanceRetries
+	}
+	if opts.OnFocusLost != "" {
+		merged.OnFocusLost = opts.OnFocusLost
+	}
+	if opts.ForegroundCache != 0 {
+		merged.ForegroundCache = opts.ForegroundCache
+	}
+	if opts.DedupAll {
+		merged.DedupAll = true
+	}
+	if opts.SimilarityAlgorithm != "" {
+		merged.SimilarityAlgorithm = opts.SimilarityAlgorithm
+	}
+	if opts.DiffRegion != "" {
+		merged.DiffRegion = opts.DiffRegion
+	}
+	if opts.PageNumberRegion != "" {
+		merged.PageNumberRegion = opts.PageNumberRegion
+	}
+	if opts.PageNumberStallTurns != 0 {
+		merged.PageNumberStallTurns = opts.PageNumberStallTurns
+	}
+	if opts.SimilaritySize != 0 {
+		merged.SimilaritySize = opts.SimilaritySize
+	}
+	if opts.DirectionChangeThreshold != 0 {
+		merged.DirectionChangeThreshold = opts.DirectionChangeThreshold
+	}
+	if opts.MaxConcurrency != 0 {
+		merged.MaxConcurrency = opts.MaxConcurrency
+	}
+	if opts.InMemoryPages != 0 {
+		merged.InMemoryPages = opts.InMemoryPages
+	}
+	if opts.PageStep != 0 {
+		merged.PageStep = opts.PageStep
+	}
+	if opts.FirstPageOnly {
+		merged.FirstPageOnly = true
+	}
+	if opts.SkipCover {
+		merged.SkipCover = true
+	}
+	if opts.NoTrimCover {
+		merged.NoTrimCover = true
+	}
+	if opts.StampPageNumbers {
+		merged.StampPageNumbers = true
+	}
+	if opts.StampPosition != "" {
+		merged.StampPosition = opts.StampPosition
+	}
+	if opts.StampFontSize != 0 {
+		merged.StampFontSize = opts.StampFontSize
+	}
+	if opts.PageSize != "" {
+		merged.PageSize = opts.PageSize
+	}
+	if opts.PageMargin != 0 {
+		merged.PageMargin = opts.PageMargin
+	}
+	if opts.BackgroundColor != "" {
+		merged.BackgroundColor = opts.BackgroundColor
+	}
+	if opts.DPI != 0 {
+		merged.DPI = opts.DPI
+	}
+	if opts.Rotate != 0 {
+		merged.Rotate = opts.Rotate
+	}
+	if opts.AutoRotate {
+		merged.AutoRotate = true
+	}
+	if opts.UniformPages {
+		merged.UniformPages = true
+	}
+	if opts.VerifyFirstCapture {
+		merged.VerifyFirstCapture = true
+	}
+	if opts.Invert {
+		merged.Invert = true
+	}
+	if opts.NormalizePaper {
+		merged.NormalizePaper = true
+	}
+	if opts.NUp != 0 {
+		merged.NUp = opts.NUp
+	}
+	if opts.ContactSheetColumns != 0 {
+		merged.ContactSheetColumns = opts.ContactSheetColumns
+	}
+	if opts.ContactSheetThumbSize != 0 {
+		merged.ContactSheetThumbSize = opts.ContactSheetThumbSize
+	}
+	if opts.Optimize {
+		merged.Optimize = true
+	}
+
+	if opts.OutputFormat != "" {
+		merged.OutputFormat = opts.OutputFormat
+	}
+	if opts.EPUBPagesPerChapter != 0 {
+		merged.EPUBPagesPerChapter = opts.EPUBPagesPerChapter
+	}
+	if opts.EPUBEmbedImages {
+		merged.EPUBEmbedImages = true
+	}
 
 	return merged
 }
@@ -131,14 +706,267 @@ func (o *ConversionOptions) Validate() error {
 		return fmt.Errorf("screenshot quality must be between 1 and 100")
 	}
 
+	validCaptureFormats := map[string]bool{"": true, "png": true, "bmp": true}
+	if !validCaptureFormats[o.CaptureFormat] {
+		return fmt.Errorf("capture format must be 'png' or 'bmp'")
+	}
+
+	if o.MinPageDelay < 0 || o.MaxPageDelay < 0 {
+		return fmt.Errorf("page delays must not be negative")
+	}
+	if o.AdaptiveDelay && o.MaxPageDelay < o.MinPageDelay {
+		return fmt.Errorf("max page delay must not be less than min page delay")
+	}
+
 	validPDFQualities := map[string]bool{"low": true, "medium": true, "high": true}
 	if !validPDFQualities[o.PDFQuality] {
 		return fmt.Errorf("pdf quality must be 'low', 'medium', or 'high'")
 	}
 
+	validOutputFormats := map[string]bool{"": true, "pdf": true, "epub": true}
+	if !validOutputFormats[o.OutputFormat] {
+		return fmt.Errorf("output format must be 'pdf' or 'epub'")
+	}
+
+	validOutputSummaries := map[string]bool{"": true, "text": true, "json": true}
+	if !validOutputSummaries[o.OutputSummary] {
+		return fmt.Errorf("output summary must be 'text' or 'json'")
+	}
+
+	validPageTurnKeys := map[string]bool{"": true, "right": true, "left": true, "auto": true}
+	if !validPageTurnKeys[NormalizePageTurnKey(o.PageTurnKey)] {
+		return fmt.Errorf("page turn key must be 'right', 'left', or 'auto' (got %q)", o.PageTurnKey)
+	}
+
+	validTrimAggregates := map[string]bool{"": true, "min": true, "p10": true, "p25": true}
+	if !validTrimAggregates[o.TrimAggregate] {
+		return fmt.Errorf("trim aggregate must be min, p10 or p25")
+	}
+	if o.BlackThreshold < 0 || o.BlackThreshold > 255 || o.WhiteThreshold < 0 || o.WhiteThreshold > 255 {
+		return fmt.Errorf("black and white thresholds must be between 0 and 255")
+	}
+	if o.BlackThreshold != 0 && o.WhiteThreshold != 0 && o.BlackThreshold >= o.WhiteThreshold {
+		return fmt.Errorf("black threshold (%d) must be below white threshold (%d)", o.BlackThreshold, o.WhiteThreshold)
+	}
+
+	if o.EndDetectionMinPages < 0 {
+		return fmt.Errorf("end detection minimum pages must not be negative")
+	}
+	if o.IncrementalPDF < 0 {
+		return fmt.Errorf("incremental PDF page count must not be negative")
+	}
+	if o.IncrementalPDF > 0 && (o.OutputFormat == "epub" || o.CaptureFormat == "bmp") {
+		return fmt.Errorf("incremental PDF needs PDF output and PNG or JPEG captures")
+	}
+	if o.StallPages < 0 || (o.StallPages > 0 && o.StallPages <= 5) {
+		return fmt.Errorf("stall pages must be 0 (off) or more than 5, the end-of-book screens")
+	}
+
+	validStampPositions := map[string]bool{"": true, "bottom-right": true, "bottom-left": true,
+		"bottom-center": true, "top-right": true, "top-left": true, "top-center": true}
+	if !validStampPositions[o.StampPosition] {
+		return fmt.Errorf("stamp position must be one of bottom-right, bottom-left, bottom-center, top-right, top-left, top-center")
+	}
+	if o.StampFontSize < 0 {
+		return fmt.Errorf("stamp font size must not be negative")
+	}
+
+	validPageSizes := map[string]bool{"": true, "auto": true, "a4": true, "a5": true, "letter": true, "legal": true}
+	if !validPageSizes[strings.ToLower(o.PageSize)] {
+		return fmt.Errorf("page size must be one of auto, A4, A5, Letter, Legal")
+	}
+	if o.PageMargin < 0 {
+		return fmt.Errorf("page margin must not be negative")
+	}
+	if _, err := ParseBackgroundColor(o.BackgroundColor); err != nil {
+		return err
+	}
+	if o.DPI < 0 || o.DPI > 2400 {
+		return fmt.Errorf("dpi must be between 1 and 2400 (or 0 for the image's own)")
+	}
+	validRotations := map[int]bool{0: true, 90: true, 180: true, 270: true}
+	if !validRotations[o.Rotate] {
+		return fmt.Errorf("rotation must be 0, 90, 180 or 270 degrees, got %d", o.Rotate)
+	}
+	validNUp := map[int]bool{0: true, 1: true, 2: true, 4: true}
+	if !validNUp[o.NUp] {
+		return fmt.Errorf("n-up must be 1, 2 or 4")
+	}
+
+	if o.DirectionChangeThreshold < 0 || o.DirectionChangeThreshold > 1 {
+		return fmt.Errorf("direction change threshold must be between 0 and 1")
+	}
+
+	validSimilarity := map[string]bool{"": true, "pixel": true, "downscale-mad": true, "ssim": true, "dhash": true}
+	if !validSimilarity[o.SimilarityAlgorithm] {
+		return fmt.Errorf("similarity algorithm must be pixel, downscale-mad, ssim or dhash")
+	}
+
+	if o.SimilaritySize != 0 && (o.SimilaritySize < 16 || o.SimilaritySize > 1024) {
+		return fmt.Errorf("similarity sample size must be between 16 and 1024")
+	}
+
+	if _, err := ParseDiffRegion(o.DiffRegion); err != nil {
+		return err
+	}
+	if _, err := ParseDiffRegion(o.PageNumberRegion); err != nil {
+		return fmt.Errorf("page number region: %w", err)
+	}
+	if o.PageNumberStallTurns < 0 {
+		return fmt.Errorf("page number stall turns must not be negative")
+	}
+
+	if o.TurnRetries < 0 {
+		return fmt.Errorf("turn retries must not be negative")
+	}
+	if o.AdvanceRetries < 0 {
+		return fmt.Errorf("advance retries must not be negative")
+	}
+	if o.ActivationAttempts < 0 {
+		return fmt.Errorf("activation attempts must not be negative")
+	}
+	if o.SpaceSwitchSettle < 0 {
+		return fmt.Errorf("space switch settle must not be negative")
+	}
+	if o.PostCaptureDelay != nil && *o.PostCaptureDelay < 0 {
+		return fmt.Errorf("post-capture delay must not be negative")
+	}
+
+	if o.Timeout < 0 || o.CommandTimeout < 0 {
+		return fmt.Errorf("timeouts must not be negative")
+	}
+
+	if o.MaxConcurrency < 0 {
+		return fmt.Errorf("max concurrency must not be negative")
+	}
+	if o.InMemoryPages < 0 {
+		return fmt.Errorf("in-memory pages must not be negative")
+	}
+
+	validFocusLost := map[string]bool{"": true, "abort": true, "pause": true, "refocus": true}
+	if !validFocusLost[o.OnFocusLost] {
+		return fmt.Errorf("on-focus-lost must be abort, pause or refocus")
+	}
+	validConflict := map[string]bool{"": true, "ask": true, "overwrite": true, "version": true}
+	if !validConflict[o.OnConflict] {
+		return fmt.Errorf("on-conflict must be ask, overwrite or version")
+	}
+
+	if o.PageStep < 0 {
+		return fmt.Errorf("page step must not be negative")
+	}
+
+	if o.ForegroundCache < 0 {
+		return fmt.Errorf("foreground cache must not be negative")
+	}
+
+	if o.EPUBPagesPerChapter < 0 {
+		return fmt.Errorf("EPUB pages per chapter must be positive")
+	}
+
 	if o.Mode == "pdf2md" && o.InputFile == "" {
 		return fmt.Errorf("input file is required for pdf2md mode")
 	}
+	if o.Mode == "trim-preview" && o.InputFile == "" {
+		return fmt.Errorf("input file is required for trim-preview mode")
+	}
+	if o.Mode == "images2pdf" && (o.InputFile == "" || o.OutputFile == "") {
+		return fmt.Errorf("input and output files are required for images2pdf mode")
+	}
+	if o.Mode == "contact-sheet" && o.OutputFormat == "epub" {
+		return fmt.Errorf("contact sheets are only supported as PDF output")
+	}
+	if o.ContactSheetColumns < 0 || o.ContactSheetThumbSize < 0 {
+		return fmt.Errorf("contact sheet columns and thumbnail size must not be negative")
+	}
+	if o.Mode == "merge" {
+		if len(ParseInputFiles(o.InputFile)) < 2 {
+			return fmt.Errorf("at least two input PDFs are required for merge mode")
+		}
+		if o.OutputFile == "" {
+			return fmt.Errorf("output file is required for merge mode")
+		}
+	}
+	if o.Webhook != "" {
+		u, err := url.Parse(o.Webhook)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("webhook must be an http or https URL (got %q)", o.Webhook)
+		}
+	}
+	if o.FirstPageOnly {
+		if o.Mode != "" && o.Mode != "generate" {
+			return fmt.Errorf("first page only is only supported in generate mode")
+		}
+		if o.AppendTo != "" {
+			return fmt.Errorf("first page only cannot append to a PDF")
+		}
+	}
+	if o.AppendTo != "" {
+		if o.Mode != "" && o.Mode != "generate" {
+			return fmt.Errorf("append is only supported in generate mode")
+		}
+		if o.OutputFormat == "epub" {
+			return fmt.Errorf("append is only supported for PDF output")
+		}
+	}
 
 	return nil
 }
+
+// ParseInputFiles splits a comma- or newline-separated list of paths
+// Surrounding spaces and empty entries are dropped
+func ParseInputFiles(s string) []string {
+	var files []string
+	for _, f := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == '\n' }) {
+		if f = strings.TrimSpace(f); f != "" {
+			files = append(files, f)
+		}
+	}
+	return files
+}
+
+// ParseDiffRegion parses a "x,y,w,h" diff region into a rectangle
+// An empty string yields an empty rectangle, meaning the whole screenshot
+func ParseDiffRegion(s string) (image.Rectangle, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return image.Rectangle{}, nil
+	}
+
+	parts := strings.Split(s, ",")
+	if len(parts) != 4 {
+		return image.Rectangle{}, fmt.Errorf("diff region must be x,y,w,h (got %q)", s)
+	}
+	var v [4]int
+	for i, part := range parts {
+		n, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			return image.Rectangle{}, fmt.Errorf("diff region must be x,y,w,h (got %q)", s)
+		}
+		v[i] = n
+	}
+	if v[0] < 0 || v[1] < 0 || v[2] <= 0 || v[3] <= 0 {
+		return image.Rectangle{}, fmt.Errorf("diff region needs a non-negative origin and a positive size (got %q)", s)
+	}
+
+	return image.Rect(v[0], v[1], v[0]+v[2], v[1]+v[3]), nil
+}
+
+// ParseBackgroundColor parses a "#RRGGBB" (or "RRGGBB") background colour
+// An empty string yields nil, meaning the default background
+func ParseBackgroundColor(s string) (color.Color, error) {
+	hex := strings.TrimPrefix(strings.TrimSpace(s), "#")
+	if hex == "" {
+		return nil, nil
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil || len(hex) != 6 {
+		return nil, fmt.Errorf("background color must be #RRGGBB (got %q)", s)
+	}
+	return color.RGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 255}, nil
+}
+
+// NormalizePageTurnKey lower-cases and trims a page turn key so "Right " matches "right"
+func NormalizePageTurnKey(key string) string {
+	return strings.ToLower(strings.TrimSpace(key))
+}
@@ -35,7 +35,7 @@ func TestProperty29_DefaultConfiguration(t *testing.T) {
 			if result.Mode != "generate" && result.Mode != "detect" {
 				return false
 			}
-			if result.PageTurnKey != "right" && result.PageTurnKey != "left" {
+			if result.PageTurnKey != "right" && result.PageTurnKey != "left" && result.PageTurnKey != "auto" {
 				return false
 			}
 			if len(result.PDFQuality) == 0 {
@@ -58,7 +58,7 @@ func TestProperty29_DefaultConfiguration(t *testing.T) {
 			"TrimBottom":        gen.Int(),
 			"TrimLeft":          gen.Int(),
 			"TrimRight":         gen.Int(),
-			"PageTurnKey":       gen.OneConstOf("", "left", "right"),
+			"PageTurnKey":       gen.OneConstOf("", "left", "right", "auto"),
 		})),
 	))
 
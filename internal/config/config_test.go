@@ -1,6 +1,10 @@
 package config
 
 import (
+	"image"
+	"image/color"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 )
@@ -18,12 +22,54 @@ func TestApplyDefaults(t *testing.T) {
 		if defaults.Mode != "generate" {
 			t.Errorf("Expected default mode 'generate', got %s", defaults.Mode)
 		}
-		if defaults.PageTurnKey != "right" {
-			t.Errorf("Expected default page turn key 'right', got %s", defaults.PageTurnKey)
+		if defaults.PageTurnKey != "auto" {
+			t.Errorf("Expected default page turn key 'auto', got %s", defaults.PageTurnKey)
 		}
 		if !defaults.ShowCountdown {
 			t.Error("Expected default ShowCountdown=true")
 		}
+		if defaults.PostCaptureDelay == nil || *defaults.PostCaptureDelay != 1*time.Second {
+			t.Errorf("Expected default post-capture delay 1s, got %v", defaults.PostCaptureDelay)
+		}
+		if defaults.EndDetectionMinPages != 5 {
+			t.Errorf("Expected default end detection min pages 5, got %d", defaults.EndDetectionMinPages)
+		}
+		if defaults.DirectionChangeThreshold != 0.90 {
+			t.Errorf("Expected default direction change threshold 0.90, got %v", defaults.DirectionChangeThreshold)
+		}
+		if defaults.WatchInterval != 3*time.Second || defaults.WatchDebounce != 5*time.Second {
+			t.Errorf("Expected default watch interval/debounce 3s/5s, got %v/%v",
+				defaults.WatchInterval, defaults.WatchDebounce)
+		}
+	})
+
+	t.Run("PostCaptureDelay 0 disables the post-capture delay", func(t *testing.T) {
+		for _, want := range []time.Duration{0, 2 * time.Second} {
+			delay := want
+			got := ApplyDefaults(&ConversionOptions{PostCaptureDelay: &delay}).PostCaptureDelay
+			if got == nil || *got != want {
+				t.Errorf("Expected post-capture delay %v, got %v", want, got)
+			}
+		}
+	})
+
+	t.Run("NoCountdown turns the countdown off", func(t *testing.T) {
+		merged := ApplyDefaults(&ConversionOptions{AutoConfirm: true, NoCountdown: true})
+
+		if merged.ShowCountdown {
+			t.Error("Expected ShowCountdown=false with NoCountdown")
+		}
+		if !ApplyDefaults(&ConversionOptions{AutoConfirm: true}).ShowCountdown {
+			t.Error("Expected ShowCountdown=true with AutoConfirm")
+		}
+	})
+
+	t.Run("Page turn key is normalized", func(t *testing.T) {
+		merged := ApplyDefaults(&ConversionOptions{PageTurnKey: " Left "})
+
+		if merged.PageTurnKey != "left" {
+			t.Errorf("Expected page turn key 'left', got %q", merged.PageTurnKey)
+		}
 	})
 
 	t.Run("Override defaults with provided values", func(t *testing.T) {
@@ -121,6 +167,366 @@ func TestValidate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "Valid EPUB output format",
+			opts: &ConversionOptions{
+				ScreenshotQuality: 95,
+				PDFQuality:        "high",
+				OutputFormat:      "epub",
+			},
+			wantErr: false,
+		},
+		{
+			name: "Valid auto page turn key",
+			opts: &ConversionOptions{
+				ScreenshotQuality: 95,
+				PDFQuality:        "high",
+				PageTurnKey:       "auto",
+			},
+			wantErr: false,
+		},
+		{
+			name: "Invalid page turn key",
+			opts: &ConversionOptions{
+				ScreenshotQuality: 95,
+				PDFQuality:        "high",
+				PageTurnKey:       "rihgt",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Negative end detection min pages",
+			opts: &ConversionOptions{
+				ScreenshotQuality:    95,
+				PDFQuality:           "high",
+				EndDetectionMinPages: -1,
+			},
+			wantErr: true,
+		},
+		{
+			name: "Direction change threshold above 1",
+			opts: &ConversionOptions{
+				ScreenshotQuality:        95,
+				PDFQuality:               "high",
+				DirectionChangeThreshold: 1.5,
+			},
+			wantErr: true,
+		},
+		{
+			name: "Unknown similarity algorithm",
+			opts: &ConversionOptions{
+				ScreenshotQuality:   95,
+				PDFQuality:          "high",
+				SimilarityAlgorithm: "psnr",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Append to EPUB",
+			opts: &ConversionOptions{
+				ScreenshotQuality: 95,
+				PDFQuality:        "high",
+				OutputFormat:      "epub",
+				AppendTo:          "book.pdf",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Append in detect mode",
+			opts: &ConversionOptions{
+				ScreenshotQuality: 95,
+				PDFQuality:        "high",
+				Mode:              "detect",
+				AppendTo:          "book.pdf",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Merge with one input",
+			opts: &ConversionOptions{
+				ScreenshotQuality: 95,
+				PDFQuality:        "high",
+				Mode:              "merge",
+				InputFile:         "a.pdf",
+				OutputFile:        "merged.pdf",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Merge without output",
+			opts: &ConversionOptions{
+				ScreenshotQuality: 95,
+				PDFQuality:        "high",
+				Mode:              "merge",
+				InputFile:         "a.pdf,b.pdf",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Images2pdf without output",
+			opts: &ConversionOptions{
+				ScreenshotQuality: 95,
+				PDFQuality:        "high",
+				Mode:              "images2pdf",
+				InputFile:         "book.cbz",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Rotation not a multiple of 90",
+			opts: &ConversionOptions{
+				ScreenshotQuality: 95,
+				PDFQuality:        "high",
+				Rotate:            45,
+			},
+			wantErr: true,
+		},
+		{
+			name: "Negative rotation",
+			opts: &ConversionOptions{
+				ScreenshotQuality: 95,
+				PDFQuality:        "high",
+				Rotate:            -90,
+			},
+			wantErr: true,
+		},
+		{
+			name: "Full-turn rotation",
+			opts: &ConversionOptions{
+				ScreenshotQuality: 95,
+				PDFQuality:        "high",
+				Rotate:            360,
+			},
+			wantErr: true,
+		},
+		{
+			name: "Rotation beyond a full turn",
+			opts: &ConversionOptions{
+				ScreenshotQuality: 95,
+				PDFQuality:        "high",
+				Rotate:            450,
+			},
+			wantErr: true,
+		},
+		{
+			name: "Rotation of 270",
+			opts: &ConversionOptions{
+				ScreenshotQuality: 95,
+				PDFQuality:        "high",
+				Rotate:            270,
+			},
+			wantErr: false,
+		},
+		{
+			name: "Stall pages within the end-of-book screens",
+			opts: &ConversionOptions{
+				ScreenshotQuality: 95,
+				PDFQuality:        "high",
+				StallPages:        5,
+			},
+			wantErr: true,
+		},
+		{
+			name: "Stall pages beyond the end-of-book screens",
+			opts: &ConversionOptions{
+				ScreenshotQuality: 95,
+				PDFQuality:        "high",
+				StallPages:        6,
+			},
+			wantErr: false,
+		},
+		{
+			name: "Black threshold above white threshold",
+			opts: &ConversionOptions{
+				ScreenshotQuality: 95,
+				PDFQuality:        "high",
+				BlackThreshold:    200,
+				WhiteThreshold:    180,
+			},
+			wantErr: true,
+		},
+		{
+			name: "White threshold out of range",
+			opts: &ConversionOptions{
+				ScreenshotQuality: 95,
+				PDFQuality:        "high",
+				WhiteThreshold:    256,
+			},
+			wantErr: true,
+		},
+		{
+			name: "Webhook without scheme",
+			opts: &ConversionOptions{
+				ScreenshotQuality: 95,
+				PDFQuality:        "high",
+				Webhook:           "homeserver.local/hook",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Unknown trim aggregate",
+			opts: &ConversionOptions{
+				ScreenshotQuality: 95,
+				PDFQuality:        "high",
+				TrimAggregate:     "p50",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Negative DPI",
+			opts: &ConversionOptions{
+				ScreenshotQuality: 95,
+				PDFQuality:        "high",
+				DPI:               -72,
+			},
+			wantErr: true,
+		},
+		{
+			name: "Malformed diff region",
+			opts: &ConversionOptions{
+				ScreenshotQuality: 95,
+				PDFQuality:        "high",
+				DiffRegion:        "0,100,800",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Invalid stamp position",
+			opts: &ConversionOptions{
+				ScreenshotQuality: 95,
+				PDFQuality:        "high",
+				StampPosition:     "middle",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Invalid page size",
+			opts: &ConversionOptions{
+				ScreenshotQuality: 95,
+				PDFQuality:        "high",
+				PageSize:          "B5",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Invalid n-up",
+			opts: &ConversionOptions{
+				ScreenshotQuality: 95,
+				PDFQuality:        "high",
+				NUp:               3,
+			},
+			wantErr: true,
+		},
+		{
+			name: "Invalid on-focus-lost",
+			opts: &ConversionOptions{
+				ScreenshotQuality: 95,
+				PDFQuality:        "high",
+				OnFocusLost:       "ignore",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Adaptive delay max below min",
+			opts: &ConversionOptions{
+				ScreenshotQuality: 95,
+				PDFQuality:        "high",
+				AdaptiveDelay:     true,
+				MinPageDelay:      time.Second,
+				MaxPageDelay:      500 * time.Millisecond,
+			},
+			wantErr: true,
+		},
+		{
+			name: "Invalid page number region",
+			opts: &ConversionOptions{
+				ScreenshotQuality: 95,
+				PDFQuality:        "high",
+				PageNumberRegion:  "0,0,10",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Invalid on-conflict",
+			opts: &ConversionOptions{
+				ScreenshotQuality: 95,
+				PDFQuality:        "high",
+				OnConflict:        "rename",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Invalid output format",
+			opts: &ConversionOptions{
+				ScreenshotQuality: 95,
+				PDFQuality:        "high",
+				OutputFormat:      "mobi",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Negative activation attempts",
+			opts: &ConversionOptions{
+				ScreenshotQuality:  95,
+				PDFQuality:         "high",
+				ActivationAttempts: -1,
+			},
+			wantErr: true,
+		},
+		{
+			name: "Negative post-capture delay",
+			opts: &ConversionOptions{
+				ScreenshotQuality: 95,
+				PDFQuality:        "high",
+				PostCaptureDelay:  durationPtr(-1),
+			},
+			wantErr: true,
+		},
+		{
+			name: "Negative space switch settle",
+			opts: &ConversionOptions{
+				ScreenshotQuality: 95,
+				PDFQuality:        "high",
+				SpaceSwitchSettle: -time.Second,
+			},
+			wantErr: true,
+		},
+		{
+			name: "Negative in-memory pages",
+			opts: &ConversionOptions{
+				ScreenshotQuality: 95,
+				PDFQuality:        "high",
+				InMemoryPages:     -1,
+			},
+			wantErr: true,
+		},
+		{
+			name: "Negative turn retries",
+			opts: &ConversionOptions{
+				ScreenshotQuality: 95,
+				PDFQuality:        "high",
+				TurnRetries:       -1,
+			},
+			wantErr: true,
+		},
+		{
+			name: "Negative advance retries",
+			opts: &ConversionOptions{
+				ScreenshotQuality: 95,
+				PDFQuality:        "high",
+				AdvanceRetries:    -1,
+			},
+			wantErr: true,
+		},
+		{
+			name: "Invalid background color",
+			opts: &ConversionOptions{
+				ScreenshotQuality: 95,
+				PDFQuality:        "high",
+				BackgroundColor:   "black",
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -131,3 +537,177 @@ func TestValidate(t *testing.T) {
 		})
 	}
 }
+
+func TestFormatYAML(t *testing.T) {
+	out := FormatYAML(ApplyDefaults(&ConversionOptions{OutputDir: `/tmp/"books"`}))
+
+	for _, want := range []string{
+		"mode: generate\n",
+		"pageDelay: 500ms\n",
+		"screenshotQuality: 100\n",
+		"directionChangeThreshold: 0.9\n",
+		"showCountdown: true\n",
+		`outputDir: /tmp/"books"` + "\n",
+		`inputFile: ""` + "\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output:\n%s", want, out)
+		}
+	}
+
+	// One line per option
+	if got, want := strings.Count(out, "\n"), reflect.TypeOf(ConversionOptions{}).NumField(); got != want {
+		t.Errorf("expected %d lines, got %d", want, got)
+	}
+}
+
+func TestParseConfig(t *testing.T) {
+	t.Run("FormatYAML output loads back", func(t *testing.T) {
+		want := ApplyDefaults(&ConversionOptions{OutputDir: `/tmp/"books"`, PageDelay: 750 * time.Millisecond})
+		got, err := ParseConfig(strings.NewReader(FormatYAML(want)))
+		if err != nil {
+			t.Fatalf("ParseConfig failed: %v", err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("round trip mismatch:\ngot  %+v\nwant %+v", got, want)
+		}
+	})
+
+	t.Run("Comments and a partial file", func(t *testing.T) {
+		got, err := ParseConfig(strings.NewReader("# settings\n\nmode: merge\nscreenshotQuality: 80\n"))
+		if err != nil {
+			t.Fatalf("ParseConfig failed: %v", err)
+		}
+		if got.Mode != "merge" || got.ScreenshotQuality != 80 {
+			t.Errorf("unexpected options: Mode=%q ScreenshotQuality=%d", got.Mode, got.ScreenshotQuality)
+		}
+	})
+
+	t.Run("Empty file", func(t *testing.T) {
+		got, err := ParseConfig(strings.NewReader("# nothing set\n"))
+		if err != nil || !reflect.DeepEqual(got, &ConversionOptions{}) {
+			t.Errorf("expected zero options, got %+v, %v", got, err)
+		}
+	})
+
+	// Field names are not keys: only the yaml tags are
+	for _, in := range []string{"unknown: 1\n", "PageDelay: 1s\n", "pageDelay: soon\n", "screenshotQuality\n"} {
+		if _, err := ParseConfig(strings.NewReader(in)); err == nil || !strings.Contains(err.Error(), "line 1") {
+			t.Errorf("expected a line 1 error for %q, got %v", in, err)
+		}
+	}
+}
+
+func TestMergeOptions(t *testing.T) {
+	file := &ConversionOptions{PageDelay: time.Second, ScreenshotQuality: 80, OutputDir: "/books"}
+	explicit := &ConversionOptions{ScreenshotQuality: 90, Verbose: true}
+
+	got := MergeOptions(explicit, file)
+	if got.ScreenshotQuality != 90 {
+		t.Errorf("expected explicit ScreenshotQuality 90, got %d", got.ScreenshotQuality)
+	}
+	if got.PageDelay != time.Second || got.OutputDir != "/books" {
+		t.Errorf("expected file PageDelay and OutputDir, got %v and %q", got.PageDelay, got.OutputDir)
+	}
+	if !got.Verbose {
+		t.Error("expected explicit Verbose")
+	}
+	if got.Mode != "generate" {
+		t.Errorf("expected default Mode, got %q", got.Mode)
+	}
+	if file.ScreenshotQuality != 80 {
+		t.Error("MergeOptions should not modify its inputs")
+	}
+
+	if got := MergeOptions(nil, nil); !reflect.DeepEqual(got, ApplyDefaults(nil)) {
+		t.Error("expected defaults without explicit or file options")
+	}
+}
+
+func TestParseInputFiles(t *testing.T) {
+	got := ParseInputFiles(" a.pdf, b.pdf ,,\nc.pdf\n")
+	want := []string{"a.pdf", "b.pdf", "c.pdf"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseInputFiles = %q, want %q", got, want)
+	}
+	if got := ParseInputFiles(""); len(got) != 0 {
+		t.Errorf("expected no files for empty input, got %q", got)
+	}
+}
+
+func TestParseDiffRegion(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    image.Rectangle
+		wantErr bool
+	}{
+		{in: "", want: image.Rectangle{}},
+		{in: "0,100,800,1000", want: image.Rect(0, 100, 800, 1100)},
+		{in: " 10, 20 , 30,40 ", want: image.Rect(10, 20, 40, 60)},
+		{in: "1,2,3", wantErr: true},
+		{in: "a,b,c,d", wantErr: true},
+		{in: "0,0,0,100", wantErr: true},
+		{in: "-1,0,100,100", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseDiffRegion(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseDiffRegion(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseDiffRegion(%q) = %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseBackgroundColor(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    color.Color
+		wantErr bool
+	}{
+		{in: "", want: nil},
+		{in: "#000000", want: color.RGBA{A: 255}},
+		{in: " 1a2B3c ", want: color.RGBA{R: 0x1a, G: 0x2b, B: 0x3c, A: 255}},
+		{in: "#fff", wantErr: true},
+		{in: "#gggggg", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseBackgroundColor(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseBackgroundColor(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseBackgroundColor(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestBuiltinPresets(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, p := range BuiltinPresets {
+		if seen[p.Name] {
+			t.Errorf("duplicate preset name %q", p.Name)
+		}
+		seen[p.Name] = true
+
+		if err := ApplyDefaults(&p.Options).Validate(); err != nil {
+			t.Errorf("preset %q is invalid: %v", p.Name, err)
+		}
+		if found, ok := FindBuiltinPreset(p.Name); !ok || found.Name != p.Name {
+			t.Errorf("FindBuiltinPreset(%q) did not find it", p.Name)
+		}
+	}
+
+	if _, ok := FindBuiltinPreset("Unknown"); ok {
+		t.Error("FindBuiltinPreset found an unknown preset")
+	}
+}
+
+func durationPtr(d time.Duration) *time.Duration {
+	return &d
+}
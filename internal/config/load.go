@@ -0,0 +1,63 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LoadConfig reads options from a YAML settings file, e.g. one saved from
+// FormatYAML output
+func LoadConfig(path string) (*ConversionOptions, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open config file: %w", err)
+	}
+	defer file.Close()
+
+	opts, err := ParseConfig(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return opts, nil
+}
+
+// ParseConfig decodes a YAML mapping of option keys (the yaml tags on
+// ConversionOptions, e.g. pageDelay) into options. Durations use Go syntax
+// (e.g. 500ms) and unknown keys are an error. Unset fields stay zero, so
+// defaults still apply; an empty file sets nothing
+func ParseConfig(r io.Reader) (*ConversionOptions, error) {
+	opts := &ConversionOptions{}
+	decoder := yaml.NewDecoder(r)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(opts); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	return opts, nil
+}
+
+// MergeOptions combines explicitly set options, options from a config file
+// and the defaults, in that order of precedence: every non-zero explicit
+// field wins, then every non-zero file field, and ApplyDefaults fills the rest
+// file may be nil. Like ApplyDefaults, a zero value means "not set", so a
+// file cannot be overridden back to false or zero
+func MergeOptions(explicit, file *ConversionOptions) *ConversionOptions {
+	merged := &ConversionOptions{}
+	if file != nil {
+		*merged = *file
+	}
+	if explicit != nil {
+		src := reflect.ValueOf(explicit).Elem()
+		dst := reflect.ValueOf(merged).Elem()
+		for i := 0; i < src.NumField(); i++ {
+			if !src.Field(i).IsZero() {
+				dst.Field(i).Set(src.Field(i))
+			}
+		}
+	}
+	return ApplyDefaults(merged)
+}
@@ -0,0 +1,59 @@
+package config
+
+import "time"
+
+// Preset is a named set of settings for one kind of book
+// Only the quality, delay, trimming and page layout fields are used
+type Preset struct {
+	Name    string
+	Options ConversionOptions
+}
+
+// BuiltinPresets are the presets offered before any are saved
+var BuiltinPresets = []Preset{
+	{
+		// Black-and-white text compresses well; trim the location/progress footer
+		Name: "Text/Novel",
+		Options: ConversionOptions{
+			ScreenshotQuality: 80,
+			PDFQuality:        "medium",
+			PageDelay:         300 * time.Millisecond,
+			TrimTop:           40,
+			TrimBottom:        60,
+			PageSize:          "auto",
+			NUp:               1,
+		},
+	},
+	{
+		// Full-bleed artwork: keep every pixel and give image pages time to render
+		Name: "Manga/Comic",
+		Options: ConversionOptions{
+			ScreenshotQuality: 95,
+			PDFQuality:        "high",
+			PageDelay:         600 * time.Millisecond,
+			PageSize:          "auto",
+			NUp:               1,
+		},
+	},
+	{
+		// Dense color layouts with small print
+		Name: "Magazine",
+		Options: ConversionOptions{
+			ScreenshotQuality: 100,
+			PDFQuality:        "high",
+			PageDelay:         800 * time.Millisecond,
+			PageSize:          "auto",
+			NUp:               1,
+		},
+	},
+}
+
+// FindBuiltinPreset returns the built-in preset with the given name
+func FindBuiltinPreset(name string) (Preset, bool) {
+	for _, p := range BuiltinPresets {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return Preset{}, false
+}
@@ -0,0 +1,14 @@
+package config
+
+import (
+	"gopkg.in/yaml.v3"
+)
+
+// FormatYAML renders every option as a YAML "key: value" line, in
+// declaration order, to show the settings actually in effect
+// Keys are the yaml tags ParseConfig reads, so the output loads back as is
+func FormatYAML(opts *ConversionOptions) string {
+	// ConversionOptions holds only scalars, which always marshal
+	out, _ := yaml.Marshal(opts)
+	return string(out)
+}
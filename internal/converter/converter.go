@@ -5,6 +5,9 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/ledongthuc/pdf"
 )
@@ -12,9 +15,50 @@ import (
 // MarkdownConverter converts PDF files to Markdown
 type MarkdownConverter interface {
 	// ConvertPDFToMarkdown extracts text from PDF and saves as Markdown
-	ConvertPDFToMarkdown(ctx context.Context, inputPDF string, outputMarkdown string) error
+	ConvertPDFToMarkdown(ctx context.Context, inputPDF string, outputMarkdown string, options MarkdownOptions) (*MarkdownResult, error)
 }
 
+// MarkdownOptions controls how much structure is recovered from the PDF
+type MarkdownOptions struct {
+	// Promote lines set noticeably larger than the body text to # and ##
+	// headings
+	Headings bool
+
+	// Directory to extract embedded images to; they are referenced from the
+	// Markdown after the text of their page. Empty disables extraction
+	ImageDir string
+}
+
+// MarkdownResult summarizes a PDF to Markdown conversion
+type MarkdownResult struct {
+	// Pages in the input PDF
+	Pages int
+
+	// Pages that had extractable text
+	TextPages int
+
+	// Characters of text extracted (excluding whitespace)
+	Characters int
+
+	// Lines promoted to headings (MarkdownOptions.Headings)
+	Headings int
+
+	// Images extracted to MarkdownOptions.ImageDir
+	Images int
+
+	// Too little text for the page count (below minCharactersPerPage on
+	// average): the PDF is image-only, e.g. a scan or a k2p output, and the
+	// Markdown file is empty or nearly so
+	NeedsOCR bool
+
+	// Problems worth showing to the user, such as NeedsOCR
+	Warnings []string
+}
+
+// minCharactersPerPage is the average text per page below which a PDF counts
+// as image-only; scans often still carry a page number or a stray glyph
+const minCharactersPerPage = 20
+
 // DefaultConverter is the default implementation using a pure Go library
 type DefaultConverter struct{}
 
@@ -24,43 +68,103 @@ func NewConverter() MarkdownConverter {
 }
 
 // ConvertPDFToMarkdown implements MarkdownConverter
-func (c *DefaultConverter) ConvertPDFToMarkdown(ctx context.Context, inputPDF string, outputMarkdown string) error {
+func (c *DefaultConverter) ConvertPDFToMarkdown(ctx context.Context, inputPDF string, outputMarkdown string, options MarkdownOptions) (*MarkdownResult, error) {
 	// 1. Validate input file
 	if _, err := os.Stat(inputPDF); err != nil {
-		return fmt.Errorf("input file not found: %s", inputPDF)
+		return nil, fmt.Errorf("input file not found: %s", inputPDF)
 	}
 
 	// 2. Extract text using ledongthuc/pdf
 	f, r, err := pdf.Open(inputPDF)
 	if err != nil {
-		return fmt.Errorf("failed to open PDF: %w", err)
+		return nil, fmt.Errorf("failed to open PDF: %w", err)
 	}
 	defer f.Close()
 
-	var buf bytes.Buffer
 	totalPage := r.NumPage()
+	result := &MarkdownResult{Pages: totalPage}
 
+	// Text of each page, as plain text or as lines with font sizes
+	texts := make([]string, totalPage+1)
+	lines := make([][]textLine, totalPage+1)
 	for pageIndex := 1; pageIndex <= totalPage; pageIndex++ {
 		p := r.Page(pageIndex)
 		if p.V.IsNull() {
 			continue
 		}
 
+		if options.Headings {
+			if pageText, err := pageLines(p); err == nil {
+				lines[pageIndex] = pageText
+				for _, line := range pageText {
+					texts[pageIndex] += line.text + "\n"
+				}
+				continue
+			}
+		}
+
 		text, err := p.GetPlainText(nil)
 		if err != nil {
 			// If we fail to get text for a page, strict error might be too harsh,
 			// but let's log/buffer it. For now, we continue.
 			continue
 		}
+		texts[pageIndex] = text
+	}
+
+	// 3. Extract images next to the Markdown file
+	var images map[int][]string
+	if options.ImageDir != "" {
+		images, err = extractImages(inputPDF, options.ImageDir)
+		if err != nil {
+			result.Warnings = append(result.Warnings, err.Error())
+		}
+	}
+
+	var buf bytes.Buffer
+	body := bodyFontSize(lines)
+	for pageIndex := 1; pageIndex <= totalPage; pageIndex++ {
+		text := texts[pageIndex]
+		if chars := utf8.RuneCountInString(strings.Join(strings.Fields(text), "")); chars > 0 {
+			result.TextPages++
+			result.Characters += chars
+		}
 
-		buf.WriteString(text)
-		buf.WriteString("\n\n") // Separation between pages
+		if lines[pageIndex] != nil {
+			for _, line := range lines[pageIndex] {
+				if prefix := headingPrefix(line, body); prefix != "" {
+					buf.WriteString("\n" + prefix + line.text + "\n\n")
+					result.Headings++
+					continue
+				}
+				buf.WriteString(line.text + "\n")
+			}
+		} else {
+			buf.WriteString(text)
+		}
+		for _, image := range images[pageIndex] {
+			ref := image
+			if rel, err := filepath.Rel(filepath.Dir(outputMarkdown), image); err == nil {
+				ref = filepath.ToSlash(rel)
+			}
+			fmt.Fprintf(&buf, "\n![](%s)\n", ref)
+			result.Images++
+		}
+		if text != "" || len(images[pageIndex]) > 0 {
+			buf.WriteString("\n\n") // Separation between pages
+		}
+	}
+	if result.Pages > 0 && result.Characters < minCharactersPerPage*result.Pages {
+		result.NeedsOCR = true
+		result.Warnings = append(result.Warnings, fmt.Sprintf(
+			"only %d characters of text in %d pages: this PDF appears to be image-only (scanned) and needs OCR. "+
+				"k2p recognizes page text when converting a book with Format: epub", result.Characters, result.Pages))
 	}
 
-	// 3. Write to output file
+	// 4. Write to output file
 	if err := os.WriteFile(outputMarkdown, buf.Bytes(), 0644); err != nil {
-		return fmt.Errorf("failed to write Markdown file: %w", err)
+		return nil, fmt.Errorf("failed to write Markdown file: %w", err)
 	}
 
-	return nil
+	return result, nil
 }
@@ -0,0 +1,141 @@
+package converter
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+func TestConvertPDFToMarkdown(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	textPDF := func(name string, pages ...string) string {
+		path := filepath.Join(tmpDir, name)
+		doc := gofpdf.New("P", "mm", "A5", "")
+		doc.SetFont("Helvetica", "", 12)
+		for _, text := range pages {
+			doc.AddPage()
+			doc.Cell(40, 10, text)
+		}
+		if err := doc.OutputFileAndClose(path); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	// Two pages with text and one without
+	line := "The quick brown fox jumps over the lazy dog"
+	result, err := NewConverter().ConvertPDFToMarkdown(context.Background(),
+		textPDF("text.pdf", line, "", line), filepath.Join(tmpDir, "text.md"), MarkdownOptions{})
+	if err != nil {
+		t.Fatalf("conversion failed: %v", err)
+	}
+	if result.Pages != 3 || result.TextPages != 2 || result.Characters != 70 || result.NeedsOCR || len(result.Warnings) != 0 {
+		t.Errorf("unexpected result for a text PDF: %+v", result)
+	}
+
+	// A scan with only page numbers is still image-only
+	result, err = NewConverter().ConvertPDFToMarkdown(context.Background(),
+		textPDF("numbers.pdf", "1", "2", "3"), filepath.Join(tmpDir, "numbers.md"), MarkdownOptions{})
+	if err != nil {
+		t.Fatalf("conversion failed: %v", err)
+	}
+	if !result.NeedsOCR || len(result.Warnings) != 1 {
+		t.Errorf("expected a near-empty PDF to need OCR, got %+v", result)
+	}
+
+	// A page that is only an image, like k2p's own output
+	imagePath := filepath.Join(tmpDir, "page.png")
+	f, err := os.Create(imagePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img := image.NewGray(image.Rect(0, 0, 20, 20))
+	img.Set(10, 10, color.White)
+	if err := png.Encode(f, img); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	imagePDF := filepath.Join(tmpDir, "image.pdf")
+	doc := gofpdf.New("P", "mm", "A5", "")
+	doc.AddPage()
+	doc.Image(imagePath, 10, 10, 50, 0, false, "", 0, "")
+	if err := doc.OutputFileAndClose(imagePDF); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err = NewConverter().ConvertPDFToMarkdown(context.Background(), imagePDF, filepath.Join(tmpDir, "image.md"), MarkdownOptions{})
+	if err != nil {
+		t.Fatalf("conversion failed: %v", err)
+	}
+	if result.Pages != 1 || result.TextPages != 0 || !result.NeedsOCR {
+		t.Errorf("expected an image-only PDF to need OCR, got %+v", result)
+	}
+}
+
+func TestConvertPDFToMarkdownStructure(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	imagePath := filepath.Join(tmpDir, "figure.png")
+	f, err := os.Create(imagePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := png.Encode(f, image.NewGray(image.Rect(0, 0, 20, 20))); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	// A title, a section heading, body text and a figure
+	inputPDF := filepath.Join(tmpDir, "book.pdf")
+	doc := gofpdf.New("P", "mm", "A5", "")
+	doc.AddPage()
+	doc.SetFont("Helvetica", "", 24)
+	doc.Text(10, 20, "Chapter One")
+	doc.SetFont("Helvetica", "", 16)
+	doc.Text(10, 35, "A Section")
+	doc.SetFont("Helvetica", "", 11)
+	doc.Text(10, 45, "The quick brown fox jumps over the lazy dog.")
+	doc.Text(10, 52, "Pack my box with five dozen liquor jugs.")
+	doc.Image(imagePath, 10, 60, 30, 0, false, "", 0, "")
+	if err := doc.OutputFileAndClose(inputPDF); err != nil {
+		t.Fatal(err)
+	}
+
+	outputPath := filepath.Join(tmpDir, "book.md")
+	result, err := NewConverter().ConvertPDFToMarkdown(context.Background(), inputPDF, outputPath,
+		MarkdownOptions{Headings: true, ImageDir: filepath.Join(tmpDir, "images")})
+	if err != nil {
+		t.Fatalf("conversion failed: %v", err)
+	}
+	if result.Headings != 2 || result.Images != 1 || len(result.Warnings) != 0 {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	data, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	markdown := string(data)
+	for _, want := range []string{
+		"# Chapter One\n",
+		"## A Section\n",
+		"\nThe quick brown fox jumps over the lazy dog.\n",
+		"![](images/page_001_1.png)",
+	} {
+		if !strings.Contains(markdown, want) {
+			t.Errorf("expected %q in the Markdown, got:\n%s", want, markdown)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "images", "page_001_1.png")); err != nil {
+		t.Errorf("expected the extracted image: %v", err)
+	}
+}
@@ -0,0 +1,113 @@
+package converter
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ledongthuc/pdf"
+)
+
+const (
+	// headingMaxRunes is the longest line that may become a heading; longer
+	// lines are large-print body text rather than titles
+	headingMaxRunes = 120
+
+	// h1Ratio and h2Ratio are how much larger than the body font a line must
+	// be to become a level 1 or level 2 heading
+	h1Ratio = 1.6
+	h2Ratio = 1.25
+
+	// wordGapRatio is the horizontal gap (relative to the font size) between
+	// two glyphs above which a space is inserted
+	wordGapRatio = 0.25
+)
+
+// textLine is a line of text on a page with its largest font size
+type textLine struct {
+	text     string
+	fontSize float64
+}
+
+// pageLines groups the glyphs of a page into lines in content order
+// ledongthuc/pdf panics on some malformed content streams; that page then
+// yields no lines and the caller falls back to plain text
+func pageLines(p pdf.Page) (lines []textLine, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			lines, err = nil, fmt.Errorf("failed to read page content: %v", r)
+		}
+	}()
+
+	var current strings.Builder
+	var size, lineY, endX float64
+	flush := func() {
+		if text := strings.TrimSpace(current.String()); text != "" {
+			lines = append(lines, textLine{text: text, fontSize: size})
+		}
+		current.Reset()
+		size = 0
+	}
+
+	for _, glyph := range p.Content().Text {
+		if glyph.S == "\n" {
+			flush()
+			continue
+		}
+		if current.Len() > 0 {
+			if math.Abs(glyph.Y-lineY) > glyph.FontSize/2 {
+				flush()
+			} else if glyph.X-endX > glyph.FontSize*wordGapRatio {
+				current.WriteByte(' ')
+			}
+		}
+		if current.Len() == 0 {
+			lineY = glyph.Y
+		}
+		current.WriteString(glyph.S)
+		if glyph.FontSize > size && strings.TrimSpace(glyph.S) != "" {
+			size = glyph.FontSize
+		}
+		endX = glyph.X + glyph.W
+	}
+	flush()
+	return lines, nil
+}
+
+// bodyFontSize returns the font size most of the text is set in
+func bodyFontSize(pages [][]textLine) float64 {
+	runes := make(map[float64]int)
+	for _, lines := range pages {
+		for _, line := range lines {
+			runes[math.Round(line.fontSize*10)/10] += utf8.RuneCountInString(line.text)
+		}
+	}
+	var body float64
+	for size, n := range runes {
+		if n > runes[body] || (n == runes[body] && size < body) {
+			body = size
+		}
+	}
+	return body
+}
+
+// headingPrefix returns the Markdown heading marker for a line, or "" for
+// body text
+func headingPrefix(line textLine, body float64) string {
+	if body <= 0 || utf8.RuneCountInString(line.text) > headingMaxRunes {
+		return ""
+	}
+	// Page numbers and ornaments are never headings
+	if strings.IndexFunc(line.text, unicode.IsLetter) < 0 {
+		return ""
+	}
+	switch {
+	case line.fontSize >= body*h1Ratio:
+		return "# "
+	case line.fontSize >= body*h2Ratio:
+		return "## "
+	}
+	return ""
+}
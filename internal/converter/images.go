@@ -0,0 +1,52 @@
+package converter
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+)
+
+// pdfcpu otherwise creates a configuration directory in the user's config dir
+func init() {
+	api.DisableConfigDir()
+}
+
+// extractImages writes the embedded images of a PDF to dir as
+// page_NNN_M.<ext> and returns their paths by page number
+// ledongthuc/pdf cannot decode image XObjects, so pdfcpu reads them instead
+func extractImages(inputPDF, dir string) (map[int][]string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create image directory: %w", err)
+	}
+	f, err := os.Open(inputPDF)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	images := make(map[int][]string)
+	digest := func(img model.Image, _ bool, _ int) error {
+		if img.Thumb {
+			return nil
+		}
+		path := filepath.Join(dir, fmt.Sprintf("page_%03d_%d.%s", img.PageNr, len(images[img.PageNr])+1, img.FileType))
+		out, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		defer out.Close()
+		if _, err := io.Copy(out, img); err != nil {
+			return err
+		}
+		images[img.PageNr] = append(images[img.PageNr], path)
+		return nil
+	}
+	if err := api.ExtractImages(f, nil, digest, model.NewDefaultConfiguration()); err != nil {
+		return images, fmt.Errorf("failed to extract images: %w", err)
+	}
+	return images, nil
+}
@@ -0,0 +1,304 @@
+package epub
+
+import (
+	"archive/zip"
+	"crypto/rand"
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// EPUBGenerator handles EPUB generation from recognized page text
+type EPUBGenerator interface {
+	// CreateEPUB creates an EPUB from a sequence of pages
+	CreateEPUB(pages []Page, outputPath string, options EPUBOptions) error
+}
+
+// Page is a single captured book page
+type Page struct {
+	// Text recognized on the page (lines separated by newlines)
+	Text string
+
+	// Path to the page image (embedded only if EPUBOptions.EmbedImages is set)
+	ImagePath string
+}
+
+// EPUBOptions contains options for EPUB generation
+type EPUBOptions struct {
+	// Book title written to the package metadata
+	Title string
+
+	// Book language as a BCP 47 code (default: "ja")
+	Language string
+
+	// Number of pages grouped into one chapter (default: 10)
+	PagesPerChapter int
+
+	// Embed each page image above its recognized text
+	EmbedImages bool
+}
+
+// DefaultEPUBGenerator is the default implementation writing EPUB 3 archives
+type DefaultEPUBGenerator struct{}
+
+// NewEPUBGenerator creates a new EPUBGenerator instance
+func NewEPUBGenerator() EPUBGenerator {
+	return &DefaultEPUBGenerator{}
+}
+
+// chapter groups consecutive pages into one XHTML document
+type chapter struct {
+	FileName  string
+	Title     string
+	FirstPage int
+	Pages     []Page
+}
+
+// CreateEPUB creates an EPUB from a sequence of pages
+func (g *DefaultEPUBGenerator) CreateEPUB(pages []Page, outputPath string, options EPUBOptions) error {
+	if len(pages) == 0 {
+		return fmt.Errorf("no pages provided")
+	}
+
+	if options.Title == "" {
+		options.Title = strings.TrimSuffix(filepath.Base(outputPath), filepath.Ext(outputPath))
+	}
+	if options.Language == "" {
+		options.Language = "ja"
+	}
+	if options.PagesPerChapter <= 0 {
+		options.PagesPerChapter = 10
+	}
+
+	// Validate embedded images exist before writing anything
+	if options.EmbedImages {
+		for _, p := range pages {
+			if _, err := os.Stat(p.ImagePath); err != nil {
+				return fmt.Errorf("image file not found: %s", p.ImagePath)
+			}
+		}
+	}
+
+	chapters := splitChapters(pages, options.PagesPerChapter)
+
+	out, err := os.Create(outputPath)
+	if err != nil {
+		return fmt.Errorf("failed to create EPUB: %w", err)
+	}
+	defer out.Close()
+
+	zw := zip.NewWriter(out)
+
+	// The mimetype entry must come first and be stored uncompressed
+	mw, err := zw.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store})
+	if err != nil {
+		return fmt.Errorf("failed to write mimetype: %w", err)
+	}
+	if _, err := io.WriteString(mw, "application/epub+zip"); err != nil {
+		return fmt.Errorf("failed to write mimetype: %w", err)
+	}
+
+	if err := writeEntry(zw, "META-INF/container.xml", containerXML); err != nil {
+		return err
+	}
+	if err := writeEntry(zw, "OEBPS/content.opf", buildPackage(chapters, options)); err != nil {
+		return err
+	}
+	if err := writeEntry(zw, "OEBPS/nav.xhtml", buildNav(chapters, options)); err != nil {
+		return err
+	}
+
+	for _, ch := range chapters {
+		if err := writeEntry(zw, "OEBPS/"+ch.FileName, buildChapter(ch, options)); err != nil {
+			return err
+		}
+	}
+
+	if options.EmbedImages {
+		for i, p := range pages {
+			if err := copyEntry(zw, "OEBPS/"+imageName(i+1, p.ImagePath), p.ImagePath); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to finalize EPUB: %w", err)
+	}
+
+	return nil
+}
+
+// splitChapters groups pages into chapters of at most perChapter pages
+func splitChapters(pages []Page, perChapter int) []chapter {
+	var chapters []chapter
+	for start := 0; start < len(pages); start += perChapter {
+		end := start + perChapter
+		if end > len(pages) {
+			end = len(pages)
+		}
+		n := len(chapters) + 1
+		chapters = append(chapters, chapter{
+			FileName:  fmt.Sprintf("chapter_%03d.xhtml", n),
+			Title:     fmt.Sprintf("Pages %d-%d", start+1, end),
+			FirstPage: start + 1,
+			Pages:     pages[start:end],
+		})
+	}
+	return chapters
+}
+
+// imageName returns the archive path (relative to OEBPS) of a page image
+func imageName(pageNum int, imagePath string) string {
+	return fmt.Sprintf("images/page_%04d%s", pageNum, strings.ToLower(filepath.Ext(imagePath)))
+}
+
+// imageMediaType returns the media type for an image path
+func imageMediaType(imagePath string) string {
+	switch strings.ToLower(filepath.Ext(imagePath)) {
+	case ".jpg", ".jpeg":
+		return "image/jpeg"
+	default:
+		return "image/png"
+	}
+}
+
+const containerXML = `<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>
+`
+
+// buildPackage renders content.opf (metadata, manifest and spine)
+func buildPackage(chapters []chapter, options EPUBOptions) string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	b.WriteString(`<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="book-id">` + "\n")
+	b.WriteString(`  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">` + "\n")
+	fmt.Fprintf(&b, "    <dc:identifier id=\"book-id\">urn:uuid:%s</dc:identifier>\n", newUUID())
+	fmt.Fprintf(&b, "    <dc:title>%s</dc:title>\n", html.EscapeString(options.Title))
+	fmt.Fprintf(&b, "    <dc:language>%s</dc:language>\n", html.EscapeString(options.Language))
+	fmt.Fprintf(&b, "    <meta property=\"dcterms:modified\">%s</meta>\n", time.Now().UTC().Format("2006-01-02T15:04:05Z"))
+	b.WriteString("  </metadata>\n")
+
+	b.WriteString("  <manifest>\n")
+	b.WriteString(`    <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>` + "\n")
+	for i, ch := range chapters {
+		fmt.Fprintf(&b, "    <item id=\"chapter-%d\" href=\"%s\" media-type=\"application/xhtml+xml\"/>\n", i+1, ch.FileName)
+	}
+	if options.EmbedImages {
+		for _, ch := range chapters {
+			for j, p := range ch.Pages {
+				pageNum := ch.FirstPage + j
+				fmt.Fprintf(&b, "    <item id=\"page-%d\" href=\"%s\" media-type=\"%s\"/>\n",
+					pageNum, imageName(pageNum, p.ImagePath), imageMediaType(p.ImagePath))
+			}
+		}
+	}
+	b.WriteString("  </manifest>\n")
+
+	b.WriteString("  <spine>\n")
+	for i := range chapters {
+		fmt.Fprintf(&b, "    <itemref idref=\"chapter-%d\"/>\n", i+1)
+	}
+	b.WriteString("  </spine>\n")
+	b.WriteString("</package>\n")
+	return b.String()
+}
+
+// buildNav renders the EPUB 3 navigation document
+func buildNav(chapters []chapter, options EPUBOptions) string {
+	var b strings.Builder
+	writeXHTMLHeader(&b, options.Title, options.Language, ` xmlns:epub="http://www.idpf.org/2007/ops"`)
+	b.WriteString("  <nav epub:type=\"toc\" id=\"toc\">\n")
+	fmt.Fprintf(&b, "    <h1>%s</h1>\n", html.EscapeString(options.Title))
+	b.WriteString("    <ol>\n")
+	for _, ch := range chapters {
+		fmt.Fprintf(&b, "      <li><a href=\"%s\">%s</a></li>\n", ch.FileName, html.EscapeString(ch.Title))
+	}
+	b.WriteString("    </ol>\n")
+	b.WriteString("  </nav>\n")
+	b.WriteString("</body>\n</html>\n")
+	return b.String()
+}
+
+// buildChapter renders one chapter with a section per page
+func buildChapter(ch chapter, options EPUBOptions) string {
+	var b strings.Builder
+	writeXHTMLHeader(&b, ch.Title, options.Language, "")
+	fmt.Fprintf(&b, "  <h1>%s</h1>\n", html.EscapeString(ch.Title))
+	for j, p := range ch.Pages {
+		pageNum := ch.FirstPage + j
+		fmt.Fprintf(&b, "  <section id=\"page-%d\">\n", pageNum)
+		if options.EmbedImages {
+			fmt.Fprintf(&b, "    <img src=\"%s\" alt=\"Page %d\"/>\n", imageName(pageNum, p.ImagePath), pageNum)
+		}
+		for _, line := range strings.Split(p.Text, "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" {
+				continue
+			}
+			fmt.Fprintf(&b, "    <p>%s</p>\n", html.EscapeString(line))
+		}
+		b.WriteString("  </section>\n")
+	}
+	b.WriteString("</body>\n</html>\n")
+	return b.String()
+}
+
+// writeXHTMLHeader writes the common XHTML prologue up to the opening body tag
+func writeXHTMLHeader(b *strings.Builder, title, language, extraNS string) {
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	b.WriteString("<!DOCTYPE html>\n")
+	fmt.Fprintf(b, "<html xmlns=\"http://www.w3.org/1999/xhtml\"%s xml:lang=\"%s\" lang=\"%s\">\n",
+		extraNS, html.EscapeString(language), html.EscapeString(language))
+	fmt.Fprintf(b, "<head>\n  <title>%s</title>\n</head>\n<body>\n", html.EscapeString(title))
+}
+
+// writeEntry writes a text file into the archive
+func writeEntry(zw *zip.Writer, name, content string) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	if _, err := io.WriteString(w, content); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
+
+// copyEntry copies a file from disk into the archive
+func copyEntry(zw *zip.Writer, name, srcPath string) error {
+	src, err := os.Open(srcPath)
+	if err != nil {
+		return fmt.Errorf("failed to open image %s: %w", srcPath, err)
+	}
+	defer src.Close()
+
+	w, err := zw.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	if _, err := io.Copy(w, src); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
+
+// newUUID returns a random (version 4) UUID string
+func newUUID() string {
+	var u [16]byte
+	if _, err := rand.Read(u[:]); err != nil {
+		// Fall back to a time-based identifier; uniqueness is best effort
+		return fmt.Sprintf("00000000-0000-4000-8000-%012x", time.Now().UnixNano()&0xffffffffffff)
+	}
+	u[6] = (u[6] & 0x0f) | 0x40
+	u[8] = (u[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:16])
+}
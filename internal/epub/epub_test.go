@@ -0,0 +1,122 @@
+package epub
+
+import (
+	"archive/zip"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCreateEPUB(t *testing.T) {
+	generator := NewEPUBGenerator()
+
+	t.Run("no pages", func(t *testing.T) {
+		err := generator.CreateEPUB([]Page{}, "output.epub", EPUBOptions{})
+		if err == nil {
+			t.Error("expected error for empty page list")
+		}
+	})
+
+	t.Run("missing image when embedding", func(t *testing.T) {
+		pages := []Page{{Text: "hello", ImagePath: "/nonexistent/page.png"}}
+		err := generator.CreateEPUB(pages, filepath.Join(t.TempDir(), "out.epub"), EPUBOptions{EmbedImages: true})
+		if err == nil {
+			t.Error("expected error for non-existent image")
+		}
+	})
+
+	t.Run("chapters and package documents", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		imgPath := filepath.Join(tmpDir, "page.png")
+		writeTestPNG(t, imgPath)
+
+		pages := []Page{
+			{Text: "first line\nsecond <line>", ImagePath: imgPath},
+			{Text: "page two", ImagePath: imgPath},
+			{Text: "page three", ImagePath: imgPath},
+		}
+		outputPath := filepath.Join(tmpDir, "book.epub")
+		opts := EPUBOptions{Title: "Test & Book", PagesPerChapter: 2, EmbedImages: true}
+
+		if err := generator.CreateEPUB(pages, outputPath, opts); err != nil {
+			t.Fatalf("CreateEPUB failed: %v", err)
+		}
+
+		zr, err := zip.OpenReader(outputPath)
+		if err != nil {
+			t.Fatalf("output is not a valid zip: %v", err)
+		}
+		defer zr.Close()
+
+		// mimetype must be the first, uncompressed entry
+		first := zr.File[0]
+		if first.Name != "mimetype" || first.Method != zip.Store {
+			t.Errorf("expected stored mimetype as first entry, got %s (method %d)", first.Name, first.Method)
+		}
+		if got := readEntry(t, zr, "mimetype"); got != "application/epub+zip" {
+			t.Errorf("unexpected mimetype content %q", got)
+		}
+
+		opf := readEntry(t, zr, "OEBPS/content.opf")
+		if !strings.Contains(opf, "<dc:title>Test &amp; Book</dc:title>") {
+			t.Errorf("content.opf missing escaped title:\n%s", opf)
+		}
+		if !strings.Contains(opf, `href="images/page_0003.png"`) {
+			t.Errorf("content.opf missing embedded image item:\n%s", opf)
+		}
+
+		nav := readEntry(t, zr, "OEBPS/nav.xhtml")
+		if !strings.Contains(nav, "chapter_001.xhtml") || !strings.Contains(nav, "chapter_002.xhtml") {
+			t.Errorf("nav.xhtml should list two chapters:\n%s", nav)
+		}
+
+		ch1 := readEntry(t, zr, "OEBPS/chapter_001.xhtml")
+		if !strings.Contains(ch1, "<p>second &lt;line&gt;</p>") {
+			t.Errorf("chapter text not escaped:\n%s", ch1)
+		}
+		if strings.Contains(ch1, "page three") {
+			t.Error("chapter 1 should only contain the first two pages")
+		}
+
+		if _, err := zr.Open("OEBPS/images/page_0001.png"); err != nil {
+			t.Errorf("embedded image missing: %v", err)
+		}
+	})
+}
+
+func readEntry(t *testing.T, zr *zip.ReadCloser, name string) string {
+	t.Helper()
+	f, err := zr.Open(name)
+	if err != nil {
+		t.Fatalf("missing entry %s: %v", name, err)
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", name, err)
+	}
+	return string(data)
+}
+
+func writeTestPNG(t *testing.T, path string) {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, 10, 10))
+	for y := 0; y < 10; y++ {
+		for x := 0; x < 10; x++ {
+			img.Set(x, y, color.White)
+		}
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("failed to create test image: %v", err)
+	}
+	defer f.Close()
+	if err := png.Encode(f, img); err != nil {
+		t.Fatalf("failed to encode test image: %v", err)
+	}
+}
@@ -8,6 +8,15 @@ import (
 	"strings"
 	"syscall"
 	"time"
+	"unicode/utf8"
+
+	"github.com/oumi/k2p/internal/hint"
+)
+
+// ErrInsufficientDiskSpace is returned (wrapped with sizes) by CheckDiskSpace
+var ErrInsufficientDiskSpace = hint.New(
+	"insufficient disk space",
+	"Free up disk space or choose another output directory",
 )
 
 // FileManager handles all file system operations
@@ -140,8 +149,8 @@ func (fm *DefaultFileManager) CheckDiskSpace(path string, estimatedBytes int64)
 	availableBytes := stat.Bavail * uint64(stat.Bsize)
 
 	if uint64(estimatedBytes) > availableBytes {
-		return fmt.Errorf("insufficient disk space: need %d MB, only %d MB available",
-			estimatedBytes/(1024*1024), availableBytes/(1024*1024))
+		return fmt.Errorf("%w: need %d MB, only %d MB available",
+			ErrInsufficientDiskSpace, estimatedBytes/(1024*1024), availableBytes/(1024*1024))
 	}
 
 	return nil
@@ -192,6 +201,31 @@ func (fm *DefaultFileManager) ResolveOutputPath(outputDir string) (string, error
 	return outputPath, nil
 }
 
+// SanitizeFileName turns an arbitrary string (e.g. a book title) into a safe file name
+// Path separators and characters reserved on macOS/Windows are replaced with "_"
+func SanitizeFileName(name string) string {
+	replacer := strings.NewReplacer(
+		"/", "_", "\\", "_", ":", "_", "*", "_", "?", "_",
+		"\"", "_", "<", "_", ">", "_", "|", "_", "\n", " ", "\r", " ", "\t", " ",
+	)
+	name = strings.TrimSpace(replacer.Replace(name))
+	// Avoid hidden files and relative path components
+	name = strings.TrimLeft(name, ".")
+	if name == "" {
+		return "untitled"
+	}
+	// Keep well under the 255 byte file name limit (leave room for extension)
+	const maxLen = 200
+	if len(name) > maxLen {
+		cut := maxLen
+		for cut > 0 && !utf8.RuneStart(name[cut]) {
+			cut--
+		}
+		name = name[:cut]
+	}
+	return name
+}
+
 // generateTimestamp generates a timestamp string for filenames
 func generateTimestamp() string {
 	return time.Now().Format("20060102-150405")
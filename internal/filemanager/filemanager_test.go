@@ -7,6 +7,7 @@ import (
 	"strings"
 	"testing"
 	"time"
+	"unicode/utf8"
 )
 
 func TestValidateOutputPath(t *testing.T) {
@@ -179,6 +180,30 @@ func TestResolveOutputPath(t *testing.T) {
 	})
 }
 
+func TestSanitizeFileName(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"My Book", "My Book"},
+		{"Vol. 1/2: The Beginning", "Vol. 1_2_ The Beginning"},
+		{"../secret", "_secret"},
+		{"   ", "untitled"},
+		{"吾輩は猫である", "吾輩は猫である"},
+	}
+
+	for _, tt := range tests {
+		if got := SanitizeFileName(tt.input); got != tt.want {
+			t.Errorf("SanitizeFileName(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+
+	long := strings.Repeat("あ", 100) // 300 bytes
+	if got := SanitizeFileName(long); len(got) > 200 || !utf8.ValidString(got) {
+		t.Errorf("long name not truncated on a rune boundary: %d bytes", len(got))
+	}
+}
+
 func TestHandleExistingFile(t *testing.T) {
 	fm := NewFileManager()
 
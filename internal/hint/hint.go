@@ -0,0 +1,30 @@
+package hint
+
+import "errors"
+
+// Error is a failure that carries a remediation hint for users
+// Declare them as sentinel errors, wrap them with context and match with errors.Is
+type Error struct {
+	msg  string
+	hint string
+}
+
+// New creates an error with a remediation hint
+func New(msg, hint string) error {
+	return &Error{msg: msg, hint: hint}
+}
+
+func (e *Error) Error() string { return e.msg }
+
+// Hint returns what the user should do to fix the problem
+func (e *Error) Hint() string { return e.hint }
+
+// Of returns the hint carried by err or any error it wraps
+// Returns "" when the error has no known remedy
+func Of(err error) string {
+	var h *Error
+	if errors.As(err, &h) {
+		return h.hint
+	}
+	return ""
+}
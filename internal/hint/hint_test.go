@@ -0,0 +1,25 @@
+package hint
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestOf(t *testing.T) {
+	errFull := New("disk is full", "Free up disk space")
+	wrapped := fmt.Errorf("failed to write page 3: %w", errFull)
+
+	if got := Of(wrapped); got != "Free up disk space" {
+		t.Errorf("expected the wrapped hint, got %q", got)
+	}
+	if !errors.Is(wrapped, errFull) || wrapped.Error() != "failed to write page 3: disk is full" {
+		t.Errorf("expected the sentinel to match and keep its message, got %v", wrapped)
+	}
+	if got := Of(errors.New("something else")); got != "" {
+		t.Errorf("expected no hint, got %q", got)
+	}
+	if got := Of(nil); got != "" {
+		t.Errorf("expected no hint for nil, got %q", got)
+	}
+}
@@ -0,0 +1,62 @@
+package imageprocessing
+
+import (
+	"fmt"
+	"image"
+	"os"
+)
+
+// uniformTolerance is the per-channel difference (8-bit) still treated as the same color
+const uniformTolerance = 30
+
+// BlankInfo describes whether an image is a single uniform color
+type BlankInfo struct {
+	// Every sampled pixel is within tolerance of the first one
+	Uniform bool
+
+	// The image is uniform and black-ish (all channels <= blackThreshold)
+	Black bool
+}
+
+// DetectBlank checks whether an image is a single uniform color
+// Samples every 10th pixel like CompareImages
+func DetectBlank(img image.Image) BlankInfo {
+	bounds := img.Bounds()
+	if bounds.Empty() {
+		return BlankInfo{}
+	}
+
+	r0, g0, b0, _ := img.At(bounds.Min.X, bounds.Min.Y).RGBA()
+	r0, g0, b0 = r0>>8, g0>>8, b0>>8
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y += 10 {
+		for x := bounds.Min.X; x < bounds.Max.X; x += 10 {
+			r, g, b, _ := img.At(x, y).RGBA()
+			r, g, b = r>>8, g>>8, b>>8
+			if absUint32(r, r0) > uniformTolerance ||
+				absUint32(g, g0) > uniformTolerance ||
+				absUint32(b, b0) > uniformTolerance {
+				return BlankInfo{}
+			}
+		}
+	}
+
+	black := r0 <= blackThreshold && g0 <= blackThreshold && b0 <= blackThreshold
+	return BlankInfo{Uniform: true, Black: black}
+}
+
+// DetectBlankFile checks whether an image file is a single uniform color
+func DetectBlankFile(imagePath string) (BlankInfo, error) {
+	file, err := os.Open(imagePath)
+	if err != nil {
+		return BlankInfo{}, fmt.Errorf("failed to open image file: %w", err)
+	}
+	defer file.Close()
+
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return BlankInfo{}, fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	return DetectBlank(img), nil
+}
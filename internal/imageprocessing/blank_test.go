@@ -0,0 +1,38 @@
+package imageprocessing
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestDetectBlank(t *testing.T) {
+	tests := []struct {
+		name string
+		info BlankInfo
+		want BlankInfo
+	}{
+		{
+			name: "all black",
+			info: DetectBlank(createTestImageWithBorder(100, 100, 0, color.Black, color.Black)),
+			want: BlankInfo{Uniform: true, Black: true},
+		},
+		{
+			name: "all white",
+			info: DetectBlank(createTestImageWithBorder(100, 100, 0, color.White, color.White)),
+			want: BlankInfo{Uniform: true, Black: false},
+		},
+		{
+			name: "page with content",
+			info: DetectBlank(createTestImageWithBorder(100, 100, 20, color.White, color.Black)),
+			want: BlankInfo{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.info != tt.want {
+				t.Errorf("DetectBlank() = %+v, want %+v", tt.info, tt.want)
+			}
+		})
+	}
+}
@@ -1,43 +1,55 @@
 package imageprocessing
 
 import (
-	"image/png"
+	"image"
+	"image/color"
+	_ "image/jpeg"
+	_ "image/png"
 	"os"
+
+	_ "golang.org/x/image/bmp"
 )
 
 // CompareImages compares two images and returns similarity score (0.0 to 1.0)
 // Higher score means more similar
 func CompareImages(img1Path, img2Path string) (float64, error) {
-	// Load first image
-	file1, err := os.Open(img1Path)
+	img1, err := LoadImage(img1Path)
 	if err != nil {
 		return 0, err
 	}
-	defer file1.Close()
 
-	img1, err := png.Decode(file1)
+	img2, err := LoadImage(img2Path)
 	if err != nil {
 		return 0, err
 	}
 
-	// Load second image
-	file2, err := os.Open(img2Path)
+	return CompareDecodedImages(img1, img2), nil
+}
+
+// LoadImage opens and decodes a PNG or JPEG file
+func LoadImage(path string) (image.Image, error) {
+	file, err := os.Open(path)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
-	defer file2.Close()
+	defer file.Close()
 
-	img2, err := png.Decode(file2)
+	img, _, err := image.Decode(file)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
+	return img, nil
+}
 
+// CompareDecodedImages compares two decoded images like CompareImages
+// Images of different sizes have similarity 0
+func CompareDecodedImages(img1, img2 image.Image) float64 {
 	// Check if dimensions match
 	bounds1 := img1.Bounds()
 	bounds2 := img2.Bounds()
 
 	if bounds1.Dx() != bounds2.Dx() || bounds1.Dy() != bounds2.Dy() {
-		return 0, nil
+		return 0
 	}
 
 	// Compare pixels
@@ -49,18 +61,7 @@ func CompareImages(img1Path, img2Path string) (float64, error) {
 		for x := bounds1.Min.X; x < bounds1.Max.X; x += 10 {
 			totalCount++
 
-			r1, g1, b1, _ := img1.At(x, y).RGBA()
-			r2, g2, b2, _ := img2.At(x, y).RGBA()
-
-			// Convert to 8-bit
-			r1, g1, b1 = r1>>8, g1>>8, b1>>8
-			r2, g2, b2 = r2>>8, g2>>8, b2>>8
-
-			// Check if similar (within threshold)
-			threshold := uint32(30)
-			if absUint32(r1, r2) <= threshold &&
-				absUint32(g1, g2) <= threshold &&
-				absUint32(b1, b2) <= threshold {
+			if similarPixels(img1.At(x, y), img2.At(x-bounds1.Min.X+bounds2.Min.X, y-bounds1.Min.Y+bounds2.Min.Y)) {
 				matchCount++
 			}
 		}
@@ -68,7 +69,97 @@ func CompareImages(img1Path, img2Path string) (float64, error) {
 
 	// Return similarity score
 	similarity := float64(matchCount) / float64(totalCount)
-	return similarity, nil
+	return similarity
+}
+
+// CompareImagesInRegion compares only the given region of two images
+// The region is in pixels relative to each image's top-left corner, so a static
+// header or a changing page number outside it is ignored. An empty region
+// compares the whole images like CompareImages
+func CompareImagesInRegion(img1Path, img2Path string, region image.Rectangle) (float64, error) {
+	return CompareFiles(PixelComparer{}, img1Path, img2Path, region)
+}
+
+// CompareFiles decodes two image files and compares the region with c
+func CompareFiles(c Comparer, img1Path, img2Path string, region image.Rectangle) (float64, error) {
+	img1, err := LoadImage(img1Path)
+	if err != nil {
+		return 0, err
+	}
+
+	img2, err := LoadImage(img2Path)
+	if err != nil {
+		return 0, err
+	}
+
+	return CompareInRegion(c, img1, img2, region), nil
+}
+
+// CompareDecodedImagesInRegion compares only the given region of two decoded images
+// Images of different sizes have similarity 0, as do regions entirely outside them
+func CompareDecodedImagesInRegion(img1, img2 image.Image, region image.Rectangle) float64 {
+	return CompareInRegion(PixelComparer{}, img1, img2, region)
+}
+
+// CompareInRegion compares the given region of two decoded images with c
+// An empty region compares the whole images
+func CompareInRegion(c Comparer, img1, img2 image.Image, region image.Rectangle) float64 {
+	if region.Empty() {
+		return c.Compare(img1, img2)
+	}
+	if img1.Bounds().Size() != img2.Bounds().Size() {
+		return 0
+	}
+
+	sub1, ok1 := cropToRegion(img1, region)
+	sub2, ok2 := cropToRegion(img2, region)
+	if !ok1 || !ok2 {
+		return 0
+	}
+	return c.Compare(sub1, sub2)
+}
+
+// cropToRegion returns the part of img inside region (relative to its origin)
+// Reports false when the region does not overlap the image
+func cropToRegion(img image.Image, region image.Rectangle) (image.Image, bool) {
+	bounds := img.Bounds()
+	rect := region.Add(bounds.Min).Intersect(bounds)
+	if rect.Empty() {
+		return nil, false
+	}
+	if sub, ok := img.(interface {
+		SubImage(r image.Rectangle) image.Image
+	}); ok {
+		return sub.SubImage(rect), true
+	}
+	return &regionImage{Image: img, rect: rect}, true
+}
+
+// regionImage restricts an image without SubImage support to a rectangle
+type regionImage struct {
+	image.Image
+	rect image.Rectangle
+}
+
+// Bounds returns the restricted rectangle
+func (r *regionImage) Bounds() image.Rectangle {
+	return r.rect
+}
+
+// similarPixels reports whether two colours are within 30 of each other on
+// every 8-bit channel
+func similarPixels(c1, c2 color.Color) bool {
+	r1, g1, b1, _ := c1.RGBA()
+	r2, g2, b2, _ := c2.RGBA()
+
+	// Convert to 8-bit
+	r1, g1, b1 = r1>>8, g1>>8, b1>>8
+	r2, g2, b2 = r2>>8, g2>>8, b2>>8
+
+	const threshold = uint32(30)
+	return absUint32(r1, r2) <= threshold &&
+		absUint32(g1, g2) <= threshold &&
+		absUint32(b1, b2) <= threshold
 }
 
 // absUint32 returns absolute difference
@@ -0,0 +1,124 @@
+package imageprocessing
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"testing"
+)
+
+// pageWithChrome draws a white page whose header band (top 20px) has the given color
+func pageWithChrome(header color.Color) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, 200, 200))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(img, image.Rect(0, 0, 200, 20), image.NewUniform(header), image.Point{}, draw.Src)
+	return img
+}
+
+func TestCompareDecodedImagesInRegion(t *testing.T) {
+	page1 := pageWithChrome(color.White)
+	page2 := pageWithChrome(color.Black)
+
+	if whole := CompareDecodedImagesInRegion(page1, page2, image.Rectangle{}); whole >= 1 {
+		t.Errorf("Whole-image similarity should see the header change, got %v", whole)
+	}
+
+	content := image.Rect(0, 40, 200, 200)
+	if got := CompareDecodedImagesInRegion(page1, page2, content); got != 1 {
+		t.Errorf("Content region should ignore the header, got %v", got)
+	}
+
+	header := image.Rect(0, 0, 200, 20)
+	if got := CompareDecodedImagesInRegion(page1, page2, header); got != 0 {
+		t.Errorf("Header region should be completely different, got %v", got)
+	}
+
+	outside := image.Rect(500, 500, 600, 600)
+	if got := CompareDecodedImagesInRegion(page1, page1, outside); got != 0 {
+		t.Errorf("Region outside the image should give 0, got %v", got)
+	}
+
+	// Regions are relative to the image origin, so trimmed sub-images still line up
+	shifted := page2.SubImage(image.Rect(0, 10, 200, 200))
+	if got := CompareDecodedImagesInRegion(page1.SubImage(image.Rect(0, 10, 200, 200)), shifted, image.Rect(0, 30, 200, 190)); got != 1 {
+		t.Errorf("Region on offset images should ignore the header, got %v", got)
+	}
+}
+
+func TestComparers(t *testing.T) {
+	page := pageWithChrome(color.White)
+	other := image.NewRGBA(image.Rect(0, 0, 200, 200))
+	for y := 0; y < 200; y++ {
+		for x := 0; x < 200; x++ {
+			// Stripes: a page with completely different content
+			if (x/10)%2 == 0 {
+				other.Set(x, y, color.Black)
+			} else {
+				other.Set(x, y, color.White)
+			}
+		}
+	}
+	small := image.NewRGBA(image.Rect(0, 0, 100, 100))
+
+	for _, name := range ValidSimilarityAlgorithms {
+		t.Run(name, func(t *testing.T) {
+			c, err := NewComparer(name)
+			if err != nil {
+				t.Fatalf("NewComparer(%q) failed: %v", name, err)
+			}
+			if got := c.Compare(page, page); got < 0.999 {
+				t.Errorf("identical pages: expected ~1, got %v", got)
+			}
+			if got := c.Compare(page, other); got >= 0.9 {
+				t.Errorf("different pages: expected < 0.9, got %v", got)
+			}
+			if got := c.Compare(page, small); got != 0 {
+				t.Errorf("different sizes: expected 0, got %v", got)
+			}
+		})
+	}
+
+	if c, err := NewComparer(""); err != nil || c != (PixelComparer{}) {
+		t.Errorf("empty algorithm should select the pixel comparer, got %v, %v", c, err)
+	}
+	if _, err := NewComparer("psnr"); err == nil {
+		t.Error("expected an error for an unknown algorithm")
+	}
+}
+
+func TestSizedComparers(t *testing.T) {
+	page := pageWithChrome(color.White)
+	changed := pageWithChrome(color.White)
+	for y := 60; y < 140; y++ {
+		for x := 20; x < 180; x++ {
+			changed.Set(x, y, color.Black)
+		}
+	}
+
+	// dhash has a fixed size
+	for _, name := range []string{"pixel", "downscale-mad", "ssim"} {
+		for _, size := range []int{16, 64, 128} {
+			c, err := NewSizedComparer(name, size)
+			if err != nil {
+				t.Fatalf("NewSizedComparer(%q, %d) failed: %v", name, size, err)
+			}
+			if got := c.Compare(page, page); got < 0.999 {
+				t.Errorf("%s at %d: identical pages: expected ~1, got %v", name, size, got)
+			}
+			if got := c.Compare(page, changed); got >= 0.9 {
+				t.Errorf("%s at %d: changed pages: expected < 0.9, got %v", name, size, got)
+			}
+		}
+	}
+
+	// A sized grid scores about like the 10px grid on the same change
+	full := PixelComparer{}.Compare(page, changed)
+	if got := (PixelComparer{Size: 64}).Compare(page, changed); math.Abs(got-full) > 0.05 {
+		t.Errorf("64x64 grid: expected about %v, got %v", full, got)
+	}
+
+	if _, err := NewSizedComparer("pixel", -1); err == nil {
+		t.Error("expected an error for a negative size")
+	}
+}
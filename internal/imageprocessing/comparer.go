@@ -0,0 +1,235 @@
+package imageprocessing
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"math/bits"
+)
+
+// Comparer scores how similar two decoded images are (0.0 to 1.0)
+// Images of different sizes have similarity 0 with every comparer
+type Comparer interface {
+	Compare(img1, img2 image.Image) float64
+}
+
+// ValidSimilarityAlgorithms lists the names accepted by NewComparer
+var ValidSimilarityAlgorithms = []string{"pixel", "downscale-mad", "ssim", "dhash"}
+
+// NewComparer returns the comparer for a similarity algorithm ("" = pixel)
+func NewComparer(algorithm string) (Comparer, error) {
+	return NewSizedComparer(algorithm, 0)
+}
+
+// NewSizedComparer returns the comparer for a similarity algorithm that
+// works at the given sample size (size x size; 0 = the algorithm's own)
+// Smaller sizes are faster on large captures at some cost in accuracy; dhash
+// always uses its 9x8 hash
+func NewSizedComparer(algorithm string, size int) (Comparer, error) {
+	if size < 0 {
+		return nil, fmt.Errorf("invalid similarity sample size: %d", size)
+	}
+	switch algorithm {
+	case "", "pixel":
+		return PixelComparer{Size: size}, nil
+	case "downscale-mad":
+		return DownscaleMADComparer{Size: size}, nil
+	case "ssim":
+		return SSIMComparer{Size: size}, nil
+	case "dhash":
+		return DHashComparer{}, nil
+	default:
+		return nil, fmt.Errorf("unknown similarity algorithm: %s", algorithm)
+	}
+}
+
+// PixelComparer counts matching pixels on a 10px grid (the original metric),
+// or on an evenly spaced Size x Size grid when Size is set
+type PixelComparer struct {
+	Size int
+}
+
+// Compare implements Comparer
+func (c PixelComparer) Compare(img1, img2 image.Image) float64 {
+	if c.Size <= 0 {
+		return CompareDecodedImages(img1, img2)
+	}
+	bounds1 := img1.Bounds()
+	bounds2 := img2.Bounds()
+	if bounds1.Size() != bounds2.Size() || bounds1.Empty() {
+		return 0
+	}
+
+	cols := min(c.Size, bounds1.Dx())
+	rows := min(c.Size, bounds1.Dy())
+	matchCount := 0
+	for row := 0; row < rows; row++ {
+		dy := (2*row + 1) * bounds1.Dy() / (2 * rows)
+		for col := 0; col < cols; col++ {
+			dx := (2*col + 1) * bounds1.Dx() / (2 * cols)
+			if similarPixels(img1.At(bounds1.Min.X+dx, bounds1.Min.Y+dy), img2.At(bounds2.Min.X+dx, bounds2.Min.Y+dy)) {
+				matchCount++
+			}
+		}
+	}
+	return float64(matchCount) / float64(rows*cols)
+}
+
+// DownscaleMADComparer compares grayscale thumbnails (Size x Size, default
+// 64x64) by mean absolute difference; tolerant of rendering noise
+type DownscaleMADComparer struct {
+	Size int
+}
+
+// Compare implements Comparer
+func (c DownscaleMADComparer) Compare(img1, img2 image.Image) float64 {
+	if img1.Bounds().Size() != img2.Bounds().Size() {
+		return 0
+	}
+	size := c.Size
+	if size <= 0 {
+		size = 64
+	}
+	a := downscaleGray(img1, size, size)
+	b := downscaleGray(img2, size, size)
+
+	var sum float64
+	for i := range a {
+		sum += math.Abs(a[i] - b[i])
+	}
+	return 1 - sum/float64(len(a))/255
+}
+
+// SSIMComparer computes the mean structural similarity of 8x8 windows on
+// grayscale thumbnails (Size x Size rounded down to a multiple of 8, at least
+// 16; default 128x128); the most accurate and the slowest
+type SSIMComparer struct {
+	Size int
+}
+
+// Compare implements Comparer
+func (c SSIMComparer) Compare(img1, img2 image.Image) float64 {
+	if img1.Bounds().Size() != img2.Bounds().Size() {
+		return 0
+	}
+	const window = 8
+	size := 128
+	if c.Size > 0 {
+		size = max(16, c.Size/window*window)
+	}
+	const c1, c2 = (0.01 * 255) * (0.01 * 255), (0.03 * 255) * (0.03 * 255)
+	a := downscaleGray(img1, size, size)
+	b := downscaleGray(img2, size, size)
+
+	var total float64
+	windows := 0
+	for wy := 0; wy < size; wy += window {
+		for wx := 0; wx < size; wx += window {
+			var meanA, meanB float64
+			for y := wy; y < wy+window; y++ {
+				for x := wx; x < wx+window; x++ {
+					meanA += a[y*size+x]
+					meanB += b[y*size+x]
+				}
+			}
+			n := float64(window * window)
+			meanA /= n
+			meanB /= n
+
+			var varA, varB, cov float64
+			for y := wy; y < wy+window; y++ {
+				for x := wx; x < wx+window; x++ {
+					da := a[y*size+x] - meanA
+					db := b[y*size+x] - meanB
+					varA += da * da
+					varB += db * db
+					cov += da * db
+				}
+			}
+			varA /= n - 1
+			varB /= n - 1
+			cov /= n - 1
+
+			total += ((2*meanA*meanB + c1) * (2*cov + c2)) /
+				((meanA*meanA + meanB*meanB + c1) * (varA + varB + c2))
+			windows++
+		}
+	}
+
+	return math.Max(0, math.Min(1, total/float64(windows)))
+}
+
+// DHashComparer compares 64-bit difference hashes; the fastest, but coarse:
+// pages with similar layouts can hash alike
+type DHashComparer struct{}
+
+// Compare implements Comparer
+func (DHashComparer) Compare(img1, img2 image.Image) float64 {
+	if img1.Bounds().Size() != img2.Bounds().Size() {
+		return 0
+	}
+	return 1 - float64(HammingDistance(DHash(img1), DHash(img2)))/64
+}
+
+// HammingDistance returns the number of differing bits of two hashes
+func HammingDistance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// DHash returns a 64-bit difference hash: one bit per adjacent pair of a 9x8
+// grayscale thumbnail, telling whether brightness increases left to right
+func DHash(img image.Image) uint64 {
+	g := downscaleGray(img, 9, 8)
+	var hash uint64
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			hash <<= 1
+			if g[y*9+x] < g[y*9+x+1] {
+				hash |= 1
+			}
+		}
+	}
+	return hash
+}
+
+// downscaleGray returns a w x h grayscale thumbnail (0-255, row-major)
+// Each cell averages up to 8x8 evenly spaced samples, so the cost does not
+// grow with the capture resolution
+func downscaleGray(img image.Image, w, h int) []float64 {
+	bounds := img.Bounds()
+	out := make([]float64, w*h)
+	if bounds.Empty() {
+		return out
+	}
+
+	for cy := 0; cy < h; cy++ {
+		y0 := bounds.Min.Y + cy*bounds.Dy()/h
+		y1 := bounds.Min.Y + (cy+1)*bounds.Dy()/h
+		if y1 <= y0 {
+			y1 = y0 + 1
+		}
+		for cx := 0; cx < w; cx++ {
+			x0 := bounds.Min.X + cx*bounds.Dx()/w
+			x1 := bounds.Min.X + (cx+1)*bounds.Dx()/w
+			if x1 <= x0 {
+				x1 = x0 + 1
+			}
+
+			stepY := (y1 - y0 + 7) / 8
+			stepX := (x1 - x0 + 7) / 8
+			var sum float64
+			count := 0
+			for y := y0; y < y1 && y < bounds.Max.Y; y += stepY {
+				for x := x0; x < x1 && x < bounds.Max.X; x += stepX {
+					r, g, b, _ := img.At(x, y).RGBA()
+					sum += 0.299*float64(r>>8) + 0.587*float64(g>>8) + 0.114*float64(b>>8)
+					count++
+				}
+			}
+			if count > 0 {
+				out[cy*w+cx] = sum / float64(count)
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,23 @@
+package imageprocessing
+
+import (
+	"image"
+	"image/draw"
+)
+
+// Invert returns the negative of an image, keeping its alpha
+// Turns dark-mode pages (light text on black) into black on white
+func Invert(img image.Image) image.Image {
+	bounds := img.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(dst, dst.Bounds(), img, bounds.Min, draw.Src)
+
+	// RGBA is alpha-premultiplied, so the inverse of c is a-c
+	for i := 0; i < len(dst.Pix); i += 4 {
+		a := dst.Pix[i+3]
+		dst.Pix[i] = a - dst.Pix[i]
+		dst.Pix[i+1] = a - dst.Pix[i+1]
+		dst.Pix[i+2] = a - dst.Pix[i+2]
+	}
+	return dst
+}
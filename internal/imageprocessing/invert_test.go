@@ -0,0 +1,35 @@
+package imageprocessing
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestInvert(t *testing.T) {
+	// Dark-mode page: light gray text pixel on black, offset bounds
+	img := image.NewRGBA(image.Rect(10, 10, 13, 12))
+	for y := 10; y < 12; y++ {
+		for x := 10; x < 13; x++ {
+			img.Set(x, y, color.Black)
+		}
+	}
+	img.Set(11, 11, color.RGBA{R: 200, G: 200, B: 200, A: 255})
+
+	inverted := Invert(img)
+	if got := inverted.Bounds(); got != image.Rect(0, 0, 3, 2) {
+		t.Fatalf("expected 3x2 bounds at the origin, got %v", got)
+	}
+	if got := color.RGBAModel.Convert(inverted.At(0, 0)); got != (color.RGBA{R: 255, G: 255, B: 255, A: 255}) {
+		t.Errorf("expected black to become white, got %v", got)
+	}
+	if got := color.RGBAModel.Convert(inverted.At(1, 1)); got != (color.RGBA{R: 55, G: 55, B: 55, A: 255}) {
+		t.Errorf("expected light gray to become dark gray, got %v", got)
+	}
+
+	// Transparent pixels stay transparent
+	clear := image.NewRGBA(image.Rect(0, 0, 1, 1))
+	if got := color.RGBAModel.Convert(Invert(clear).At(0, 0)); got != (color.RGBA{}) {
+		t.Errorf("expected a transparent pixel to stay transparent, got %v", got)
+	}
+}
@@ -0,0 +1,114 @@
+package imageprocessing
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+const (
+	// paperCornerTolerance is how far (per 8-bit channel) a corner may be
+	// from the corner average for the corners to count as one paper colour
+	paperCornerTolerance = 16
+
+	// paperMinChannel is the darkest channel a paper colour may have; darker
+	// backgrounds (dark mode, full-bleed images) are left alone
+	paperMinChannel = 128
+
+	// paperTintTolerance is how far a pixel may stray from the paper-to-ink
+	// line before it counts as coloured; the correction fades out over the
+	// same distance again, so illustrations keep their colours
+	paperTintTolerance = 24
+)
+
+// PaperColor returns the page background colour when all four corners show
+// the same light colour (e.g. the sepia theme's warm paper)
+func PaperColor(img image.Image) (color.RGBA, bool) {
+	var samples [][3]int
+	var sum [3]int
+	for _, p := range cornerPoints(img.Bounds()) {
+		r, g, b, _ := img.At(p.X, p.Y).RGBA()
+		c := [3]int{int(r >> 8), int(g >> 8), int(b >> 8)}
+		samples = append(samples, c)
+		for i := range sum {
+			sum[i] += c[i]
+		}
+	}
+
+	var avg [3]int
+	for i := range avg {
+		avg[i] = sum[i] / len(samples)
+		if avg[i] < paperMinChannel {
+			return color.RGBA{}, false
+		}
+	}
+	for _, c := range samples {
+		for i := range c {
+			if absInt(c[i]-avg[i]) > paperCornerTolerance {
+				return color.RGBA{}, false
+			}
+		}
+	}
+	return color.RGBA{R: uint8(avg[0]), G: uint8(avg[1]), B: uint8(avg[2]), A: 255}, true
+}
+
+// NormalizePaper remaps a tinted page background to pure white
+// Each channel is scaled so the paper colour becomes 255, which keeps black
+// text black and its contrast intact. Only pixels on the line between ink and
+// paper (shades of the paper tint) are corrected; coloured pixels such as
+// illustrations are kept. Pages without a light, uniform paper colour are
+// returned unchanged
+func NormalizePaper(img image.Image) image.Image {
+	paper, ok := PaperColor(img)
+	if !ok || (paper.R == 255 && paper.G == 255 && paper.B == 255) {
+		return img
+	}
+	bg := [3]float64{float64(paper.R), float64(paper.G), float64(paper.B)}
+	bgSum := bg[0] + bg[1] + bg[2]
+
+	bounds := img.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(dst, dst.Bounds(), img, bounds.Min, draw.Src)
+
+	for i := 0; i < len(dst.Pix); i += 4 {
+		px := [3]float64{float64(dst.Pix[i]), float64(dst.Pix[i+1]), float64(dst.Pix[i+2])}
+
+		// Position on the ink (0) to paper (1) line and distance from it
+		t := (px[0] + px[1] + px[2]) / bgSum
+		if t > 1 {
+			t = 1
+		}
+		deviation := 0.0
+		for c := range px {
+			if d := px[c] - t*bg[c]; d > deviation {
+				deviation = d
+			} else if -d > deviation {
+				deviation = -d
+			}
+		}
+
+		weight := (2*paperTintTolerance - deviation) / paperTintTolerance
+		if weight <= 0 {
+			continue
+		}
+		if weight > 1 {
+			weight = 1
+		}
+		for c := range px {
+			corrected := px[c] * 255 / bg[c]
+			if corrected > 255 {
+				corrected = 255
+			}
+			dst.Pix[i+c] = uint8(px[c] + weight*(corrected-px[c]) + 0.5)
+		}
+	}
+	return dst
+}
+
+// absInt returns the absolute value of n
+func absInt(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
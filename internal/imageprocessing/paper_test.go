@@ -0,0 +1,51 @@
+package imageprocessing
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestNormalizePaper(t *testing.T) {
+	sepia := color.RGBA{R: 251, G: 240, B: 219, A: 255}
+	red := color.RGBA{R: 200, G: 30, B: 30, A: 255}
+	img := image.NewRGBA(image.Rect(0, 0, 20, 20))
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 20; x++ {
+			img.Set(x, y, sepia)
+		}
+	}
+	img.Set(5, 5, color.Black)
+	// Mid-tone text anti-aliasing: half way between ink and paper
+	img.Set(6, 5, color.RGBA{R: 126, G: 120, B: 110, A: 255})
+	img.Set(10, 10, red)
+
+	if paper, ok := PaperColor(img); !ok || paper != sepia {
+		t.Fatalf("expected paper colour %v, got %v (%v)", sepia, paper, ok)
+	}
+
+	out := NormalizePaper(img)
+	at := func(x, y int) color.RGBA { return color.RGBAModel.Convert(out.At(x, y)).(color.RGBA) }
+	if got := at(0, 0); got != (color.RGBA{R: 255, G: 255, B: 255, A: 255}) {
+		t.Errorf("expected white paper, got %v", got)
+	}
+	if got := at(5, 5); got != (color.RGBA{A: 255}) {
+		t.Errorf("expected black text to stay black, got %v", got)
+	}
+	if got := at(6, 5); absInt(int(got.R)-int(got.B)) > 2 {
+		t.Errorf("expected a neutral gray for tinted anti-aliasing, got %v", got)
+	}
+	if got := at(10, 10); got != red {
+		t.Errorf("expected the illustration colour to be kept, got %v", got)
+	}
+
+	// Dark-mode and mixed-corner pages are left alone
+	dark := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	if NormalizePaper(dark) != image.Image(dark) {
+		t.Error("expected a dark page to be returned unchanged")
+	}
+	img.Set(19, 19, red)
+	if _, ok := PaperColor(img); ok {
+		t.Error("expected no paper colour when the corners disagree")
+	}
+}
@@ -0,0 +1,77 @@
+package imageprocessing
+
+import (
+	"image"
+	"image/color"
+
+	"golang.org/x/image/draw"
+)
+
+// Thumbnail scales img down so its longer side is at most maxSize pixels,
+// keeping the aspect ratio. Images that already fit are returned unchanged
+func Thumbnail(img image.Image, maxSize int) image.Image {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	if maxSize <= 0 || (w <= maxSize && h <= maxSize) {
+		return img
+	}
+
+	if w >= h {
+		w, h = maxSize, max(1, h*maxSize/w)
+	} else {
+		w, h = max(1, w*maxSize/h), maxSize
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.BiLinear.Scale(dst, dst.Bounds(), img, bounds, draw.Src, nil)
+	return dst
+}
+
+// ThumbnailFile writes a thumbnail of an image file as PNG, see Thumbnail
+func ThumbnailFile(inputPath, outputPath string, maxSize int) error {
+	img, err := LoadImage(inputPath)
+	if err != nil {
+		return err
+	}
+	return SavePNG(Thumbnail(img, maxSize), outputPath)
+}
+
+// PadToSize centres img on a w x h canvas filled with bg
+// Images at least that large in both directions are returned unchanged;
+// padding never crops, so a larger side keeps its size
+func PadToSize(img image.Image, w, h int, bg color.Color) image.Image {
+	bounds := img.Bounds()
+	w, h = max(w, bounds.Dx()), max(h, bounds.Dy())
+	if w == bounds.Dx() && h == bounds.Dy() {
+		return img
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(bg), image.Point{}, draw.Src)
+	offset := image.Pt((w-bounds.Dx())/2, (h-bounds.Dy())/2)
+	draw.Draw(dst, bounds.Sub(bounds.Min).Add(offset), img, bounds.Min, draw.Src)
+	return dst
+}
+
+// BackgroundColor returns the colour to pad a page with: its paper colour,
+// or its top-left pixel on pages without a light, uniform paper colour (dark
+// mode, full-bleed images)
+func BackgroundColor(img image.Image) color.Color {
+	if paper, ok := PaperColor(img); ok {
+		return paper
+	}
+	bounds := img.Bounds()
+	return img.At(bounds.Min.X, bounds.Min.Y)
+}
+
+// PadImageFile pads an image file to w x h with bg (nil: its own
+// background colour) and writes it as PNG, see PadToSize
+func PadImageFile(inputPath, outputPath string, w, h int, bg color.Color) error {
+	img, err := LoadImage(inputPath)
+	if err != nil {
+		return err
+	}
+	if bg == nil {
+		bg = BackgroundColor(img)
+	}
+	return SavePNG(PadToSize(img, w, h, bg), outputPath)
+}
@@ -0,0 +1,56 @@
+package imageprocessing
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestThumbnail(t *testing.T) {
+	tests := []struct {
+		w, h, maxSize int
+		wantW, wantH  int
+	}{
+		{300, 600, 100, 50, 100},
+		{600, 300, 100, 100, 50},
+		{80, 60, 100, 80, 60}, // already fits
+		{300, 600, 0, 300, 600},
+	}
+	for _, tt := range tests {
+		img := image.NewRGBA(image.Rect(0, 0, tt.w, tt.h))
+		got := Thumbnail(img, tt.maxSize).Bounds()
+		if got.Dx() != tt.wantW || got.Dy() != tt.wantH {
+			t.Errorf("Thumbnail(%dx%d, %d) = %dx%d, want %dx%d", tt.w, tt.h, tt.maxSize, got.Dx(), got.Dy(), tt.wantW, tt.wantH)
+		}
+	}
+}
+
+func TestPadToSize(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 2))
+	for x := 0; x < 4; x++ {
+		for y := 0; y < 2; y++ {
+			img.Set(x, y, color.Black)
+		}
+	}
+	bg := color.RGBA{R: 240, G: 230, B: 210, A: 255}
+
+	padded := PadToSize(img, 8, 6, bg)
+	if got := padded.Bounds().Size(); got != image.Pt(8, 6) {
+		t.Fatalf("expected 8x6, got %v", got)
+	}
+	// Content is centred, the rest is background
+	if got := color.RGBAModel.Convert(padded.At(2, 2)); got != color.RGBAModel.Convert(color.Black) {
+		t.Errorf("expected content at (2,2), got %v", got)
+	}
+	if got := padded.At(0, 0); got != bg {
+		t.Errorf("expected background at (0,0), got %v", got)
+	}
+
+	// Never crops, and an image of the target size is returned as is
+	if got := PadToSize(img, 2, 6, bg).Bounds().Size(); got != image.Pt(4, 6) {
+		t.Errorf("expected 4x6 without cropping, got %v", got)
+	}
+	if PadToSize(img, 4, 2, bg) != image.Image(img) {
+		t.Error("expected the same image when it already has the size")
+	}
+}
@@ -0,0 +1,146 @@
+package imageprocessing
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"os"
+)
+
+// normalizeDegrees maps an angle to 0, 90, 180 or 270
+func normalizeDegrees(degrees int) int {
+	return ((degrees % 360) + 360) % 360
+}
+
+// Rotate turns an image clockwise by a multiple of 90 degrees
+// Other angles and 0 return the image unchanged
+func Rotate(img image.Image, degrees int) image.Image {
+	degrees = normalizeDegrees(degrees)
+	if degrees == 0 || degrees%90 != 0 {
+		return img
+	}
+
+	// Work on an RGBA copy so the inner loop reads pixels directly
+	bounds := img.Bounds()
+	src, ok := img.(*image.RGBA)
+	if !ok {
+		src = image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+		draw.Draw(src, src.Bounds(), img, bounds.Min, draw.Src)
+	}
+	w, h := bounds.Dx(), bounds.Dy()
+	origin := src.Bounds().Min
+
+	dstW, dstH := w, h
+	if degrees != 180 {
+		dstW, dstH = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dstW, dstH))
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch degrees {
+			case 90:
+				dx, dy = h-1-y, x
+			case 180:
+				dx, dy = w-1-x, h-1-y
+			case 270:
+				dx, dy = y, w-1-x
+			}
+			si := src.PixOffset(origin.X+x, origin.Y+y)
+			di := dst.PixOffset(dx, dy)
+			copy(dst.Pix[di:di+4], src.Pix[si:si+4])
+		}
+	}
+	return dst
+}
+
+// RotateMargins maps margins measured on an unrotated image to the edges
+// they end up on after Rotate, so margins need not be measured again
+func RotateMargins(m TrimMargins, degrees int) TrimMargins {
+	switch normalizeDegrees(degrees) {
+	case 90:
+		return TrimMargins{Top: m.Left, Right: m.Top, Bottom: m.Right, Left: m.Bottom}
+	case 180:
+		return TrimMargins{Top: m.Bottom, Right: m.Left, Bottom: m.Top, Left: m.Right}
+	case 270:
+		return TrimMargins{Top: m.Right, Right: m.Bottom, Bottom: m.Left, Left: m.Top}
+	}
+	return m
+}
+
+// RotatedSize returns the size of a width x height image after Rotate
+func RotatedSize(width, height, degrees int) (int, int) {
+	if d := normalizeDegrees(degrees); d == 90 || d == 270 {
+		return height, width
+	}
+	return width, height
+}
+
+// RotateImageFile rotates an image file clockwise and saves it as PNG
+func RotateImageFile(inputPath, outputPath string, degrees int) error {
+	img, err := LoadImage(inputPath)
+	if err != nil {
+		return err
+	}
+	return SavePNG(Rotate(img, degrees), outputPath)
+}
+
+// SavePNG encodes an image as PNG to outputPath
+func SavePNG(img image.Image, outputPath string) error {
+	outFile, err := os.Create(outputPath)
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+	defer outFile.Close()
+
+	if err := png.Encode(outFile, img); err != nil {
+		return fmt.Errorf("failed to encode image: %w", err)
+	}
+	return nil
+}
+
+// Orientation is whether a page is taller or wider
+type Orientation int
+
+const (
+	// OrientationSquare covers pages within squareTolerance of square, which
+	// are never reoriented
+	OrientationSquare Orientation = iota
+	OrientationPortrait
+	OrientationLandscape
+)
+
+// squareTolerance is the aspect ratio difference still treated as square
+const squareTolerance = 0.05
+
+// OrientationOf classifies an image size as portrait, landscape or square
+func OrientationOf(size image.Point) Orientation {
+	if size.X <= 0 || size.Y <= 0 {
+		return OrientationSquare
+	}
+	ratio := float64(size.X) / float64(size.Y)
+	switch {
+	case ratio > 1+squareTolerance:
+		return OrientationLandscape
+	case ratio < 1/(1+squareTolerance):
+		return OrientationPortrait
+	}
+	return OrientationSquare
+}
+
+// ImageSize reads the dimensions of an image file without decoding its pixels
+func ImageSize(path string) (image.Point, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return image.Point{}, fmt.Errorf("failed to open image file: %w", err)
+	}
+	defer file.Close()
+
+	config, _, err := image.DecodeConfig(file)
+	if err != nil {
+		return image.Point{}, fmt.Errorf("failed to read image size: %w", err)
+	}
+	return image.Pt(config.Width, config.Height), nil
+}
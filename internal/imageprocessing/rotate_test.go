@@ -0,0 +1,77 @@
+package imageprocessing
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestRotate(t *testing.T) {
+	// 3x2 image with a red top-left pixel
+	img := image.NewRGBA(image.Rect(0, 0, 3, 2))
+	red := color.RGBA{R: 255, A: 255}
+	img.Set(0, 0, red)
+
+	tests := []struct {
+		degrees int
+		size    image.Point
+		red     image.Point
+	}{
+		{0, image.Pt(3, 2), image.Pt(0, 0)},
+		{90, image.Pt(2, 3), image.Pt(1, 0)},
+		{180, image.Pt(3, 2), image.Pt(2, 1)},
+		{270, image.Pt(2, 3), image.Pt(0, 2)},
+		{-90, image.Pt(2, 3), image.Pt(0, 2)},
+		{45, image.Pt(3, 2), image.Pt(0, 0)},
+	}
+
+	for _, tt := range tests {
+		rotated := Rotate(img, tt.degrees)
+		if got := rotated.Bounds().Size(); got != tt.size {
+			t.Errorf("%d°: expected size %v, got %v", tt.degrees, tt.size, got)
+		}
+		if got := color.RGBAModel.Convert(rotated.At(tt.red.X, tt.red.Y)); got != red {
+			t.Errorf("%d°: expected red at %v, got %v", tt.degrees, tt.red, got)
+		}
+	}
+}
+
+func TestRotateMargins(t *testing.T) {
+	// Uneven white border around black content
+	img := image.NewRGBA(image.Rect(0, 0, 200, 300))
+	for y := 0; y < 300; y++ {
+		for x := 0; x < 200; x++ {
+			c := color.White
+			if x >= 30 && x < 180 && y >= 10 && y < 250 {
+				c = color.Black
+			}
+			img.Set(x, y, c)
+		}
+	}
+
+	margins := CalculateTrimMargins(img)
+	for _, degrees := range []int{90, 180, 270} {
+		want := CalculateTrimMargins(Rotate(img, degrees))
+		if got := RotateMargins(margins, degrees); got != want {
+			t.Errorf("%d°: RotateMargins = %+v, measured %+v", degrees, got, want)
+		}
+	}
+}
+
+func TestOrientationOf(t *testing.T) {
+	tests := []struct {
+		size image.Point
+		want Orientation
+	}{
+		{image.Pt(300, 400), OrientationPortrait},
+		{image.Pt(800, 600), OrientationLandscape},
+		{image.Pt(500, 500), OrientationSquare},
+		{image.Pt(510, 500), OrientationSquare},
+		{image.Pt(0, 0), OrientationSquare},
+	}
+	for _, tt := range tests {
+		if got := OrientationOf(tt.size); got != tt.want {
+			t.Errorf("OrientationOf(%v) = %d, want %d", tt.size, got, tt.want)
+		}
+	}
+}
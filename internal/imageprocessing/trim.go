@@ -1,45 +1,86 @@
 package imageprocessing
 
 import (
+	"errors"
 	"fmt"
 	"image"
+	_ "image/jpeg"
 	"image/png"
+	"math"
 	"os"
+	"sort"
+
+	_ "golang.org/x/image/bmp"
 )
 
-// Thresholds for "Black-ish" and "White-ish" pixels
+// Default thresholds for "Black-ish" and "White-ish" pixels
 const (
 	blackThreshold = 60
 	whiteThreshold = 195
 )
 
+// TrimOptions tunes how borders are recognized (zero values = defaults)
+type TrimOptions struct {
+	// Pixels with every channel (8-bit) at or below this are border black
+	// (default: 60)
+	BlackThreshold int
+
+	// Pixels with every channel (8-bit) at or above this are border white
+	// (default: 195); lower it for grayish, anti-aliased page backgrounds
+	WhiteThreshold int
+}
+
+// DefaultTrimOptions returns the thresholds CalculateTrimMargins uses
+func DefaultTrimOptions() TrimOptions {
+	return TrimOptions{BlackThreshold: blackThreshold, WhiteThreshold: whiteThreshold}
+}
+
+// withDefaults fills unset thresholds
+func (t TrimOptions) withDefaults() TrimOptions {
+	if t.BlackThreshold <= 0 {
+		t.BlackThreshold = blackThreshold
+	}
+	if t.WhiteThreshold <= 0 {
+		t.WhiteThreshold = whiteThreshold
+	}
+	return t
+}
+
+// cornerPoints returns the four corner pixels of bounds, where the page
+// background shows on Kindle captures
+func cornerPoints(bounds image.Rectangle) []image.Point {
+	return []image.Point{
+		{bounds.Min.X, bounds.Min.Y},
+		{bounds.Max.X - 1, bounds.Min.Y},
+		{bounds.Min.X, bounds.Max.Y - 1},
+		{bounds.Max.X - 1, bounds.Max.Y - 1},
+	}
+}
+
 // findContentBounds finds the content area by removing uniform borders
-func findContentBounds(img image.Image) image.Rectangle {
+func findContentBounds(img image.Image, options TrimOptions) image.Rectangle {
 	bounds := img.Bounds()
 	minX, minY := bounds.Max.X, bounds.Max.Y
 	maxX, maxY := bounds.Min.X, bounds.Min.Y
 
+	options = options.withDefaults()
+	black := uint32(options.BlackThreshold)
+	white := uint32(options.WhiteThreshold)
+
 	// Helpers to check color type
 	isPixelBlack := func(r8, g8, b8 uint32) bool {
-		return r8 <= blackThreshold && g8 <= blackThreshold && b8 <= blackThreshold
+		return r8 <= black && g8 <= black && b8 <= black
 	}
 	isPixelWhite := func(r8, g8, b8 uint32) bool {
-		return r8 >= whiteThreshold && g8 >= whiteThreshold && b8 >= whiteThreshold
+		return r8 >= white && g8 >= white && b8 >= white
 	}
 
 	// 1. Determine the target background color (Black or White) based on corners
-	corners := []struct{ x, y int }{
-		{bounds.Min.X, bounds.Min.Y},
-		{bounds.Max.X - 1, bounds.Min.Y},
-		{bounds.Min.X, bounds.Max.Y - 1},
-		{bounds.Max.X - 1, bounds.Max.Y - 1},
-	}
-
 	blackCornerCount := 0
 	whiteCornerCount := 0
 
-	for _, p := range corners {
-		c := img.At(p.x, p.y)
+	for _, p := range cornerPoints(bounds) {
+		c := img.At(p.X, p.Y)
 		r, g, b, _ := c.RGBA()
 		r8, g8, b8 := r>>8, g>>8, b>>8
 
@@ -246,7 +287,12 @@ type TrimMargins struct {
 
 // CalculateTrimMargins analyzes an image and returns the removable margin size for each edge
 func CalculateTrimMargins(img image.Image) TrimMargins {
-	bounds := findContentBounds(img)
+	return CalculateTrimMarginsWithOptions(img, DefaultTrimOptions())
+}
+
+// CalculateTrimMarginsWithOptions is CalculateTrimMargins with custom thresholds
+func CalculateTrimMarginsWithOptions(img image.Image, options TrimOptions) TrimMargins {
+	bounds := findContentBounds(img, options)
 	originalBounds := img.Bounds()
 
 	return TrimMargins{
@@ -265,7 +311,7 @@ func CalculateTrimMarginsFromFile(imagePath string) (TrimMargins, error) {
 	}
 	defer file.Close()
 
-	img, err := png.Decode(file)
+	img, _, err := image.Decode(file)
 	if err != nil {
 		return TrimMargins{}, fmt.Errorf("failed to decode image: %w", err)
 	}
@@ -302,7 +348,22 @@ func AggregateMinimumMargins(margins []TrimMargins) TrimMargins {
 	return minMargins
 }
 
+// ErrTrimTooLarge means trim margins would leave nothing of an image
+var ErrTrimTooLarge = errors.New("trim margins leave nothing of the image")
+
+// CheckTrimMargins returns ErrTrimTooLarge, with the image size and the
+// margins, when trimming a width x height image would leave an empty image
+func CheckTrimMargins(width, height int, m TrimMargins) error {
+	if width-m.Left-m.Right <= 0 || height-m.Top-m.Bottom <= 0 {
+		return fmt.Errorf("%w: the image is %dx%d, margins are Top=%d Bottom=%d Left=%d Right=%d",
+			ErrTrimTooLarge, width, height, m.Top, m.Bottom, m.Left, m.Right)
+	}
+	return nil
+}
+
 // TrimWithCustomMargins trims an image using specific pixel margins for each edge
+// Decoded PNG/JPEG images are trimmed with SubImage, which shares the pixel
+// buffer instead of copying it; the result's bounds then keep their offset
 func TrimWithCustomMargins(img image.Image, top, bottom, left, right int) image.Image {
 	bounds := img.Bounds()
 
@@ -318,6 +379,12 @@ func TrimWithCustomMargins(img image.Image, top, bottom, left, right int) image.
 		return img
 	}
 
+	if sub, ok := img.(interface {
+		SubImage(r image.Rectangle) image.Image
+	}); ok {
+		return sub.SubImage(image.Rect(newMinX, newMinY, newMaxX, newMaxY))
+	}
+
 	// Create trimmed image
 	trimmedBounds := image.Rect(0, 0, newMaxX-newMinX, newMaxY-newMinY)
 	trimmed := image.NewRGBA(trimmedBounds)
@@ -332,6 +399,7 @@ func TrimWithCustomMargins(img image.Image, top, bottom, left, right int) image.
 }
 
 // TrimImageFileWithCustomMargins trims an image file using custom margins and saves the result
+// Only one decoded page is held at a time, so callers can trim any number of pages in a loop
 func TrimImageFileWithCustomMargins(inputPath, outputPath string, top, bottom, left, right int) error {
 	// Open input file
 	file, err := os.Open(inputPath)
@@ -340,11 +408,18 @@ func TrimImageFileWithCustomMargins(inputPath, outputPath string, top, bottom, l
 	}
 	defer file.Close()
 
-	// Decode image
-	img, err := png.Decode(file)
+	// Decode image (PNG, JPEG or BMP)
+	img, _, err := image.Decode(file)
 	if err != nil {
 		return fmt.Errorf("failed to decode image: %w", err)
 	}
+	file.Close()
+
+	// Refuse instead of silently writing the untrimmed page
+	bounds := img.Bounds()
+	if err := CheckTrimMargins(bounds.Dx(), bounds.Dy(), TrimMargins{Top: top, Bottom: bottom, Left: left, Right: right}); err != nil {
+		return err
+	}
 
 	// Trim with custom margins
 	trimmed := TrimWithCustomMargins(img, top, bottom, left, right)
@@ -362,3 +437,38 @@ func TrimImageFileWithCustomMargins(inputPath, outputPath string, top, bottom, l
 
 	return nil
 }
+
+// AggregatePercentileMargins returns, for each edge, the p-th percentile
+// (0-100, nearest rank) of the page margins
+// Unlike the minimum, a few outlier pages (e.g. full-bleed images) no longer
+// force near-zero trimming, but up to p percent of pages have less margin
+// than the result and get cropped into. p <= 0 equals AggregateMinimumMargins
+func AggregatePercentileMargins(margins []TrimMargins, p float64) TrimMargins {
+	if len(margins) == 0 {
+		return TrimMargins{}
+	}
+
+	edge := func(get func(TrimMargins) int) int {
+		values := make([]int, len(margins))
+		for i, m := range margins {
+			values[i] = get(m)
+		}
+		sort.Ints(values)
+
+		rank := int(math.Ceil(p/100*float64(len(values)))) - 1
+		if rank < 0 {
+			rank = 0
+		}
+		if rank >= len(values) {
+			rank = len(values) - 1
+		}
+		return values[rank]
+	}
+
+	return TrimMargins{
+		Top:    edge(func(m TrimMargins) int { return m.Top }),
+		Bottom: edge(func(m TrimMargins) int { return m.Bottom }),
+		Left:   edge(func(m TrimMargins) int { return m.Left }),
+		Right:  edge(func(m TrimMargins) int { return m.Right }),
+	}
+}
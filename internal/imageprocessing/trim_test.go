@@ -1,9 +1,19 @@
 package imageprocessing
 
 import (
+	"errors"
+	"fmt"
 	"image"
 	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
+
+	"golang.org/x/image/bmp"
 )
 
 func TestCalculateTrimMargins(t *testing.T) {
@@ -87,6 +97,175 @@ func TestCalculateTrimMargins(t *testing.T) {
 	})
 }
 
+func TestCalculateTrimMarginsThresholds(t *testing.T) {
+	gray := func(v uint8) color.Color { return color.RGBA{R: v, G: v, B: v, A: 255} }
+	// Content in the middle that is neither black nor white at any threshold used here
+	content := gray(128)
+
+	tests := []struct {
+		name    string
+		border  color.Color
+		options TrimOptions
+		want    int
+	}{
+		{"white at default threshold", gray(195), TrimOptions{}, 20},
+		{"just below default white", gray(194), TrimOptions{}, 0},
+		{"black at default threshold", gray(60), TrimOptions{}, 20},
+		{"just above default black", gray(61), TrimOptions{}, 0},
+		{"grayish background with lowered white", gray(180), TrimOptions{WhiteThreshold: 180}, 20},
+		{"just below lowered white", gray(179), TrimOptions{WhiteThreshold: 180}, 0},
+		{"dark gray with raised black", gray(90), TrimOptions{BlackThreshold: 90}, 20},
+		{"just above raised black", gray(91), TrimOptions{BlackThreshold: 90}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img := createTestImageWithBorder(100, 100, 20, tt.border, content)
+			m := CalculateTrimMarginsWithOptions(img, tt.options)
+			if m.Top != tt.want || m.Bottom != tt.want || m.Left != tt.want || m.Right != tt.want {
+				t.Errorf("expected %d on every edge, got %+v", tt.want, m)
+			}
+		})
+	}
+
+	// Zero options match the default thresholds
+	img := createTestImageWithBorder(100, 100, 20, gray(200), content)
+	if CalculateTrimMargins(img) != CalculateTrimMarginsWithOptions(img, TrimOptions{}) {
+		t.Error("expected zero TrimOptions to behave like CalculateTrimMargins")
+	}
+}
+
+func TestTrimWithCustomMarginsSharesPixels(t *testing.T) {
+	img := createTestImageWithBorder(100, 80, 10, color.Black, color.White)
+
+	var trimmed image.Image
+	allocs := testing.AllocsPerRun(10, func() {
+		trimmed = TrimWithCustomMargins(img, 10, 10, 10, 10)
+	})
+
+	if got := trimmed.Bounds().Size(); got != image.Pt(80, 60) {
+		t.Errorf("expected 80x60 after trimming, got %v", got)
+	}
+	if trimmed.At(trimmed.Bounds().Min.X, trimmed.Bounds().Min.Y) != img.At(10, 10) {
+		t.Error("trimmed image does not start at the first content pixel")
+	}
+	// SubImage allocates the image header only, never a second pixel buffer
+	if allocs > 2 {
+		t.Errorf("expected no pixel copy, got %.0f allocations", allocs)
+	}
+}
+
+func TestCheckTrimMargins(t *testing.T) {
+	if err := CheckTrimMargins(100, 80, TrimMargins{Top: 40, Bottom: 39, Left: 49, Right: 50}); err != nil {
+		t.Errorf("expected a 1x1 result to be allowed, got %v", err)
+	}
+	err := CheckTrimMargins(1200, 1600, TrimMargins{Top: 2000, Bottom: 2000})
+	if !errors.Is(err, ErrTrimTooLarge) || !strings.Contains(err.Error(), "1200x1600") {
+		t.Errorf("expected ErrTrimTooLarge with the image size, got %v", err)
+	}
+
+	// The file variant no longer writes the untrimmed page
+	dir := t.TempDir()
+	inputPath := filepath.Join(dir, "page.png")
+	outputPath := filepath.Join(dir, "trimmed.png")
+	if err := SavePNG(createTestImageWithBorder(100, 80, 10, color.Black, color.White), inputPath); err != nil {
+		t.Fatal(err)
+	}
+	if err := TrimImageFileWithCustomMargins(inputPath, outputPath, 50, 50, 0, 0); !errors.Is(err, ErrTrimTooLarge) {
+		t.Errorf("expected ErrTrimTooLarge, got %v", err)
+	}
+	if _, err := os.Stat(outputPath); !os.IsNotExist(err) {
+		t.Error("expected no output file")
+	}
+}
+
+// Trimming a long book one page at a time must not accumulate decoded pages
+func TestTrimImageFileBoundedMemory(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping memory test")
+	}
+
+	const width, height, pages = 800, 1200, 40
+	pageBytes := uint64(width * height * 4) // decoded RGBA page
+
+	tmpDir := t.TempDir()
+	inputPath := filepath.Join(tmpDir, "page.png")
+	f, err := os.Create(inputPath)
+	if err != nil {
+		t.Fatalf("failed to create page: %v", err)
+	}
+	if err := png.Encode(f, createTestImageWithBorder(width, height, 50, color.White, color.Black)); err != nil {
+		t.Fatalf("failed to encode page: %v", err)
+	}
+	f.Close()
+
+	heapAfter := func(n int) uint64 {
+		for i := 0; i < n; i++ {
+			outputPath := filepath.Join(tmpDir, fmt.Sprintf("page_%04d_trimmed.png", i))
+			if err := TrimImageFileWithCustomMargins(inputPath, outputPath, 50, 50, 50, 50); err != nil {
+				t.Fatalf("trim failed: %v", err)
+			}
+		}
+		runtime.GC()
+		var stats runtime.MemStats
+		runtime.ReadMemStats(&stats)
+		return stats.HeapAlloc
+	}
+
+	warm := heapAfter(5)
+	long := heapAfter(pages)
+
+	// Retained heap must not grow with the page count
+	if long > warm+pageBytes {
+		t.Errorf("heap grew from %d to %d bytes over %d pages (one page is %d bytes)", warm, long, pages, pageBytes)
+	}
+}
+
+func TestTrimImageFileFormats(t *testing.T) {
+	const width, height, border = 200, 300, 40
+	page := createTestImageWithBorder(width, height, border, color.White, color.Black)
+
+	encoders := map[string]func(f *os.File) error{
+		"page.jpg": func(f *os.File) error { return jpeg.Encode(f, page, &jpeg.Options{Quality: 90}) },
+		"page.bmp": func(f *os.File) error { return bmp.Encode(f, page) },
+	}
+	for name, encode := range encoders {
+		inputPath := filepath.Join(t.TempDir(), name)
+		f, err := os.Create(inputPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := encode(f); err != nil {
+			t.Fatalf("%s: failed to encode page: %v", name, err)
+		}
+		f.Close()
+
+		// JPEG blurs the content edge by a pixel or two
+		margins, err := CalculateTrimMarginsFromFile(inputPath)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		for _, got := range []int{margins.Top, margins.Bottom, margins.Left, margins.Right} {
+			if abs32(got, border) > 2 {
+				t.Errorf("%s: expected margins of about %d, got %+v", name, border, margins)
+				break
+			}
+		}
+
+		outputPath := filepath.Join(t.TempDir(), "trimmed.png")
+		if err := TrimImageFileWithCustomMargins(inputPath, outputPath, border, border, border, border); err != nil {
+			t.Fatalf("%s: trim failed: %v", name, err)
+		}
+		trimmed, err := LoadImage(outputPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if size := trimmed.Bounds().Size(); size != image.Pt(width-2*border, height-2*border) {
+			t.Errorf("%s: expected %dx%d after trimming, got %v", name, width-2*border, height-2*border, size)
+		}
+	}
+}
+
 // Helper function to create test image with border
 func createTestImageWithBorder(width, height, borderSize int, borderColor, fillColor color.Color) *image.RGBA {
 	img := image.NewRGBA(image.Rect(0, 0, width, height))
@@ -112,3 +291,28 @@ func abs32(a, b int) int {
 	}
 	return b - a
 }
+
+func TestAggregatePercentileMargins(t *testing.T) {
+	// Nine ordinary pages and one full-bleed outlier
+	var margins []TrimMargins
+	for i := 0; i < 9; i++ {
+		margins = append(margins, TrimMargins{Top: 40 + i, Bottom: 50, Left: 30, Right: 30})
+	}
+	margins = append(margins, TrimMargins{Top: 0, Bottom: 2, Left: 0, Right: 1})
+
+	if got := AggregateMinimumMargins(margins); got != (TrimMargins{Top: 0, Bottom: 2, Left: 0, Right: 1}) {
+		t.Errorf("minimum: got %+v", got)
+	}
+	if got := AggregatePercentileMargins(margins, 0); got != AggregateMinimumMargins(margins) {
+		t.Errorf("p0 should equal the minimum, got %+v", got)
+	}
+	if got := AggregatePercentileMargins(margins, 10); got != (TrimMargins{Top: 0, Bottom: 2, Left: 0, Right: 1}) {
+		t.Errorf("p10 of 10 pages is the lowest page, got %+v", got)
+	}
+	if got := AggregatePercentileMargins(margins, 25); got != (TrimMargins{Top: 41, Bottom: 50, Left: 30, Right: 30}) {
+		t.Errorf("p25: got %+v", got)
+	}
+	if got := AggregatePercentileMargins(nil, 25); got != (TrimMargins{}) {
+		t.Errorf("no pages: got %+v", got)
+	}
+}
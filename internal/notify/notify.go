@@ -0,0 +1,51 @@
+package notify
+
+import (
+	"fmt"
+	"os/exec"
+
+	"github.com/oumi/k2p/internal/automation"
+)
+
+// Notifier posts desktop notifications
+type Notifier interface {
+	Notify(title, message string) error
+}
+
+// MacOSNotifier posts Notification Center alerts through osascript
+type MacOSNotifier struct{}
+
+// NewNotifier creates a new Notifier instance
+func NewNotifier() Notifier {
+	return &MacOSNotifier{}
+}
+
+// Notify posts a notification; it runs in the background and never blocks
+// the conversion. The process is waited for so it does not linger as a zombie
+func (n *MacOSNotifier) Notify(title, message string) error {
+	cmd := exec.Command("osascript", "-e", notificationScript(title, message))
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to post notification: %w", err)
+	}
+	go cmd.Wait()
+	return nil
+}
+
+// notificationScript builds the AppleScript for a notification
+func notificationScript(title, message string) string {
+	return fmt.Sprintf("display notification %s with title %s",
+		automation.QuoteAppleScript(message), automation.QuoteAppleScript(title))
+}
+
+// NoOpNotifier is a notifier that does nothing (for testing)
+type NoOpNotifier struct{}
+
+// NewNoOpNotifier creates a new no-op notifier
+func NewNoOpNotifier() Notifier {
+	return &NoOpNotifier{}
+}
+
+// Notify does nothing
+func (n *NoOpNotifier) Notify(title, message string) error {
+	return nil
+}
@@ -0,0 +1,11 @@
+package notify
+
+import "testing"
+
+func TestNotificationScript(t *testing.T) {
+	got := notificationScript("k2p", `Saved "My Book" to C:\books`)
+	want := `display notification "Saved \"My Book\" to C:\\books" with title "k2p"`
+	if got != want {
+		t.Errorf("notificationScript = %s, want %s", got, want)
+	}
+}
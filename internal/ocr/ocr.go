@@ -0,0 +1,89 @@
+package ocr
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// TextRecognizer extracts text from page images
+type TextRecognizer interface {
+	// RecognizeText runs OCR on an image file and returns the recognized text
+	// Lines are returned in reading order separated by newlines
+	RecognizeText(imagePath string) (string, error)
+}
+
+// VisionRecognizer implements TextRecognizer using the macOS Vision framework
+// The Vision API is driven through JavaScript for Automation (osascript -l JavaScript)
+// so no extra binaries or cgo are required
+type VisionRecognizer struct {
+	// Languages passed to VNRecognizeTextRequest (BCP 47 codes, in priority order)
+	Languages []string
+}
+
+// NewTextRecognizer creates a new TextRecognizer instance
+func NewTextRecognizer() TextRecognizer {
+	return &VisionRecognizer{
+		Languages: []string{"ja-JP", "en-US"},
+	}
+}
+
+// visionScript recognizes text in the image given as the first argument
+// The second argument is a comma separated list of recognition languages
+const visionScript = `
+ObjC.import('Foundation');
+ObjC.import('Vision');
+
+function run(argv) {
+	const url = $.NSURL.fileURLWithPath(argv[0]);
+	const handler = $.VNImageRequestHandler.alloc.initWithURLOptions(url, $.NSDictionary.alloc.init);
+	const request = $.VNRecognizeTextRequest.alloc.init;
+	request.recognitionLevel = $.VNRequestTextRecognitionLevelAccurate;
+	request.usesLanguageCorrection = true;
+	if (argv.length > 1 && argv[1] !== '') {
+		request.recognitionLanguages = $(argv[1].split(','));
+	}
+
+	const error = $();
+	if (!handler.performRequestsError($.NSArray.arrayWithObject(request), error)) {
+		throw new Error('text recognition failed: ' + error.localizedDescription.js);
+	}
+
+	const results = request.results;
+	const lines = [];
+	for (let i = 0; i < results.count; i++) {
+		const candidates = results.objectAtIndex(i).topCandidates(1);
+		if (candidates.count > 0) {
+			lines.push(candidates.objectAtIndex(0).string.js);
+		}
+	}
+	return lines.join('\n');
+}
+`
+
+// RecognizeText runs OCR on an image file and returns the recognized text
+func (r *VisionRecognizer) RecognizeText(imagePath string) (string, error) {
+	if _, err := os.Stat(imagePath); err != nil {
+		return "", fmt.Errorf("image file not found: %s", imagePath)
+	}
+
+	cmd := r.command(imagePath)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("OCR failed: %w, stderr: %s", err, stderr.String())
+	}
+
+	return strings.TrimRight(stdout.String(), "\n"), nil
+}
+
+// command builds the osascript call: the script gets the image path and the
+// recognition languages as arguments
+func (r *VisionRecognizer) command(imagePath string) *exec.Cmd {
+	return exec.Command("osascript", "-l", "JavaScript", "-e", visionScript,
+		imagePath, strings.Join(r.Languages, ","))
+}
@@ -0,0 +1,80 @@
+package ocr
+
+import (
+	"image"
+	"image/draw"
+	"image/png"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRecognizeTextMissingImage(t *testing.T) {
+	_, err := NewTextRecognizer().RecognizeText(filepath.Join(t.TempDir(), "missing.png"))
+	if err == nil || !strings.Contains(err.Error(), "image file not found") {
+		t.Errorf("expected a missing file error, got %v", err)
+	}
+}
+
+// The image and the languages, in priority order, are the script arguments
+func TestVisionCommand(t *testing.T) {
+	r := &VisionRecognizer{Languages: []string{"ja-JP", "en-US"}}
+	args := r.command("/tmp/page 1.png").Args
+
+	want := []string{"osascript", "-l", "JavaScript", "-e", visionScript, "/tmp/page 1.png", "ja-JP,en-US"}
+	if strings.Join(args, "|") != strings.Join(want, "|") {
+		t.Errorf("unexpected arguments %q", args[len(args)-2:])
+	}
+
+	// No languages: Vision picks them itself
+	if args := (&VisionRecognizer{}).command("page.png").Args; args[len(args)-1] != "" {
+		t.Errorf("expected an empty language list, got %q", args[len(args)-1])
+	}
+}
+
+// The script reads both arguments and returns one line per observation
+func TestVisionScript(t *testing.T) {
+	for _, want := range []string{
+		"fileURLWithPath(argv[0])",
+		"argv[1].split(',')",
+		"VNRecognizeTextRequest",
+		"topCandidates(1)",
+		"lines.join('\\n')",
+	} {
+		if !strings.Contains(visionScript, want) {
+			t.Errorf("expected %q in the Vision script", want)
+		}
+	}
+}
+
+// A blank page runs through Vision on macOS and gives no text
+func TestRecognizeTextBlankPage(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test")
+	}
+	if _, err := exec.LookPath("osascript"); err != nil {
+		t.Skip("osascript not available")
+	}
+
+	path := filepath.Join(t.TempDir(), "blank.png")
+	img := image.NewGray(image.Rect(0, 0, 200, 300))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := png.Encode(f, img); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	text, err := NewTextRecognizer().RecognizeText(path)
+	if err != nil {
+		t.Fatalf("OCR failed: %v", err)
+	}
+	if text != "" {
+		t.Errorf("expected no text on a blank page, got %q", text)
+	}
+}
@@ -0,0 +1,43 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/oumi/k2p/internal/config"
+)
+
+// ensureAdvanced compares a new capture with the previous page and, while the
+// two are identical, sends the turn again and recaptures path (AdvanceRetries)
+// Kindle sometimes ignores an arrow key while it is still rendering; the page
+// is then captured twice and the following turn skips the real next page
+// A page that stays identical is kept as it is, so end detection and the stall
+// watchdog still see the repeats. Reports whether a re-sent turn moved on
+func (o *DefaultOrchestrator) ensureAdvanced(ctx context.Context, previous, path string, options *config.ConversionOptions, cache *pageCache, turn func() error) (bool, error) {
+	for retry := 0; ; retry++ {
+		similarity, err := cache.compare(previous, path)
+		if err != nil || similarity < endDetectionSimilarity {
+			return retry > 0, nil
+		}
+		if retry == options.AdvanceRetries {
+			return false, nil
+		}
+
+		if options.Verbose {
+			o.printf("\nPage did not advance (%.2f%% similar to the previous page), turning again (%d/%d)...\n",
+				similarity*100, retry+1, options.AdvanceRetries)
+		}
+		if err := turn(); err != nil {
+			return false, fmt.Errorf("failed to turn page after retries: %w", err)
+		}
+		o.waitForPage(ctx, previous, filepath.Dir(path), options, cache)
+		if err := ctx.Err(); err != nil {
+			return false, err
+		}
+		if err := o.capturer.CaptureWithoutActivation(path); err != nil {
+			return false, fmt.Errorf("failed to capture the page again: %w", err)
+		}
+		cache.forget(path)
+	}
+}
@@ -0,0 +1,63 @@
+package orchestrator
+
+import (
+	"context"
+	"image/color"
+	"io"
+	"path/filepath"
+	"testing"
+
+	"github.com/oumi/k2p/internal/config"
+)
+
+// A capture identical to the previous page sends the turn again, up to
+// AdvanceRetries times; a page that never changes is kept for end detection
+func TestEnsureAdvanced(t *testing.T) {
+	for _, tt := range []struct {
+		name         string
+		current      color.Color
+		frames       []color.Color
+		retries      int
+		wantAdvanced bool
+		wantTurns    int
+		wantColor    color.Color
+	}{
+		{"page advanced", color.Black, []color.Color{color.Black}, 2, false, 0, color.Black},
+		{"ignored turn sent again", color.White, []color.Color{color.Black}, 2, true, 1, color.Black},
+		{"second re-turn", color.White, []color.Color{color.White, color.Black}, 2, true, 2, color.Black},
+		{"end of book", color.White, []color.Color{color.White}, 2, false, 2, color.White},
+		{"off", color.White, []color.Color{color.Black}, 0, false, 0, color.White},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			previous := filepath.Join(dir, "page_0001.png")
+			path := filepath.Join(dir, "page_0002.png")
+			writePage(t, previous, color.White)
+			writePage(t, path, tt.current)
+
+			orch := &DefaultOrchestrator{capturer: framesCapturer(tt.frames...)}
+			orch.SetLogWriter(io.Discard)
+			cache := newPageCache(5)
+			turns := 0
+			advanced, err := orch.ensureAdvanced(context.Background(), previous, path, &config.ConversionOptions{AdvanceRetries: tt.retries}, cache, func() error {
+				turns++
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("ensureAdvanced failed: %v", err)
+			}
+
+			if advanced != tt.wantAdvanced || turns != tt.wantTurns {
+				t.Errorf("expected advanced=%v after %d turns, got %v after %d", tt.wantAdvanced, tt.wantTurns, advanced, turns)
+			}
+			// The cache must not hold the replaced capture
+			img, err := cache.get(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, want := color.RGBAModel.Convert(img.At(50, 50)), color.RGBAModel.Convert(tt.wantColor); got != want {
+				t.Errorf("expected the page to be %v, got %v", want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package orchestrator
+
+import (
+	"context"
+	"time"
+
+	"github.com/oumi/k2p/internal/config"
+)
+
+// NextBookFunc is called before each book after the first in a batch
+// It should wait until the user has opened the next book and return false
+// when the user signals that the batch is done
+type NextBookFunc func(bookNum int) bool
+
+// BatchEntry is the outcome of converting one book in a batch
+type BatchEntry struct {
+	// 1-based position of the book in the batch
+	BookNumber int
+
+	// Conversion result (nil if the conversion failed)
+	Result *ConversionResult
+
+	// Conversion error (nil on success)
+	Err error
+}
+
+// BatchResult contains the results of a batch conversion
+type BatchResult struct {
+	// One entry per attempted book, in order
+	Entries []BatchEntry
+
+	// Total batch duration
+	Duration time.Duration
+}
+
+// Results returns the results of all successful conversions
+func (b *BatchResult) Results() []ConversionResult {
+	var results []ConversionResult
+	for _, e := range b.Entries {
+		if e.Result != nil {
+			results = append(results, *e.Result)
+		}
+	}
+	return results
+}
+
+// ConvertBatch converts books one after another until nextBook returns false
+// or the context is cancelled. A failed book is recorded and the batch continues
+// With StateFile set, books listed in it are skipped, and every converted book
+// is added to it, so a batch can be restarted after the process died
+func (o *DefaultOrchestrator) ConvertBatch(ctx context.Context, options *config.ConversionOptions, nextBook NextBookFunc) (*BatchResult, error) {
+	startTime := time.Now()
+	batch := &BatchResult{}
+
+	state, err := loadState(options.StateFile)
+	if err != nil {
+		return nil, err
+	}
+	if len(state.Books) > 0 {
+		o.printf("State file %s: %d books already converted\n", state.path, len(state.Books))
+	}
+
+	bookOptions := *options
+	for bookNum := 1; ; bookNum++ {
+		if bookNum > 1 {
+			if !nextBook(bookNum) {
+				break
+			}
+			// The next-book prompt already served as confirmation
+			bookOptions.AutoConfirm = true
+		}
+
+		select {
+		case <-ctx.Done():
+			batch.Duration = time.Since(startTime)
+			o.printBatchSummary(batch)
+			return batch, ctx.Err()
+		default:
+		}
+
+		// Without a title the book cannot be matched against the state file
+		title, _ := o.automation.GetBookTitle()
+		if title != "" && state.done(title) {
+			o.printf("\nSkipping book %d (%s): already converted (listed in %s)\n", bookNum, title, state.path)
+			continue
+		}
+
+		o.printf("\n=== Book %d ===\n", bookNum)
+		result, err := o.ConvertCurrentBook(ctx, &bookOptions)
+		batch.Entries = append(batch.Entries, BatchEntry{BookNumber: bookNum, Result: result, Err: err})
+		if err != nil {
+			o.printf("Book %d failed: %v\n", bookNum, err)
+			if ctx.Err() != nil {
+				break
+			}
+			continue
+		}
+		if title != "" {
+			o.recordCompleted(state, title, result)
+		}
+	}
+
+	batch.Duration = time.Since(startTime)
+	o.printBatchSummary(batch)
+
+	return batch, ctx.Err()
+}
+
+// recordCompleted adds a converted book to the state file
+// Failing to save only costs the skip on restart, so it is a warning
+func (o *DefaultOrchestrator) recordCompleted(state *sessionState, title string, result *ConversionResult) {
+	if err := state.record(title, result.OutputPath); err != nil {
+		o.printf("Warning: %v\n", err)
+	}
+}
+
+// printBatchSummary prints a table with one row per book
+func (o *DefaultOrchestrator) printBatchSummary(batch *BatchResult) {
+	o.println("\n=== Batch Summary ===")
+	o.printf("  %-4s %-6s %-9s %-9s %s\n", "#", "Pages", "Size(MB)", "Duration", "Output")
+
+	succeeded := 0
+	for _, e := range batch.Entries {
+		if e.Err != nil || e.Result == nil {
+			o.printf("  %-4d FAILED: %v\n", e.BookNumber, e.Err)
+			continue
+		}
+		succeeded++
+		o.printf("  %-4d %-6d %-9.2f %-9s %s\n",
+			e.BookNumber, e.Result.PageCount,
+			float64(e.Result.FileSize)/(1024*1024),
+			e.Result.Duration.Round(time.Second), e.Result.OutputPath)
+	}
+
+	o.printf("\nBooks: %d (%d succeeded, %d failed)\n",
+		len(batch.Entries), succeeded, len(batch.Entries)-succeeded)
+	o.printf("Total duration: %s\n", batch.Duration.Round(time.Second))
+}
@@ -0,0 +1,124 @@
+package orchestrator
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+
+	"github.com/oumi/k2p/internal/config"
+)
+
+// Batch conversion: one entry per book until the user signals done
+func TestBatchConversion(t *testing.T) {
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 5
+	properties := gopter.NewProperties(parameters)
+
+	properties.Property("Batch converts until next-book prompt declines", prop.ForAll(
+		func(books int) bool {
+			orch := newTestOrchestrator(t, &MockCapturer{})
+			opts := generateOptions()
+			opts.StateFile = filepath.Join(t.TempDir(), "state.json")
+
+			var prompted []int
+			batch, _ := orch.ConvertBatch(context.Background(), opts, func(bookNum int) bool {
+				prompted = append(prompted, bookNum)
+				return bookNum <= books
+			})
+
+			return batch != nil &&
+				len(batch.Entries) == books &&
+				len(batch.Results()) == books &&
+				len(prompted) == books // asked before books 2..n and once more to finish
+		},
+		gen.IntRange(1, 3),
+	))
+
+	properties.TestingRun(t)
+}
+
+// State file: converted titles are recorded and skipped after a restart
+func TestBatchStateFile(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "state.json")
+	logTo := io.Discard
+	run := func(titles []string, books int) *BatchResult {
+		orch := newTestOrchestrator(t, &MockCapturer{})
+		orch.SetLogWriter(logTo)
+		orch.automation.(*MockAutomation).Titles = titles
+		opts := generateOptions()
+		opts.StateFile = stateFile
+		batch, err := orch.ConvertBatch(context.Background(), opts, func(bookNum int) bool { return bookNum <= books })
+		if err != nil {
+			t.Fatalf("batch failed: %v", err)
+		}
+		return batch
+	}
+
+	if batch := run([]string{"Book One", "Book Two"}, 2); len(batch.Results()) != 2 {
+		t.Fatalf("expected 2 converted books, got %d", len(batch.Results()))
+	}
+
+	// Restart: the two finished books are skipped, only the new one is converted
+	var log bytes.Buffer
+	logTo = &log
+	if batch := run([]string{"Book One", "Book Two", "Book Three"}, 3); len(batch.Results()) != 1 {
+		t.Errorf("expected 1 converted book after restart, got %d", len(batch.Results()))
+	}
+	if !strings.Contains(log.String(), "Skipping book 2 (Book Two): already converted (listed in "+stateFile+")") {
+		t.Errorf("expected the skipped books in the log, got:\n%s", log.String())
+	}
+
+	state, err := loadState(stateFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var titles []string
+	for _, b := range state.Books {
+		titles = append(titles, b.Title)
+		if b.OutputPath == "" || b.CompletedAt.IsZero() {
+			t.Errorf("incomplete state entry: %+v", b)
+		}
+	}
+	if strings.Join(titles, ",") != "Book One,Book Two,Book Three" {
+		t.Errorf("unexpected state titles: %v", titles)
+	}
+
+	// A damaged state file stops the batch instead of starting over
+	if err := os.WriteFile(stateFile, []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	orch := &DefaultOrchestrator{automation: &MockAutomation{}}
+	orch.SetLogWriter(io.Discard)
+	if _, err := orch.ConvertBatch(context.Background(), &config.ConversionOptions{StateFile: stateFile}, nil); err == nil {
+		t.Error("expected error for damaged state file")
+	}
+}
+
+// Without StateFile nothing is skipped or recorded
+func TestBatchStateFileOff(t *testing.T) {
+	outputDir := t.TempDir()
+	for run := 1; run <= 2; run++ {
+		orch := newTestOrchestrator(t, &MockCapturer{})
+		orch.automation.(*MockAutomation).Titles = []string{"Book One", "Book Two"}
+		opts := generateOptions()
+		opts.OutputDir = outputDir
+		batch, err := orch.ConvertBatch(context.Background(), opts, func(bookNum int) bool { return bookNum <= 2 })
+		if err != nil {
+			t.Fatalf("batch failed: %v", err)
+		}
+		if len(batch.Results()) != 2 {
+			t.Errorf("run %d: expected 2 converted books, got %d", run, len(batch.Results()))
+		}
+		if _, err := os.Stat(filepath.Join(outputDir, StateFileName)); !os.IsNotExist(err) {
+			t.Errorf("run %d: expected no state file, got %v", run, err)
+		}
+	}
+}
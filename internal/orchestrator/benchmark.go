@@ -0,0 +1,117 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/oumi/k2p/internal/config"
+	"github.com/oumi/k2p/internal/imageprocessing"
+	"github.com/oumi/k2p/internal/screenshot"
+)
+
+// benchmarkDelays are the page delays tried in benchmark mode, slowest first
+var benchmarkDelays = []time.Duration{
+	1000 * time.Millisecond,
+	900 * time.Millisecond,
+	800 * time.Millisecond,
+	700 * time.Millisecond,
+	600 * time.Millisecond,
+	500 * time.Millisecond,
+	400 * time.Millisecond,
+	300 * time.Millisecond,
+	200 * time.Millisecond,
+}
+
+// benchmarkSafetyMargin is added to the lowest reliable delay for the recommendation
+const benchmarkSafetyMargin = 100 * time.Millisecond
+
+// BenchmarkStep is the outcome of one page turn in benchmark mode
+type BenchmarkStep struct {
+	// Delay between the page turn and the capture
+	Delay time.Duration
+
+	// Similarity to the previous capture (0.0 to 1.0)
+	Similarity float64
+
+	// Whether the page turn was visible in the capture
+	Registered bool
+}
+
+// benchmarkPageDelay turns pages with decreasing delays and checks whether
+// each turn shows up in the following capture
+// Returns the lowest delay for which it and every slower delay registered
+func (o *DefaultOrchestrator) benchmarkPageDelay(ctx context.Context, tempDir string, options *config.ConversionOptions) (time.Duration, []BenchmarkStep, error) {
+	retryConfig := DefaultRetryConfig()
+	ext := screenshot.FileExtension(options.ScreenshotQuality, options.CaptureFormat)
+
+	direction := config.NormalizePageTurnKey(options.PageTurnKey)
+	if direction == "" || direction == "auto" {
+		detected, _, err := o.detectPageTurnDirection(ctx, tempDir, retryConfig, options)
+		if err != nil {
+			return 0, nil, err
+		}
+		direction = detected
+	}
+
+	o.printf("\nBenchmarking page delay (%s arrow, %d pages)...\n", direction, len(benchmarkDelays)+1)
+
+	prevPath := filepath.Join(tempDir, "bench_0000"+ext)
+	if err := RetryWithBackoff(ctx, retryConfig, func() error {
+		return o.capturer.CaptureFrontmostWindow(prevPath)
+	}); err != nil {
+		return 0, nil, fmt.Errorf("failed to capture starting page: %w", err)
+	}
+
+	var steps []BenchmarkStep
+	var lowest time.Duration
+	for i, delay := range benchmarkDelays {
+		select {
+		case <-ctx.Done():
+			return 0, steps, ctx.Err()
+		default:
+		}
+
+		if err := RetryWithBackoff(ctx, retryConfig, func() error {
+			return o.automation.TurnNextPage(direction)
+		}); err != nil {
+			return 0, steps, fmt.Errorf("failed to turn page: %w", err)
+		}
+		time.Sleep(delay)
+
+		path := filepath.Join(tempDir, fmt.Sprintf("bench_%04d%s", i+1, ext))
+		if err := RetryWithBackoff(ctx, retryConfig, func() error {
+			return o.capturer.CaptureWithoutActivation(path)
+		}); err != nil {
+			return 0, steps, fmt.Errorf("failed to capture page at %s: %w", delay, err)
+		}
+
+		similarity, err := imageprocessing.CompareFiles(comparer(options), prevPath, path, diffRegion(options))
+		if err != nil {
+			return 0, steps, fmt.Errorf("failed to compare pages: %w", err)
+		}
+
+		// Same threshold as direction detection
+		step := BenchmarkStep{Delay: delay, Similarity: similarity, Registered: similarity < directionChangeThreshold(options)}
+		steps = append(steps, step)
+
+		status := "OK"
+		if !step.Registered {
+			status = "NOT REGISTERED"
+		}
+		o.printf("  %4dms: %.2f%% similarity - %s\n", delay.Milliseconds(), similarity*100, status)
+
+		if !step.Registered {
+			break
+		}
+		lowest = delay
+		prevPath = path
+	}
+
+	if lowest == 0 {
+		return 0, steps, fmt.Errorf("page turn did not register even with %s delay (is the book at its last page?)", benchmarkDelays[0])
+	}
+
+	return lowest, steps, nil
+}
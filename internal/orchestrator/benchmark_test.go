@@ -0,0 +1,42 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/oumi/k2p/internal/config"
+	"github.com/oumi/k2p/internal/screenshot"
+)
+
+func TestBenchmarkPageDelay(t *testing.T) {
+	saved := benchmarkDelays
+	defer func() { benchmarkDelays = saved }()
+	benchmarkDelays = []time.Duration{30 * time.Millisecond, 20 * time.Millisecond, 10 * time.Millisecond}
+
+	newOrch := func(capturer screenshot.Capturer) *DefaultOrchestrator {
+		orch := newTestOrchestrator(t, capturer)
+		orch.pdfGen = &MockPDFGenerator{GenerateError: fmt.Errorf("PDF must not be generated")}
+		return orch
+	}
+	opts := &config.ConversionOptions{
+		AutoConfirm: true,
+		Mode:        "benchmark",
+		PageTurnKey: "right",
+	}
+
+	// Start page and the 30ms page are distinct, the 20ms page is the first blank
+	// page (still a change), and the 10ms capture repeats it
+	result, err := newOrch(sequenceCapturer(2)).ConvertCurrentBook(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("benchmark failed: %v", err)
+	}
+	if want := 20*time.Millisecond + benchmarkSafetyMargin; result.RecommendedPageDelay != want {
+		t.Errorf("expected recommended delay %s, got %s", want, result.RecommendedPageDelay)
+	}
+
+	if _, err := newOrch(&MockCapturer{}).ConvertCurrentBook(context.Background(), opts); err == nil {
+		t.Error("expected error when no page turn registers")
+	}
+}
@@ -0,0 +1,39 @@
+package orchestrator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/oumi/k2p/internal/config"
+	"github.com/oumi/k2p/internal/imageprocessing"
+)
+
+// encodeBMPPages stores pages still in the BMP capture format as PNG next to
+// them; trimmed or rotated pages are PNG already and are returned unchanged
+// A page that fails to convert is kept as is and fails in the writer instead
+func (o *DefaultOrchestrator) encodeBMPPages(screenshots []string, options *config.ConversionOptions, issues *pageIssues) []string {
+	encoded := make([]string, len(screenshots))
+	forEachPage(len(screenshots), options.MaxConcurrency, func(i int) {
+		encoded[i] = screenshots[i]
+		if !strings.EqualFold(filepath.Ext(screenshots[i]), ".bmp") {
+			return
+		}
+
+		pngPath := strings.TrimSuffix(screenshots[i], filepath.Ext(screenshots[i])) + ".png"
+		img, err := imageprocessing.LoadImage(screenshots[i])
+		if err == nil {
+			err = imageprocessing.SavePNG(img, pngPath)
+		}
+		if err != nil {
+			issues.add(issueBMPEncode)
+			if options.Verbose {
+				o.printf("  Warning: Failed to encode page %d as PNG: %v\n", i+1, err)
+			}
+			return
+		}
+		encoded[i] = pngPath
+		os.Remove(screenshots[i])
+	})
+	return encoded
+}
@@ -0,0 +1,29 @@
+package orchestrator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/oumi/k2p/internal/pdf"
+	"github.com/oumi/k2p/internal/screenshot"
+)
+
+// BMP captures: end detection decodes them and the PDF gets PNG pages
+func TestCaptureFormatBMP(t *testing.T) {
+	orch := newTestOrchestrator(t, screenshot.NewSyntheticCapturer(6))
+	orch.pdfGen = pdf.NewPDFGenerator()
+	opts := generateOptions()
+	opts.CaptureFormat = "bmp"
+
+	result, err := orch.ConvertCurrentBook(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("conversion with BMP captures failed: %v", err)
+	}
+	count, err := pdf.PageCount(result.OutputPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.PageCount == 0 || count != result.PageCount {
+		t.Errorf("expected all %d captured pages in the PDF, got %d", result.PageCount, count)
+	}
+}
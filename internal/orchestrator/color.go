@@ -0,0 +1,59 @@
+package orchestrator
+
+import (
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+
+	"github.com/oumi/k2p/internal/config"
+	"github.com/oumi/k2p/internal/imageprocessing"
+)
+
+// colorAdjustments returns the enabled per-page colour adjustments in the
+// order they are applied: Invert first, so a dark-mode page is an ordinary
+// page for everything after it, then NormalizePaper
+func colorAdjustments(options *config.ConversionOptions) []func(image.Image) image.Image {
+	var adjust []func(image.Image) image.Image
+	if options.Invert {
+		adjust = append(adjust, imageprocessing.Invert)
+	}
+	if options.NormalizePaper {
+		adjust = append(adjust, imageprocessing.NormalizePaper)
+	}
+	return adjust
+}
+
+// adjustColors applies the enabled colour adjustments to every page, decoding
+// and encoding each page once; a page that fails keeps its original
+func (o *DefaultOrchestrator) adjustColors(screenshots []string, tempDir string, options *config.ConversionOptions, issues *pageIssues) []string {
+	adjust := colorAdjustments(options)
+	if len(adjust) == 0 {
+		return screenshots
+	}
+	if options.Verbose {
+		o.printf("\nAdjusting colours of %d pages...\n", len(screenshots))
+	}
+
+	adjusted := make([]string, len(screenshots))
+	forEachPage(len(screenshots), options.MaxConcurrency, func(i int) {
+		adjusted[i] = screenshots[i]
+		img, err := imageprocessing.LoadImage(screenshots[i])
+		if err == nil {
+			for _, fn := range adjust {
+				img = fn(img)
+			}
+			outputPath := filepath.Join(tempDir, fmt.Sprintf("page_%04d_adjusted.png", i+1))
+			if err = imageprocessing.SavePNG(img, outputPath); err == nil {
+				adjusted[i] = outputPath
+				os.Remove(screenshots[i])
+				return
+			}
+		}
+		issues.add(issueColors)
+		if options.Verbose {
+			o.printf("  Warning: Failed to adjust colours of page %d, using original: %v\n", i+1, err)
+		}
+	})
+	return adjusted
+}
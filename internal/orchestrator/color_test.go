@@ -0,0 +1,83 @@
+package orchestrator
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"image/draw"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/oumi/k2p/internal/config"
+	"github.com/oumi/k2p/internal/imageprocessing"
+)
+
+// darkModeCapturer captures pages of paper colour with light text that
+// moves from page to page, then identical end-of-book pages
+func darkModeCapturer(paper color.Color) *pageCapturer {
+	text := image.NewUniform(color.Gray{Y: 220})
+	return &pageCapturer{Page: func(n int) image.Image {
+		img := uniformPage(paper).(*image.RGBA)
+		x := 20 * min(n, 4)
+		draw.Draw(img, image.Rect(x, 30, x+15, 70), text, image.Point{}, draw.Src)
+		return img
+	}}
+}
+
+// adjustedPageColors runs a conversion of dark-mode pages of paper colour
+// and returns the background colour of every page that reaches the PDF
+// generator
+func adjustedPageColors(t *testing.T, paper color.Color, opts *config.ConversionOptions) []color.RGBA {
+	t.Helper()
+	orch := newTestOrchestrator(t, darkModeCapturer(paper))
+	var colors []color.RGBA
+	orch.pdfGen = &inspectingPDFGenerator{MockPDFGenerator: &MockPDFGenerator{}, inspect: func(path string) {
+		if !strings.HasSuffix(path, "_adjusted.png") {
+			t.Errorf("expected an adjusted page, got %s", filepath.Base(path))
+		}
+		img, err := imageprocessing.LoadImage(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		colors = append(colors, color.RGBAModel.Convert(img.At(5, 5)).(color.RGBA))
+	}}
+	opts.AllowBlackFirstPage = true
+
+	if _, err := orch.ConvertCurrentBook(context.Background(), opts); err != nil {
+		t.Fatalf("conversion failed: %v", err)
+	}
+	if len(colors) == 0 {
+		t.Fatal("expected pages")
+	}
+	return colors
+}
+
+// Invert turns a dark-mode page light
+func TestInvertPages(t *testing.T) {
+	opts := generateOptions()
+	opts.Invert = true
+
+	want := color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	for i, got := range adjustedPageColors(t, color.Black, opts) {
+		if got != want {
+			t.Errorf("page %d: expected the black paper to become %v, got %v", i+1, want, got)
+		}
+	}
+}
+
+// Invert runs before NormalizePaper: a tinted dark-mode page is inverted to
+// tinted paper, which is then normalized to white. The other way round the
+// dark page is not recognised as paper and stays tinted after inverting
+func TestInvertBeforeNormalizePaper(t *testing.T) {
+	opts := generateOptions()
+	opts.Invert = true
+	opts.NormalizePaper = true
+
+	want := color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	for i, got := range adjustedPageColors(t, color.RGBA{R: 20, G: 25, B: 40, A: 255}, opts) {
+		if got != want {
+			t.Errorf("page %d: expected the tinted dark page to become %v, got %v", i+1, want, got)
+		}
+	}
+}
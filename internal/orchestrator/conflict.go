@@ -0,0 +1,29 @@
+package orchestrator
+
+import (
+	"github.com/oumi/k2p/internal/config"
+)
+
+// claimOutput applies OnConflict to an output path that may already exist
+// "ask" confirms the overwrite through HandleExistingFile (AutoConfirm
+// answers yes), "overwrite" replaces the file without asking and "version"
+// keeps it and picks the next free name (book_1.pdf, book_2.pdf, ...)
+// Returns the path to write, or false when the user declined the overwrite
+func (o *DefaultOrchestrator) claimOutput(outputPath string, options *config.ConversionOptions) (string, bool, error) {
+	switch options.OnConflict {
+	case "overwrite":
+		return outputPath, true, nil
+	case "version":
+		path := availablePath(outputPath)
+		if path != outputPath {
+			o.printf("%s already exists; writing %s\n", outputPath, path)
+		}
+		return path, true, nil
+	}
+
+	proceed, err := o.fileManager.HandleExistingFile(outputPath, options.AutoConfirm)
+	if err != nil || !proceed {
+		return "", false, err
+	}
+	return outputPath, true, nil
+}
@@ -0,0 +1,43 @@
+package orchestrator
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/oumi/k2p/internal/config"
+)
+
+// OnConflict: an existing output is confirmed, replaced or kept beside a new version
+func TestClaimOutput(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "book.pdf")
+	for _, name := range []string{"book.pdf", "book_1.pdf"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("%PDF"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, tt := range []struct {
+		policy      string
+		confirm     bool
+		wantPath    string
+		wantProceed bool
+	}{
+		{"", false, "", false},
+		{"ask", true, existing, true},
+		{"overwrite", false, existing, true},
+		{"version", false, filepath.Join(dir, "book_2.pdf"), true},
+	} {
+		orch := &DefaultOrchestrator{fileManager: &MockFileManager{HandleExists: tt.confirm}}
+		orch.SetLogWriter(io.Discard)
+		path, proceed, err := orch.claimOutput(existing, &config.ConversionOptions{OnConflict: tt.policy})
+		if err != nil {
+			t.Fatalf("%q: %v", tt.policy, err)
+		}
+		if path != tt.wantPath || proceed != tt.wantProceed {
+			t.Errorf("%q: expected %q (proceed %v), got %q (proceed %v)", tt.policy, tt.wantPath, tt.wantProceed, path, proceed)
+		}
+	}
+}
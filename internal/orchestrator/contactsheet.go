@@ -0,0 +1,45 @@
+package orchestrator
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/oumi/k2p/internal/config"
+	"github.com/oumi/k2p/internal/imageprocessing"
+)
+
+// contactSheetThumbnails writes a ContactSheetThumbSize thumbnail of every
+// page, so the contact sheet PDF embeds small images instead of captures
+// A page that fails to scale is placed at full size
+func (o *DefaultOrchestrator) contactSheetThumbnails(screenshots []string, tempDir string, options *config.ConversionOptions, issues *pageIssues) []string {
+	if options.Verbose {
+		o.printf("\nScaling %d pages to %dpx thumbnails...\n", len(screenshots), options.ContactSheetThumbSize)
+	}
+	thumbnails := make([]string, len(screenshots))
+	forEachPage(len(screenshots), options.MaxConcurrency, func(i int) {
+		thumbnails[i] = screenshots[i]
+		thumbPath := filepath.Join(tempDir, fmt.Sprintf("page_%04d_thumb.png", i+1))
+		if err := imageprocessing.ThumbnailFile(screenshots[i], thumbPath, options.ContactSheetThumbSize); err != nil {
+			issues.add(issueThumbnail)
+			if options.Verbose {
+				o.printf("  Warning: Failed to scale page %d, using it at full size: %v\n", i+1, err)
+			}
+			return
+		}
+		thumbnails[i] = thumbPath
+	})
+	return thumbnails
+}
+
+// contactSheetLabels captions the thumbnails with their book page numbers:
+// with PageStep every capture is that many pages after the previous one
+func contactSheetLabels(n, pageStep int) []string {
+	if pageStep < 1 {
+		pageStep = 1
+	}
+	labels := make([]string, n)
+	for i := range labels {
+		labels[i] = fmt.Sprintf("p. %d", i*pageStep+1)
+	}
+	return labels
+}
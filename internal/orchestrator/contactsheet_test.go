@@ -0,0 +1,54 @@
+package orchestrator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/oumi/k2p/internal/imageprocessing"
+	"github.com/oumi/k2p/internal/pdf"
+	"github.com/oumi/k2p/internal/screenshot"
+)
+
+type sizeRecordingPDFGenerator struct {
+	MockPDFGenerator
+	MaxSide int
+}
+
+func (g *sizeRecordingPDFGenerator) CreatePDF(imageFiles []string, outputPath string, options pdf.PDFOptions) error {
+	for _, path := range imageFiles {
+		if img, err := imageprocessing.LoadImage(path); err == nil {
+			g.MaxSide = max(g.MaxSide, img.Bounds().Dx(), img.Bounds().Dy())
+		}
+	}
+	return g.MockPDFGenerator.CreatePDF(imageFiles, outputPath, options)
+}
+
+// Contact sheet mode renders thumbnails captioned with book page numbers
+func TestContactSheetMode(t *testing.T) {
+	pdfGen := &sizeRecordingPDFGenerator{}
+	orch := newTestOrchestrator(t, screenshot.NewSyntheticCapturer(6))
+	orch.pdfGen = pdfGen
+	opts := generateOptions()
+	opts.Mode = "contact-sheet"
+	opts.PageStep = 2
+	opts.ContactSheetColumns = 3
+	opts.ContactSheetThumbSize = 100
+
+	result, err := orch.ConvertCurrentBook(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("conversion failed: %v", err)
+	}
+
+	if pdfGen.Options.ContactSheetColumns != 3 {
+		t.Errorf("expected 3 contact sheet columns, got %d", pdfGen.Options.ContactSheetColumns)
+	}
+	if len(pdfGen.ImageFiles) != result.PageCount || len(pdfGen.Options.PageLabels) != result.PageCount {
+		t.Fatalf("expected %d thumbnails and labels, got %d and %v", result.PageCount, len(pdfGen.ImageFiles), pdfGen.Options.PageLabels)
+	}
+	if got := pdfGen.Options.PageLabels[1]; got != "p. 3" {
+		t.Errorf("expected the second capture to be labelled p. 3, got %q", got)
+	}
+	if pdfGen.MaxSide == 0 || pdfGen.MaxSide > 100 {
+		t.Errorf("expected thumbnails within 100px, largest side %d", pdfGen.MaxSide)
+	}
+}
@@ -3,35 +3,94 @@ package orchestrator
 import (
 	"context"
 	"fmt"
+	"image"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"time"
 
 	"github.com/oumi/k2p/internal/config"
 	"github.com/oumi/k2p/internal/imageprocessing"
+	"github.com/oumi/k2p/internal/screenshot"
 )
 
+// diffRegion returns the screenshot region compared by detection
+// An empty rectangle compares whole screenshots; the option is validated up front
+func diffRegion(options *config.ConversionOptions) image.Rectangle {
+	region, _ := config.ParseDiffRegion(options.DiffRegion)
+	return region
+}
+
+// pageNumberRegion returns the page indicator region watched for the end of
+// the book (empty: off); the option is validated up front
+func pageNumberRegion(options *config.ConversionOptions) image.Rectangle {
+	region, _ := config.ParseDiffRegion(options.PageNumberRegion)
+	return region
+}
+
+// defaultPageNumberStallTurns is used when PageNumberStallTurns is unset
+const defaultPageNumberStallTurns = 3
+
+// pageNumberStallTurns returns how many turns in a row the page indicator
+// must stay the same to end the book
+func pageNumberStallTurns(options *config.ConversionOptions) int {
+	if options.PageNumberStallTurns > 0 {
+		return options.PageNumberStallTurns
+	}
+	return defaultPageNumberStallTurns
+}
+
+// comparer returns the similarity metric selected by SimilarityAlgorithm at
+// SimilaritySize
+// Falls back to the pixel metric; the option is validated up front
+func comparer(options *config.ConversionOptions) imageprocessing.Comparer {
+	c, err := imageprocessing.NewSizedComparer(options.SimilarityAlgorithm, options.SimilaritySize)
+	if err != nil {
+		return imageprocessing.PixelComparer{Size: options.SimilaritySize}
+	}
+	return c
+}
+
+// defaultDirectionChangeThreshold is used when DirectionChangeThreshold is unset
+const defaultDirectionChangeThreshold = 0.90
+
+// directionChangeThreshold returns the similarity below which two captures
+// count as different pages during direction detection and benchmarking
+func directionChangeThreshold(options *config.ConversionOptions) float64 {
+	if options.DirectionChangeThreshold > 0 {
+		return options.DirectionChangeThreshold
+	}
+	return defaultDirectionChangeThreshold
+}
+
 // detectPageTurnDirection tries to auto-detect the correct page turn direction
 // by capturing multiple pages and checking if content changes
 // Returns: direction string, captured image paths, error
 func (o *DefaultOrchestrator) detectPageTurnDirection(ctx context.Context, tempDir string, retryConfig RetryConfig, options *config.ConversionOptions) (string, []string, error) {
+	changeThreshold := directionChangeThreshold(options)
+	region := diffRegion(options)
+	metric := comparer(options)
 	if options.Verbose {
-		fmt.Println("Auto-detecting page turn direction...")
+		o.println("Auto-detecting page turn direction...")
+		o.printf("  Pages count as changed below %.1f%% similarity\n", changeThreshold*100)
 	}
 
-	// Create debug directory in project
-	debugDir := filepath.Join("debug_samples")
-	os.MkdirAll(debugDir, 0755)
+	// Verbose runs keep a copy of each detection capture next to the pages
+	debugDir := ""
 	if options.Verbose {
-		fmt.Printf("  DEBUG: Screenshots will be saved to: %s\n\n", debugDir)
+		debugDir = filepath.Join(tempDir, "debug_samples")
+		if err := os.MkdirAll(debugDir, 0755); err != nil {
+			debugDir = ""
+		} else {
+			o.printf("  DEBUG: Screenshots will be saved to: %s\n\n", debugDir)
+		}
 	}
 
+	ext := screenshot.FileExtension(options.ScreenshotQuality, options.CaptureFormat)
+
 	// Step 1: Capture cover page (activate Kindle once)
-	coverPath := filepath.Join(tempDir, "detect_cover.png")
-	coverDebugPath := filepath.Join(debugDir, "detect_cover.png")
+	coverPath := filepath.Join(tempDir, "detect_cover"+ext)
 	if options.Verbose {
-		fmt.Println("  [Cover] Activating Kindle and capturing cover page...")
+		o.println("  [Cover] Activating Kindle and capturing cover page...")
 	}
 	err := RetryWithBackoff(ctx, retryConfig, func() error {
 		return o.capturer.CaptureFrontmostWindow(coverPath)
@@ -39,22 +98,23 @@ func (o *DefaultOrchestrator) detectPageTurnDirection(ctx context.Context, tempD
 	if err != nil {
 		return "", nil, fmt.Errorf("failed to capture cover: %w", err)
 	}
-	// Copy to debug directory
-	exec.Command("cp", coverPath, coverDebugPath).Run()
+	if err := checkFirstCapture(coverPath, options.AllowBlackFirstPage); err != nil {
+		return "", nil, err
+	}
+	o.saveDebugSample(debugDir, coverPath, "Cover")
 	if options.Verbose {
-		fmt.Printf("  [Cover] Saved: %s\n", coverDebugPath)
-		fmt.Println("  [Cover] Kindle is now active, using fast capture for detection...")
+		o.println("  [Cover] Kindle is now active, using fast capture for detection...")
 	}
 
 	// Step 2: Test RIGHT arrow - press 3 times
 	if options.Verbose {
-		fmt.Println("\n  Testing RIGHT arrow (3 presses)...")
+		o.println("\n  Testing RIGHT arrow (3 presses)...")
 	}
 	rightPaths := []string{coverPath} // Start with cover
 	for i := 1; i <= 3; i++ {
 		// Press right arrow
 		if options.Verbose {
-			fmt.Printf("  [Right %d] Pressing RIGHT arrow...\n", i)
+			o.printf("  [Right %d] Pressing RIGHT arrow...\n", i)
 		}
 		err = RetryWithBackoff(ctx, retryConfig, func() error {
 			return o.automation.TurnNextPage("right")
@@ -67,10 +127,9 @@ func (o *DefaultOrchestrator) detectPageTurnDirection(ctx context.Context, tempD
 		time.Sleep(options.PageDelay)
 
 		// Capture screenshot (fast - no activation)
-		rightPath := filepath.Join(tempDir, fmt.Sprintf("detect_right_%d.png", i))
-		rightDebugPath := filepath.Join(debugDir, fmt.Sprintf("detect_right_%d.png", i))
+		rightPath := filepath.Join(tempDir, fmt.Sprintf("detect_right_%d%s", i, ext))
 		if options.Verbose {
-			fmt.Printf("  [Right %d] Capturing screenshot...\n", i)
+			o.printf("  [Right %d] Capturing screenshot...\n", i)
 		}
 		err = RetryWithBackoff(ctx, retryConfig, func() error {
 			return o.capturer.CaptureWithoutActivation(rightPath)
@@ -80,34 +139,30 @@ func (o *DefaultOrchestrator) detectPageTurnDirection(ctx context.Context, tempD
 		}
 		// NOTE: Detection images are NOT trimmed - they're only for comparison
 		// Trimming them would cause false end-of-book detection
-		// Copy to debug directory
-		exec.Command("cp", rightPath, rightDebugPath).Run()
-		if options.Verbose {
-			fmt.Printf("  [Right %d] Saved: %s\n", i, rightDebugPath)
-		}
+		o.saveDebugSample(debugDir, rightPath, fmt.Sprintf("Right %d", i))
 		rightPaths = append(rightPaths, rightPath)
 	}
 
 	// Check if RIGHT changed pages
 	rightChanged := false
 	if options.Verbose {
-		fmt.Println("\n  Checking if RIGHT arrow changed pages...")
+		o.println("\n  Checking if RIGHT arrow changed pages...")
 	}
 	for i := 1; i < len(rightPaths); i++ {
-		similarity, err := imageprocessing.CompareImages(rightPaths[i-1], rightPaths[i])
+		similarity, err := imageprocessing.CompareFiles(metric, rightPaths[i-1], rightPaths[i], region)
 		if err != nil && options.Verbose {
-			fmt.Printf("  Warning: Failed to compare images: %v\n", err)
+			o.printf("  Warning: Failed to compare images: %v\n", err)
 		}
 		if options.Verbose {
-			fmt.Printf("  Compare %s vs %s: %.2f%% similarity\n",
+			o.printf("  Compare %s vs %s: %.2f%% similarity\n",
 				filepath.Base(rightPaths[i-1]),
 				filepath.Base(rightPaths[i]),
 				similarity*100)
 		}
-		if similarity < 0.90 {
+		if similarity < changeThreshold {
 			rightChanged = true
 			if options.Verbose {
-				fmt.Println("  → Pages CHANGED!")
+				o.println("  → Pages CHANGED!")
 			}
 			break
 		}
@@ -115,8 +170,8 @@ func (o *DefaultOrchestrator) detectPageTurnDirection(ctx context.Context, tempD
 
 	if rightChanged {
 		if options.Verbose {
-			fmt.Println("\n✓ Direction detected: RIGHT arrow")
-			fmt.Println("  Continuing from current page...")
+			o.println("\n✓ Direction detected: RIGHT arrow")
+			o.println("  Continuing from current page...")
 		}
 		// Return right direction and the captured images
 		return "right", rightPaths, nil
@@ -124,8 +179,8 @@ func (o *DefaultOrchestrator) detectPageTurnDirection(ctx context.Context, tempD
 
 	// Step 3: RIGHT didn't work, test LEFT arrow
 	if options.Verbose {
-		fmt.Println("\n  RIGHT arrow didn't change pages.")
-		fmt.Println("  Testing LEFT arrow (3 presses)...")
+		o.println("\n  RIGHT arrow didn't change pages.")
+		o.println("  Testing LEFT arrow (3 presses)...")
 	}
 
 	// We're currently at the same position (cover), so start from there
@@ -133,7 +188,7 @@ func (o *DefaultOrchestrator) detectPageTurnDirection(ctx context.Context, tempD
 	for i := 1; i <= 3; i++ {
 		// Press left arrow
 		if options.Verbose {
-			fmt.Printf("  [Left %d] Pressing LEFT arrow...\n", i)
+			o.printf("  [Left %d] Pressing LEFT arrow...\n", i)
 		}
 		err = RetryWithBackoff(ctx, retryConfig, func() error {
 			return o.automation.TurnNextPage("left")
@@ -146,10 +201,9 @@ func (o *DefaultOrchestrator) detectPageTurnDirection(ctx context.Context, tempD
 		time.Sleep(options.PageDelay)
 
 		// Capture screenshot (fast - no activation)
-		leftPath := filepath.Join(tempDir, fmt.Sprintf("detect_left_%d.png", i))
-		leftDebugPath := filepath.Join(debugDir, fmt.Sprintf("detect_left_%d.png", i))
+		leftPath := filepath.Join(tempDir, fmt.Sprintf("detect_left_%d%s", i, ext))
 		if options.Verbose {
-			fmt.Printf("  [Left %d] Capturing screenshot...\n", i)
+			o.printf("  [Left %d] Capturing screenshot...\n", i)
 		}
 		err = RetryWithBackoff(ctx, retryConfig, func() error {
 			return o.capturer.CaptureWithoutActivation(leftPath)
@@ -159,34 +213,30 @@ func (o *DefaultOrchestrator) detectPageTurnDirection(ctx context.Context, tempD
 		}
 		// NOTE: Detection images are NOT trimmed - they're only for comparison
 		// Trimming them would cause false end-of-book detection
-		// Copy to debug directory
-		exec.Command("cp", leftPath, leftDebugPath).Run()
-		if options.Verbose {
-			fmt.Printf("  [Left %d] Saved: %s\n", i, leftDebugPath)
-		}
+		o.saveDebugSample(debugDir, leftPath, fmt.Sprintf("Left %d", i))
 		leftPaths = append(leftPaths, leftPath)
 	}
 
 	// Check if LEFT changed pages
 	leftChanged := false
 	if options.Verbose {
-		fmt.Println("\n  Checking if LEFT arrow changed pages...")
+		o.println("\n  Checking if LEFT arrow changed pages...")
 	}
 	for i := 1; i < len(leftPaths); i++ {
-		similarity, err := imageprocessing.CompareImages(leftPaths[i-1], leftPaths[i])
+		similarity, err := imageprocessing.CompareFiles(metric, leftPaths[i-1], leftPaths[i], region)
 		if err != nil && options.Verbose {
-			fmt.Printf("  Warning: Failed to compare images: %v\n", err)
+			o.printf("  Warning: Failed to compare images: %v\n", err)
 		}
 		if options.Verbose {
-			fmt.Printf("  Compare %s vs %s: %.2f%% similarity\n",
+			o.printf("  Compare %s vs %s: %.2f%% similarity\n",
 				filepath.Base(leftPaths[i-1]),
 				filepath.Base(leftPaths[i]),
 				similarity*100)
 		}
-		if similarity < 0.90 {
+		if similarity < changeThreshold {
 			leftChanged = true
 			if options.Verbose {
-				fmt.Println("  → Pages CHANGED!")
+				o.println("  → Pages CHANGED!")
 			}
 			break
 		}
@@ -194,8 +244,8 @@ func (o *DefaultOrchestrator) detectPageTurnDirection(ctx context.Context, tempD
 
 	if leftChanged {
 		if options.Verbose {
-			fmt.Println("\n✓ Direction detected: LEFT arrow")
-			fmt.Println("  Continuing from current page...")
+			o.println("\n✓ Direction detected: LEFT arrow")
+			o.println("  Continuing from current page...")
 		}
 		// Return left direction and the captured images
 		return "left", leftPaths, nil
@@ -204,3 +254,20 @@ func (o *DefaultOrchestrator) detectPageTurnDirection(ctx context.Context, tempD
 	// Neither direction worked - ERROR
 	return "", nil, fmt.Errorf("could not detect page turn direction: neither RIGHT nor LEFT arrow changed pages")
 }
+
+// saveDebugSample copies a detection capture into debugDir (verbose runs only;
+// empty debugDir: off). The copy lives in the temp directory, so nothing is
+// written into the working directory
+func (o *DefaultOrchestrator) saveDebugSample(debugDir, path, label string) {
+	if debugDir == "" {
+		return
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	debugPath := filepath.Join(debugDir, filepath.Base(path))
+	if err := os.WriteFile(debugPath, data, 0644); err == nil {
+		o.printf("  [%s] Saved: %s\n", label, debugPath)
+	}
+}
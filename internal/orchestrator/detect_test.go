@@ -0,0 +1,222 @@
+package orchestrator
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"image/draw"
+	"strings"
+	"testing"
+
+	"github.com/oumi/k2p/internal/config"
+	"github.com/oumi/k2p/internal/imageprocessing"
+	"github.com/oumi/k2p/internal/screenshot"
+)
+
+// Auto direction: detection frames become the first pages without duplicating
+// the page Kindle is still showing, and a book that never turns is an error
+func TestAutoDirectionDetection(t *testing.T) {
+	opts := generateOptions()
+	opts.PageTurnKey = "auto"
+
+	t.Run("pages change", func(t *testing.T) {
+		orch := newTestOrchestrator(t, sequenceCapturer(8))
+		result, err := orch.ConvertCurrentBook(context.Background(), opts)
+		if err != nil {
+			t.Fatalf("conversion failed: %v", err)
+		}
+
+		// Captures: cover + 3 detection (1-4), activation check (5), loop pages 6-8
+		// are distinct, then 5 identical end pages. The last detection frame is
+		// recaptured by the loop, so it must not be kept twice
+		if want := 3 + 3; result.PageCount != want {
+			t.Errorf("expected %d pages, got %d", want, result.PageCount)
+		}
+		if pages := orch.pdfGen.(*MockPDFGenerator).ImageFiles; len(pages) != result.PageCount {
+			t.Errorf("page count %d does not match PDF images %d", result.PageCount, len(pages))
+		}
+	})
+
+	t.Run("pages never change", func(t *testing.T) {
+		_, err := newTestOrchestrator(t, &MockCapturer{}).ConvertCurrentBook(context.Background(), opts)
+		if err == nil || !strings.Contains(err.Error(), "could not detect page turn direction") {
+			t.Errorf("expected direction detection error, got %v", err)
+		}
+	})
+}
+
+// Detect mode: the cover captured during direction detection must not
+// influence the aggregated margins
+func TestDetectModeExcludesDetectionFrames(t *testing.T) {
+	// A full-bleed cover, then 9 pages with a 20px white margin around an
+	// alternating dark block, then blank end pages
+	capturer := &pageCapturer{Page: func(n int) image.Image {
+		if n == 1 {
+			return uniformPage(color.RGBA{R: 200, G: 30, B: 30, A: 255})
+		}
+		img := uniformPage(color.White).(*image.RGBA)
+		if n <= 10 {
+			shade := uint8(n % 2 * 100)
+			draw.Draw(img, image.Rect(20, 20, 80, 80), image.NewUniform(color.RGBA{R: shade, G: shade, B: shade, A: 255}), image.Point{}, draw.Src)
+		}
+		return img
+	}}
+	opts := generateOptions()
+	opts.Mode = "detect"
+	opts.PageTurnKey = "auto"
+
+	result, err := newTestOrchestrator(t, capturer).ConvertCurrentBook(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("detection failed: %v", err)
+	}
+
+	want := imageprocessing.TrimMargins{Top: 20, Bottom: 20, Left: 20, Right: 20}
+	if result.DetectedMargins == nil || *result.DetectedMargins != want {
+		t.Errorf("expected margins %+v (cover excluded), got %+v", want, result.DetectedMargins)
+	}
+}
+
+// End detection waits for EndDetectionMinPages so similar early pages of a
+// short book are not mistaken for the end-of-book screens
+func TestEndDetectionMinPages(t *testing.T) {
+	for _, tt := range []struct {
+		minPages  int
+		wantPages int
+	}{
+		{0, 0}, // default window: the first 5 identical pages end the book, which is still a successful run
+		{8, 3},
+	} {
+		opts := generateOptions()
+		opts.EndDetectionMinPages = tt.minPages
+
+		result, err := newTestOrchestrator(t, &MockCapturer{}).ConvertCurrentBook(context.Background(), opts)
+		if err != nil {
+			t.Fatalf("EndDetectionMinPages=%d: expected success, got %v (exit code %d)", tt.minPages, err, ExitCode(err))
+		}
+		if result.PageCount != tt.wantPages {
+			t.Errorf("EndDetectionMinPages=%d: expected %d pages, got %d", tt.minPages, tt.wantPages, result.PageCount)
+		}
+	}
+}
+
+// Direction detection threshold: unset falls back to 90%, and stays looser than end detection
+func TestDirectionChangeThreshold(t *testing.T) {
+	if got := directionChangeThreshold(&config.ConversionOptions{}); got != 0.90 {
+		t.Errorf("Expected default threshold 0.90, got %v", got)
+	}
+	if got := directionChangeThreshold(&config.ConversionOptions{DirectionChangeThreshold: 0.98}); got != 0.98 {
+		t.Errorf("Expected configured threshold 0.98, got %v", got)
+	}
+	if defaultDirectionChangeThreshold >= endDetectionSimilarity {
+		t.Errorf("Direction threshold %v should be below end detection similarity %v",
+			defaultDirectionChangeThreshold, endDetectionSimilarity)
+	}
+}
+
+// UI end detection: the page showing Kindle's end-of-book screen ends the capture and is dropped
+func TestUIEndDetection(t *testing.T) {
+	for _, enabled := range []bool{true, false} {
+		orch := newTestOrchestrator(t, screenshot.NewSyntheticCapturer(12))
+		orch.automation = &MockAutomation{Installed: true, BookOpen: true, Foreground: true, EndAfterTurns: 5}
+		opts := generateOptions()
+		opts.UIEndDetection = enabled
+
+		result, err := orch.ConvertCurrentBook(context.Background(), opts)
+		if err != nil {
+			t.Fatalf("UIEndDetection=%v: conversion failed: %v", enabled, err)
+		}
+		if enabled && result.PageCount != 5 {
+			t.Errorf("expected 5 pages before the end screen, got %d", result.PageCount)
+		}
+		if !enabled && result.PageCount <= 5 {
+			t.Errorf("expected the end screen to be ignored when disabled, got %d pages", result.PageCount)
+		}
+	}
+}
+
+// Stall watchdog: a normal end still goes to end detection, while pages that
+// stop changing without matching as end screens stop the capture, keeping the
+// first of them and reporting a warning
+func TestStallPages(t *testing.T) {
+	t.Run("normal end", func(t *testing.T) {
+		opts := generateOptions()
+		opts.StallPages = 6
+
+		result, err := newTestOrchestrator(t, screenshot.NewSyntheticCapturer(6)).ConvertCurrentBook(context.Background(), opts)
+		if err != nil {
+			t.Fatalf("conversion failed: %v", err)
+		}
+		if result.PageCount != 5 || len(result.Warnings) != 0 {
+			t.Errorf("expected end detection to drop the end screens, got %d pages, warnings %v", result.PageCount, result.Warnings)
+		}
+	})
+
+	t.Run("frozen pages", func(t *testing.T) {
+		opts := generateOptions()
+		opts.StallPages = 6
+
+		// After the activation capture and 5 distinct pages a 10x10 mark
+		// blinks: 99% similar, enough for the watchdog but short of end detection
+		capturer := &pageCapturer{Page: func(n int) image.Image {
+			if n <= 6 {
+				return uniformPage(color.RGBA{R: uint8(n * 40), G: uint8(255 - n*40), A: 255})
+			}
+			img := uniformPage(color.White).(*image.RGBA)
+			if n%2 == 0 {
+				draw.Draw(img, image.Rect(0, 0, 10, 10), image.NewUniform(color.Black), image.Point{}, draw.Src)
+			}
+			return img
+		}}
+
+		result, err := newTestOrchestrator(t, capturer).ConvertCurrentBook(context.Background(), opts)
+		if err != nil {
+			t.Fatalf("conversion failed: %v", err)
+		}
+		if result.PageCount != 6 {
+			t.Errorf("expected the 5 pages and the first repeated page, got %d", result.PageCount)
+		}
+		if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "stopped changing") {
+			t.Errorf("expected a stall warning, got %v", result.Warnings)
+		}
+	})
+}
+
+// A page indicator that stops changing ends the book although the screen
+// never repeats; the first page showing the last number is kept
+func TestPageNumberEndDetection(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		stall     int
+		wantPages int
+	}{
+		{"default stall turns", 0, 8},
+		{"longer stall", 5, 8},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			// The indicator in the bottom-left corner flips between black and
+			// white on every turn up to the 7th, over a background that changes
+			// on every capture like an animation
+			auto := readyAutomation()
+			capturer := &pageCapturer{Page: func(n int) image.Image {
+				img := image.NewGray(image.Rect(0, 0, 100, 100))
+				draw.Draw(img, img.Bounds(), image.NewUniform(color.Gray{Y: uint8(n * 37)}), image.Point{}, draw.Src)
+				number := color.Gray{Y: uint8(min(auto.TurnCount, 7) % 2 * 255)}
+				draw.Draw(img, image.Rect(0, 90, 20, 100), image.NewUniform(number), image.Point{}, draw.Src)
+				return img
+			}}
+			orch := newTestOrchestrator(t, capturer)
+			orch.automation = auto
+			opts := generateOptions()
+			opts.PageNumberRegion = "0,90,20,10"
+			opts.PageNumberStallTurns = tt.stall
+
+			result, err := orch.ConvertCurrentBook(context.Background(), opts)
+			if err != nil {
+				t.Fatalf("conversion failed: %v", err)
+			}
+			if result.PageCount != tt.wantPages {
+				t.Errorf("expected %d pages, got %d", tt.wantPages, result.PageCount)
+			}
+		})
+	}
+}
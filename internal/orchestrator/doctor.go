@@ -0,0 +1,157 @@
+package orchestrator
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/oumi/k2p/internal/automation"
+	"github.com/oumi/k2p/internal/config"
+)
+
+// ErrDoctorFailed means at least one required doctor check failed
+var ErrDoctorFailed = errors.New("environment checks failed")
+
+// doctorCheck is one line of the doctor report
+type doctorCheck struct {
+	name string
+
+	// Failure (nil: passed) and what was found for a passed check
+	err    error
+	detail string
+
+	// Remedy when err carries no hint of its own
+	hint string
+
+	// A failed optional check only disables a feature
+	optional bool
+}
+
+// doctor runs every environment check a conversion depends on and prints a
+// pass/fail report with remedies (Mode "doctor"). Unlike the checks before a
+// run it never stops at the first problem. Failed required checks make the
+// error; unavailable optional features become warnings
+func (o *DefaultOrchestrator) doctor(options *config.ConversionOptions) (*ConversionResult, error) {
+	startTime := time.Now()
+	result := &ConversionResult{Warnings: []string{}}
+
+	o.automation.SetCommandTimeout(options.CommandTimeout)
+	o.automation.SetTarget(automation.TargetForApp(options.TargetApp))
+
+	var checks []doctorCheck
+	add := func(c doctorCheck) {
+		checks = append(checks, c)
+	}
+
+	// Reader app
+	installed, err := o.automation.IsKindleInstalled()
+	if err == nil && !installed {
+		err = automation.ErrKindleNotInstalled
+	}
+	running := doctorCheck{name: "Kindle running", err: err}
+	if err == nil {
+		result.AppVersion = o.appVersion(false)
+		running.detail = "version " + result.AppVersion
+		if result.AppVersion == "" {
+			running.detail = "version unknown"
+		}
+	}
+	add(running)
+	if err == nil {
+		open, err := o.automation.IsBookOpen()
+		if err == nil && !open {
+			err = automation.ErrNoBookOpen
+		}
+		add(doctorCheck{name: "Book open", err: err})
+
+		front, err := o.automation.IsKindleInForeground()
+		if err == nil && !front {
+			err = fmt.Errorf("not in front right now")
+		}
+		add(doctorCheck{name: "Kindle in front", err: err, optional: true,
+			hint: "Start brings Kindle to the front itself; if that fails, click the Kindle window first"})
+	}
+
+	// macOS permissions
+	if o.permissions != nil {
+		add(doctorCheck{name: "Screen Recording permission", err: o.permissions.CheckScreenRecording()})
+		add(doctorCheck{name: "Accessibility permission", err: o.permissions.CheckAccessibility()})
+	}
+
+	// Files
+	tempDir, err := os.MkdirTemp("", "k2p-doctor-")
+	if err == nil {
+		err = os.WriteFile(filepath.Join(tempDir, "check"), []byte("k2p"), 0o644)
+		os.RemoveAll(tempDir)
+	}
+	add(doctorCheck{name: "Temp directory writable", err: err, detail: os.TempDir(),
+		hint: "Set TMPDIR to a writable directory"})
+
+	outputDir, err := outputDirFor(options)
+	if err == nil {
+		err = o.fileManager.ValidateOutputPath(outputDir)
+	}
+	add(doctorCheck{name: "Output directory writable", err: err, detail: outputDir,
+		hint: "Choose another output directory or check its permissions"})
+	if err == nil {
+		add(doctorCheck{name: "Disk space", err: o.fileManager.CheckDiskSpace(outputDir, estimatedOutputSize),
+			detail: fmt.Sprintf("at least %d MB free", estimatedOutputSize/(1024*1024)),
+			hint:   "Free up space or choose an output directory on another volume"})
+	}
+
+	// Optional external tools
+	if o.optimizer != nil {
+		var err error
+		if !o.optimizer.IsInstalled() {
+			err = fmt.Errorf("qpdf or gs not found (needed for Optimize)")
+		}
+		add(doctorCheck{name: "PDF optimizer", err: err, optional: true, detail: "qpdf or gs found",
+			hint: "Install qpdf (brew install qpdf) or Ghostscript (brew install ghostscript)"})
+	}
+
+	return o.printDoctorReport(checks, result, startTime)
+}
+
+// printDoctorReport prints one line per check and fills in the result
+func (o *DefaultOrchestrator) printDoctorReport(checks []doctorCheck, result *ConversionResult, startTime time.Time) (*ConversionResult, error) {
+	o.println("=== k2p Doctor ===")
+	failed := 0
+	for _, c := range checks {
+		switch {
+		case c.err == nil:
+			if c.detail != "" {
+				o.printf("  ✓ %s: %s\n", c.name, c.detail)
+			} else {
+				o.printf("  ✓ %s\n", c.name)
+			}
+			continue
+		case c.optional:
+			o.printf("  ! %s: %v (optional)\n", c.name, c.err)
+			result.Warnings = append(result.Warnings, fmt.Sprintf("%s: %v", c.name, c.err))
+		default:
+			o.printf("  ✗ %s: %v\n", c.name, c.err)
+			failed++
+		}
+		hint := Hint(c.err)
+		if hint == "" {
+			hint = c.hint
+		}
+		if hint != "" {
+			o.printf("    → %s\n", hint)
+		}
+	}
+	result.Duration = time.Since(startTime)
+
+	if failed > 0 {
+		o.printf("\n%d of %d checks failed\n", failed, len(checks))
+		return result, &EnvironmentError{Err: fmt.Errorf("%w: %d of %d checks", ErrDoctorFailed, failed, len(checks))}
+	}
+	o.printf("\nAll required checks passed")
+	if len(result.Warnings) > 0 {
+		o.printf(" (%d optional features unavailable)", len(result.Warnings))
+	}
+	o.println()
+	return result, nil
+}
@@ -0,0 +1,57 @@
+package orchestrator
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/oumi/k2p/internal/config"
+	"github.com/oumi/k2p/internal/filemanager"
+	"github.com/oumi/k2p/internal/preflight"
+)
+
+// Doctor: every check is reported; required failures make the error, missing
+// optional tools only warnings
+func TestDoctor(t *testing.T) {
+	run := func(auto *MockAutomation, perms *MockPermissions, fm *MockFileManager) (*ConversionResult, string, error) {
+		orch := newTestOrchestrator(t, nil)
+		orch.automation = auto
+		orch.fileManager = fm
+		orch.permissions = perms
+		orch.optimizer = &MockOptimizer{}
+		var logs bytes.Buffer
+		orch.SetLogWriter(&logs)
+		result, err := orch.ConvertCurrentBook(context.Background(), &config.ConversionOptions{Mode: "doctor", OutputDir: t.TempDir()})
+		return result, logs.String(), err
+	}
+
+	ready := readyAutomation()
+	ready.Version = "7.35"
+	result, output, err := run(ready, &MockPermissions{}, &MockFileManager{})
+	if err != nil {
+		t.Fatalf("expected all required checks to pass, got %v\n%s", err, output)
+	}
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "PDF optimizer") {
+		t.Errorf("expected a warning for the missing optimizer only, got %q", result.Warnings)
+	}
+	if !strings.Contains(output, "✓ Kindle running: version 7.35") || ready.TurnCount != 0 {
+		t.Errorf("expected the Kindle version and no page turns:\n%s", output)
+	}
+
+	// Several problems are all reported, each with its remedy
+	_, output, err = run(
+		&MockAutomation{Installed: true, BookOpen: false, Foreground: true},
+		&MockPermissions{AccessibilityErr: preflight.ErrAccessibilityDenied},
+		&MockFileManager{DiskSpaceError: filemanager.ErrInsufficientDiskSpace},
+	)
+	if !errors.Is(err, ErrDoctorFailed) || ExitCode(err) != ExitEnvironment {
+		t.Fatalf("expected ErrDoctorFailed as an environment error, got %v", err)
+	}
+	for _, want := range []string{"✗ Book open", "✗ Accessibility permission", "✗ Disk space", "3 of 9 checks failed", "→ Open a book in Kindle"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected %q in the report:\n%s", want, output)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package orchestrator
+
+import (
+	"image"
+
+	"github.com/oumi/k2p/internal/imageprocessing"
+)
+
+// defaultDuplicateDistance is the dHash Hamming distance still treated as a
+// possible repeat; the candidate is confirmed with a pixel comparison
+const defaultDuplicateDistance = 2
+
+// duplicatePage is a page that repeats an earlier page of the book
+type duplicatePage struct {
+	Path     string
+	Page     int
+	Original string
+	OrigPage int
+}
+
+// duplicateIndex finds pages repeated anywhere in the book (e.g. recurring ads)
+// The dHash of every page is kept; the closest hash match is only a candidate
+// and must also reach endDetectionSimilarity in a pixel comparison. Pages
+// whose hash matches the page right before them are never flagged, so runs of
+// similar sequential pages (and the identical end-of-book screens) are left alone
+type duplicateIndex struct {
+	maxDistance int
+	region      image.Rectangle
+	entries     []duplicateEntry
+	duplicates  []duplicatePage
+}
+
+type duplicateEntry struct {
+	hash uint64
+	path string
+	page int
+}
+
+// newDuplicateIndex creates an empty index comparing only region (empty = whole page)
+func newDuplicateIndex(region image.Rectangle) *duplicateIndex {
+	return &duplicateIndex{maxDistance: defaultDuplicateDistance, region: region}
+}
+
+// add records a captured page and reports the earlier page it repeats, if any
+func (d *duplicateIndex) add(page int, path string, img image.Image) (duplicatePage, bool) {
+	hash := imageprocessing.DHash(croppedOrWhole(img, d.region))
+	defer func() {
+		d.entries = append(d.entries, duplicateEntry{hash: hash, path: path, page: page})
+	}()
+
+	// Sequential repeats belong to end detection, not to this index
+	if n := len(d.entries); n > 0 && d.entries[n-1].page == page-1 &&
+		imageprocessing.HammingDistance(d.entries[n-1].hash, hash) <= d.maxDistance {
+		return duplicatePage{}, false
+	}
+
+	// Verify only the closest earlier hash, so each page costs at most one decode
+	best, bestDistance := -1, d.maxDistance+1
+	for i, e := range d.entries {
+		if distance := imageprocessing.HammingDistance(e.hash, hash); distance < bestDistance {
+			best, bestDistance = i, distance
+		}
+	}
+	if best < 0 {
+		return duplicatePage{}, false
+	}
+
+	earlier, err := imageprocessing.LoadImage(d.entries[best].path)
+	if err != nil || imageprocessing.CompareDecodedImagesInRegion(earlier, img, d.region) < endDetectionSimilarity {
+		return duplicatePage{}, false
+	}
+	dup := duplicatePage{Path: path, Page: page, Original: d.entries[best].path, OrigPage: d.entries[best].page}
+	d.duplicates = append(d.duplicates, dup)
+	return dup, true
+}
+
+// croppedOrWhole returns the region of img, or img itself for an empty or
+// non-overlapping region
+func croppedOrWhole(img image.Image, region image.Rectangle) image.Image {
+	if region.Empty() {
+		return img
+	}
+	sub, ok := img.(interface {
+		SubImage(r image.Rectangle) image.Image
+	})
+	if !ok {
+		return img
+	}
+	rect := region.Add(img.Bounds().Min).Intersect(img.Bounds())
+	if rect.Empty() {
+		return img
+	}
+	return sub.SubImage(rect)
+}
@@ -0,0 +1,59 @@
+package orchestrator
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"path/filepath"
+	"testing"
+
+	"github.com/oumi/k2p/internal/imageprocessing"
+)
+
+// Duplicate index: repeats of non-adjacent pages are flagged, sequential
+// repeats and hash-only matches are not
+func TestDuplicateIndex(t *testing.T) {
+	dir := t.TempDir()
+	striped := func(width int) image.Image {
+		img := image.NewRGBA(image.Rect(0, 0, 120, 120))
+		for y := 0; y < 120; y++ {
+			for x := 0; x < 120; x++ {
+				if (x/width)%2 == 0 {
+					img.Set(x, y, color.Black)
+				} else {
+					img.Set(x, y, color.White)
+				}
+			}
+		}
+		return img
+	}
+	uniform := func(c color.Color) image.Image {
+		img := image.NewRGBA(image.Rect(0, 0, 120, 120))
+		draw.Draw(img, img.Bounds(), image.NewUniform(c), image.Point{}, draw.Src)
+		return img
+	}
+
+	a, b, c := striped(7), striped(19), striped(31)
+	red := uniform(color.RGBA{R: 255, A: 255})
+	green := uniform(color.RGBA{G: 255, A: 255})
+	pages := []image.Image{a, b, c, a, a, red, b, green}
+
+	index := newDuplicateIndex(image.Rectangle{})
+	for i, img := range pages {
+		path := filepath.Join(dir, fmt.Sprintf("page_%04d.png", i+1))
+		if err := imageprocessing.SavePNG(img, path); err != nil {
+			t.Fatal(err)
+		}
+		index.add(i+1, path, img)
+	}
+
+	var got [][2]int
+	for _, dup := range index.duplicates {
+		got = append(got, [2]int{dup.Page, dup.OrigPage})
+	}
+	want := [][2]int{{4, 1}, {7, 2}}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("expected duplicates (page, original) %v, got %v", want, got)
+	}
+}
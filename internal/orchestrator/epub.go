@@ -0,0 +1,53 @@
+package orchestrator
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/oumi/k2p/internal/config"
+	"github.com/oumi/k2p/internal/epub"
+)
+
+// generateEPUB runs OCR on every screenshot and assembles the result into an EPUB
+// Pages whose OCR fails are kept (image only) and reported as warnings
+// Returns the path written, which is a fallback location when outputPath
+// cannot be written (see writeOutput)
+func (o *DefaultOrchestrator) generateEPUB(screenshots []string, outputPath, tempDir string, options *config.ConversionOptions) (string, []string, error) {
+	var warnings []string
+	pages := make([]epub.Page, 0, len(screenshots))
+
+	for i, screenshot := range screenshots {
+		o.printf("\rRecognizing text on page %d/%d...", i+1, len(screenshots))
+
+		text, err := o.recognizer.RecognizeText(screenshot)
+		if err != nil {
+			warning := fmt.Sprintf("OCR failed for page %d: %v", i+1, err)
+			warnings = append(warnings, warning)
+			if options.Verbose {
+				o.printf("\n  Warning: %s\n", warning)
+			}
+		}
+
+		pages = append(pages, epub.Page{Text: text, ImagePath: screenshot})
+	}
+	o.println()
+
+	epubOpts := epub.EPUBOptions{
+		Title:           strings.TrimSuffix(filepath.Base(outputPath), filepath.Ext(outputPath)),
+		PagesPerChapter: options.EPUBPagesPerChapter,
+		EmbedImages:     options.EPUBEmbedImages,
+	}
+	written, warning, err := o.writeOutput(outputPath, tempDir, func(path string) error {
+		return o.epubGen.CreateEPUB(pages, path, epubOpts)
+	})
+	if err != nil {
+		return "", warnings, err
+	}
+	if warning != "" {
+		o.printf("Warning: %s\n", warning)
+		warnings = append(warnings, warning)
+	}
+
+	return written, warnings, nil
+}
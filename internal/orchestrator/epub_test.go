@@ -0,0 +1,118 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/oumi/k2p/internal/epub"
+)
+
+type MockRecognizer struct {
+	Calls int
+
+	// FailPage makes OCR of that page fail (0: never)
+	FailPage int
+}
+
+func (m *MockRecognizer) RecognizeText(imagePath string) (string, error) {
+	m.Calls++
+	if m.Calls == m.FailPage {
+		return "", fmt.Errorf("vision unavailable")
+	}
+	return fmt.Sprintf("text %d", m.Calls), nil
+}
+
+type MockEPUBGenerator struct {
+	Pages      []epub.Page
+	OutputPath string
+}
+
+func (m *MockEPUBGenerator) CreateEPUB(pages []epub.Page, outputPath string, options epub.EPUBOptions) error {
+	m.Pages = pages
+	m.OutputPath = outputPath
+	return os.WriteFile(outputPath, []byte("PK"), 0644)
+}
+
+// EPUB format OCRs each page and writes .epub output instead of a PDF
+func TestEPUBOutputFormat(t *testing.T) {
+	rec := &MockRecognizer{}
+	eg := &MockEPUBGenerator{}
+	orch := newTestOrchestrator(t, sequenceCapturer(6))
+	orch.pdfGen = &MockPDFGenerator{GenerateError: fmt.Errorf("PDF must not be generated")}
+	orch.recognizer = rec
+	orch.epubGen = eg
+	opts := generateOptions()
+	opts.PageTurnKey = "auto"
+	opts.OutputFormat = "epub"
+
+	result, err := orch.ConvertCurrentBook(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("conversion failed: %v", err)
+	}
+
+	want := filepath.Join(filepath.Dir(orch.fileManager.(*MockFileManager).ResolvePath), "book.epub")
+	if result.OutputPath != want || filepath.Ext(eg.OutputPath) != ".epub" {
+		t.Errorf("expected EPUB output at %s, got %s (generator %s)", want, result.OutputPath, eg.OutputPath)
+	}
+	if len(eg.Pages) == 0 || rec.Calls != len(eg.Pages) {
+		t.Errorf("expected every page OCRed, got %d pages and %d calls", len(eg.Pages), rec.Calls)
+	}
+	if len(eg.Pages) > 0 && eg.Pages[0].Text != "text 1" {
+		t.Errorf("expected the first page text %q, got %q", "text 1", eg.Pages[0].Text)
+	}
+}
+
+// A page whose OCR fails keeps its image without text and is reported
+func TestEPUBOCRFailure(t *testing.T) {
+	eg := &MockEPUBGenerator{}
+	orch := newTestOrchestrator(t, sequenceCapturer(6))
+	orch.recognizer = &MockRecognizer{FailPage: 2}
+	orch.epubGen = eg
+	opts := generateOptions()
+	opts.OutputFormat = "epub"
+
+	result, err := orch.ConvertCurrentBook(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("conversion failed: %v", err)
+	}
+	if len(eg.Pages) < 3 || eg.Pages[1].Text != "" || eg.Pages[1].ImagePath == "" || eg.Pages[2].Text != "text 3" {
+		t.Fatalf("expected page 2 image-only and the others OCRed, got %+v", eg.Pages)
+	}
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "OCR failed for page 2: vision unavailable") {
+		t.Errorf("expected an OCR warning for page 2, got %v", result.Warnings)
+	}
+}
+
+// The EPUB is rendered in the temp directory and goes through the same
+// fallback as a PDF when the output directory cannot be written
+func TestEPUBOutputFallback(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	eg := &MockEPUBGenerator{}
+	orch := newTestOrchestrator(t, sequenceCapturer(6))
+	orch.fileManager = &MockFileManager{ResolvePath: filepath.Join(t.TempDir(), "gone", "book.pdf"), HandleExists: true}
+	orch.recognizer = &MockRecognizer{}
+	orch.epubGen = eg
+	opts := generateOptions()
+	opts.OutputFormat = "epub"
+
+	result, err := orch.ConvertCurrentBook(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("conversion failed: %v", err)
+	}
+	if filepath.Base(eg.OutputPath) != "output.epub" {
+		t.Errorf("expected the EPUB rendered in the temp directory, got %s", eg.OutputPath)
+	}
+	want := filepath.Join(home, "book.epub")
+	if result.OutputPath != want || result.FileSize == 0 {
+		t.Errorf("expected the EPUB at %s, got %s (%d bytes)", want, result.OutputPath, result.FileSize)
+	}
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "saved to "+want) {
+		t.Errorf("expected a warning naming the fallback file, got %v", result.Warnings)
+	}
+}
@@ -0,0 +1,80 @@
+package orchestrator
+
+import (
+	"errors"
+	"fmt"
+
+	"path/filepath"
+
+	"github.com/oumi/k2p/internal/hint"
+	"github.com/oumi/k2p/internal/imageprocessing"
+)
+
+// Result codes for a conversion error (see ExitCode)
+// The GUI uses them to label the outcome in its status line; they follow the
+// Unix exit status convention so a command front-end can exit with them
+const (
+	// ExitSuccess: conversion finished
+	ExitSuccess = 0
+
+	// ExitFailure: conversion failed at runtime (capture, PDF generation, ...)
+	ExitFailure = 1
+
+	// ExitEnvironment: Kindle is not installed, no book is open, or Kindle is not in front
+	ExitEnvironment = 3
+)
+
+// ErrBlackCapture means the first captured page is entirely black, which is
+// what screencapture produces without Screen Recording permission
+var ErrBlackCapture = hint.New(
+	"captured page is entirely black (Screen Recording permission is probably missing)",
+	"Open System Settings → Privacy & Security → Screen Recording and enable k2p. If the first page really is black (e.g. a dark cover), enable Allow Black First Page",
+)
+
+// ErrPagesStalled means the StallPages watchdog stopped a capture whose pages
+// stopped changing; the pages captured before are still usable
+var ErrPagesStalled = errors.New("pages stopped changing")
+
+// EnvironmentError reports that Kindle was not ready for a conversion
+// The message is the underlying error's, so callers can still print it as is
+type EnvironmentError struct {
+	Err error
+}
+
+func (e *EnvironmentError) Error() string { return e.Err.Error() }
+func (e *EnvironmentError) Unwrap() error { return e.Err }
+
+// ExitCode maps a conversion error to its result code
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitSuccess
+	}
+	var envErr *EnvironmentError
+	if errors.As(err, &envErr) {
+		return ExitEnvironment
+	}
+	return ExitFailure
+}
+
+// Hint returns the remediation hint carried by err or any error it wraps
+// Returns "" when the error has no known remedy
+func Hint(err error) string {
+	return hint.Of(err)
+}
+
+// checkFirstCapture fails fast when the first capture is entirely black, so a
+// missing permission does not turn the whole book into black pages
+func checkFirstCapture(path string, allowBlack bool) error {
+	if allowBlack {
+		return nil
+	}
+	info, err := imageprocessing.DetectBlankFile(path)
+	if err != nil {
+		// An unreadable capture is reported by the comparisons later on
+		return nil
+	}
+	if info.Black {
+		return &EnvironmentError{Err: fmt.Errorf("%w: %s", ErrBlackCapture, filepath.Base(path))}
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image/color"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/oumi/k2p/internal/automation"
+	"github.com/oumi/k2p/internal/config"
+	"github.com/oumi/k2p/internal/filemanager"
+)
+
+// Exit codes distinguish Kindle environment problems from runtime failures
+func TestExitCode(t *testing.T) {
+	orch := newTestOrchestrator(t, &MockCapturer{})
+	orch.automation = &MockAutomation{Installed: false}
+
+	_, err := orch.ConvertCurrentBook(context.Background(), &config.ConversionOptions{AutoConfirm: true})
+	if got := ExitCode(err); got != ExitEnvironment {
+		t.Errorf("expected exit code %d for Kindle not installed, got %d (err: %v)", ExitEnvironment, got, err)
+	}
+
+	if got := ExitCode(fmt.Errorf("failed to capture pages: %w", os.ErrPermission)); got != ExitFailure {
+		t.Errorf("expected exit code %d for runtime failure, got %d", ExitFailure, got)
+	}
+	if got := ExitCode(nil); got != ExitSuccess {
+		t.Errorf("expected exit code %d for success, got %d", ExitSuccess, got)
+	}
+}
+
+func TestHint(t *testing.T) {
+	wrapped := &EnvironmentError{Err: fmt.Errorf("%w. Please bring Kindle to the front and try again", automation.ErrKindleNotForeground)}
+	if got := Hint(wrapped); !strings.Contains(got, "Click the Kindle window") {
+		t.Errorf("unexpected hint for foreground error: %q", got)
+	}
+	midRun := fmt.Errorf("failed to capture page 3: %w", fmt.Errorf("%w: Kindle lost focus", automation.ErrKindleNotForeground))
+	if got := Hint(midRun); !strings.Contains(got, "Click the Kindle window") {
+		t.Errorf("unexpected hint for losing focus mid-run: %q", got)
+	}
+	if got := Hint(fmt.Errorf("check: %w", filemanager.ErrInsufficientDiskSpace)); got == "" {
+		t.Error("expected hint for disk space error")
+	}
+	if got := Hint(fmt.Errorf("something else")); got != "" {
+		t.Errorf("expected no hint for unknown error, got %q", got)
+	}
+}
+
+// A black first capture (no Screen Recording permission) aborts before any
+// page is turned, unless allowed
+func TestBlackFirstCaptureFailsFast(t *testing.T) {
+	for _, key := range []string{"right", "auto"} {
+		orch := newTestOrchestrator(t, framesCapturer(color.Black))
+		auto := orch.automation.(*MockAutomation)
+		opts := generateOptions()
+		opts.PageTurnKey = key
+
+		_, err := orch.ConvertCurrentBook(context.Background(), opts)
+		if !errors.Is(err, ErrBlackCapture) || ExitCode(err) != ExitEnvironment {
+			t.Errorf("PageTurnKey=%s: expected black capture environment error, got %v", key, err)
+		}
+		if auto.TurnCount != 0 {
+			t.Errorf("PageTurnKey=%s: expected no page turns, got %d", key, auto.TurnCount)
+		}
+
+		opts.AllowBlackFirstPage = true
+		if _, err := orch.ConvertCurrentBook(context.Background(), opts); errors.Is(err, ErrBlackCapture) {
+			t.Errorf("PageTurnKey=%s: black capture should be allowed, got %v", key, err)
+		}
+	}
+}
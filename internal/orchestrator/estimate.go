@@ -0,0 +1,46 @@
+package orchestrator
+
+import "github.com/oumi/k2p/internal/config"
+
+// Capture size assumed for estimates: a full-screen Kindle window on a
+// 2880x1800 Retina display
+const (
+	estimateCaptureWidth  = 2880
+	estimateCaptureHeight = 1800
+)
+
+// bytesPerPixel is a rough compressed size per pixel for book pages
+// PNG (quality 100) keeps text edges exactly and is the largest
+func bytesPerPixel(quality int) float64 {
+	switch {
+	case quality <= 0 || quality >= 100:
+		return 0.45
+	case quality >= 90:
+		return 0.25
+	case quality >= 75:
+		return 0.15
+	default:
+		return 0.10
+	}
+}
+
+// EstimatePageBytes returns a rough output size of one page
+// Based on the screenshot quality and the area left after manual trimming;
+// images are embedded as captured, so the PDF quality barely changes it
+func EstimatePageBytes(options *config.ConversionOptions) int64 {
+	width := estimateCaptureWidth - 2*options.TrimHorizontal
+	height := estimateCaptureHeight - options.TrimTop - options.TrimBottom
+	if width <= 0 || height <= 0 {
+		return 0
+	}
+	return int64(float64(width*height) * bytesPerPixel(options.ScreenshotQuality))
+}
+
+// EstimateOutputBytes returns a rough output size for the given page count
+// Returns 0 (unknown) when the page count is not known in advance
+func EstimateOutputBytes(options *config.ConversionOptions, pages int) int64 {
+	if pages <= 0 {
+		return 0
+	}
+	return EstimatePageBytes(options) * int64(pages)
+}
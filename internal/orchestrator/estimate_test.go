@@ -0,0 +1,29 @@
+package orchestrator
+
+import (
+	"testing"
+
+	"github.com/oumi/k2p/internal/config"
+)
+
+// Size estimate: smaller with lower quality and more trimming, unknown without a page count
+func TestEstimateOutputBytes(t *testing.T) {
+	png := EstimatePageBytes(&config.ConversionOptions{ScreenshotQuality: 100})
+	jpeg := EstimatePageBytes(&config.ConversionOptions{ScreenshotQuality: 80})
+	if png <= jpeg || jpeg <= 0 {
+		t.Errorf("expected PNG estimate > JPEG estimate > 0, got %d and %d", png, jpeg)
+	}
+
+	trimmed := EstimatePageBytes(&config.ConversionOptions{ScreenshotQuality: 100, TrimHorizontal: 200, TrimTop: 100})
+	if trimmed >= png {
+		t.Errorf("expected trimming to shrink the estimate, got %d >= %d", trimmed, png)
+	}
+
+	opts := &config.ConversionOptions{ScreenshotQuality: 100}
+	if got := EstimateOutputBytes(opts, 10); got != 10*png {
+		t.Errorf("expected 10 pages to be %d, got %d", 10*png, got)
+	}
+	if got := EstimateOutputBytes(opts, 0); got != 0 {
+		t.Errorf("expected 0 for an unknown page count, got %d", got)
+	}
+}
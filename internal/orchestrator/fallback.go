@@ -0,0 +1,89 @@
+package orchestrator
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// writeOutput renders the document once with write into tempDir, then copies
+// it to outputPath. If the copy fails, e.g. because an external or network
+// volume was unmounted or became read-only during the run, the captured pages
+// are not lost: the partly written file is removed and the rendered document
+// is copied under the same file name to the home directory, then to the temp
+// directory. A failed render is returned straight away
+// Returns the path actually written and, for a fallback location, a warning
+// saying where the file went; the original error when every location fails
+func (o *DefaultOrchestrator) writeOutput(outputPath, tempDir string, write func(path string) error) (string, string, error) {
+	rendered := filepath.Join(tempDir, "output"+filepath.Ext(outputPath))
+	if err := write(rendered); err != nil {
+		return "", "", err
+	}
+
+	err := copyFile(rendered, outputPath)
+	if err == nil {
+		return outputPath, "", nil
+	}
+	// Don't leave a truncated document behind at the original location
+	os.Remove(outputPath)
+
+	for _, dir := range fallbackDirs() {
+		if dir == filepath.Dir(outputPath) {
+			continue
+		}
+		o.printf("Warning: failed to write %s (%v); trying %s\n", outputPath, err, dir)
+		path := availablePath(filepath.Join(dir, filepath.Base(outputPath)))
+		if ferr := copyFile(rendered, path); ferr != nil {
+			os.Remove(path)
+			continue
+		}
+		return path, fmt.Sprintf("Could not write %s (%v); saved to %s instead", outputPath, err, path), nil
+	}
+	return "", "", err
+}
+
+// copyFile copies src to dst, replacing dst, and reports a failed close:
+// on a network volume that is where a write error often shows up
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// fallbackDirs lists where the output goes when the output directory cannot
+// be written: the home directory, then the temp directory
+func fallbackDirs() []string {
+	var dirs []string
+	if home, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, home)
+	}
+	return append(dirs, os.TempDir())
+}
+
+// availablePath returns path, or path with a _N suffix before the extension
+// when a file of that name already exists
+func availablePath(path string) string {
+	ext := filepath.Ext(path)
+	base := strings.TrimSuffix(path, ext)
+	candidate := path
+	for n := 1; ; n++ {
+		if _, err := os.Stat(candidate); os.IsNotExist(err) {
+			return candidate
+		}
+		candidate = fmt.Sprintf("%s_%d%s", base, n, ext)
+	}
+}
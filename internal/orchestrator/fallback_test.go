@@ -0,0 +1,84 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/oumi/k2p/internal/pdf"
+)
+
+// countingPDFGenerator counts renders and fails each with err
+type countingPDFGenerator struct {
+	err   error
+	calls int
+}
+
+func (g *countingPDFGenerator) CreatePDF(imageFiles []string, outputPath string, options pdf.PDFOptions) error {
+	g.calls++
+	return g.err
+}
+
+func (g *countingPDFGenerator) CreatePDFFromImages(images []image.Image, outputPath string, options pdf.PDFOptions) error {
+	return g.CreatePDF(nil, outputPath, options)
+}
+
+// An output volume that went away during the run: the document goes to the
+// home directory instead, next to an existing file
+func TestOutputFallback(t *testing.T) {
+	tmpDir := t.TempDir()
+	home := filepath.Join(tmpDir, "home")
+	if err := os.MkdirAll(home, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(home, "book.pdf"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("HOME", home)
+
+	volume := filepath.Join(tmpDir, "volume")
+	orch := newTestOrchestrator(t, &MockCapturer{})
+	orch.fileManager = &MockFileManager{ResolvePath: filepath.Join(volume, "book.pdf"), HandleExists: true}
+
+	result, err := orch.ConvertCurrentBook(context.Background(), generateOptions())
+	if err != nil {
+		t.Fatalf("conversion failed: %v", err)
+	}
+	want := filepath.Join(home, "book_1.pdf")
+	if result.OutputPath != want || result.FileSize == 0 {
+		t.Errorf("expected the output at %s, got %s (%d bytes)", want, result.OutputPath, result.FileSize)
+	}
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "saved to "+want) {
+		t.Errorf("expected a warning naming the fallback file, got %v", result.Warnings)
+	}
+}
+
+// A document that cannot be generated fails after one render, without
+// trying the fallback locations
+func TestOutputFallbackSkipsGenerationErrors(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	orch := newTestOrchestrator(t, &MockCapturer{})
+	gen := &countingPDFGenerator{err: fmt.Errorf("corrupt page")}
+	orch.pdfGen = gen
+
+	var logs strings.Builder
+	orch.SetLogWriter(&logs)
+	if _, err := orch.ConvertCurrentBook(context.Background(), generateOptions()); err == nil || !strings.Contains(err.Error(), "corrupt page") {
+		t.Fatalf("expected the generation error, got %v", err)
+	}
+	if gen.calls != 1 {
+		t.Errorf("expected one render, got %d", gen.calls)
+	}
+	if strings.Contains(logs.String(), "trying") {
+		t.Errorf("expected no fallback attempt, got logs:\n%s", logs.String())
+	}
+	if entries, _ := os.ReadDir(home); len(entries) != 0 {
+		t.Errorf("expected nothing written to the home directory, got %d entries", len(entries))
+	}
+}
@@ -0,0 +1,47 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/oumi/k2p/internal/config"
+)
+
+// captureFirstPage writes the page Kindle is showing to outputPath as an
+// image and stops: the cover capture of direction detection on its own, with
+// no page turns, end detection or output document (FirstPageOnly)
+func (o *DefaultOrchestrator) captureFirstPage(ctx context.Context, outputPath string, options *config.ConversionOptions, startTime time.Time) (*ConversionResult, error) {
+	o.println("\nCapturing the first page...")
+	err := RetryWithBackoff(ctx, DefaultRetryConfig(), func() error {
+		return o.capturer.CaptureFrontmostWindow(outputPath)
+	})
+	if err == nil {
+		err = checkFirstCapture(outputPath, options.AllowBlackFirstPage)
+	}
+	if err != nil {
+		os.Remove(outputPath)
+		o.soundPlayer.PlayError()
+		return nil, fmt.Errorf("failed to capture the first page: %w", err)
+	}
+
+	result := &ConversionResult{
+		OutputPath: outputPath,
+		PageCount:  1,
+		Warnings:   []string{},
+		Duration:   time.Since(startTime),
+	}
+	if info, err := os.Stat(outputPath); err == nil {
+		result.FileSize = info.Size()
+	}
+	o.soundPlayer.PlaySuccess()
+
+	if options.OutputSummary == "json" {
+		o.printSummaryJSON(options, result)
+		return result, nil
+	}
+	o.println("\n=== First Page Captured ===")
+	o.printf("Output: %s\n", outputPath)
+	return result, nil
+}
@@ -0,0 +1,41 @@
+package orchestrator
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/oumi/k2p/internal/config"
+	"github.com/oumi/k2p/internal/imageprocessing"
+	"github.com/oumi/k2p/internal/screenshot"
+)
+
+// FirstPageOnly writes one PNG without turning pages or generating a PDF
+func TestFirstPageOnly(t *testing.T) {
+	dir := t.TempDir()
+	orch := newTestOrchestrator(t, screenshot.NewSyntheticCapturer(6))
+	orch.fileManager = &MockFileManager{ResolvePath: filepath.Join(dir, "book.pdf"), HandleExists: true}
+	mock := orch.automation.(*MockAutomation)
+	pdfGen := orch.pdfGen.(*MockPDFGenerator)
+
+	result, err := orch.ConvertCurrentBook(context.Background(), &config.ConversionOptions{
+		AutoConfirm:   true,
+		Mode:          "generate",
+		PageTurnKey:   "auto",
+		FirstPageOnly: true,
+	})
+	if err != nil {
+		t.Fatalf("capture failed: %v", err)
+	}
+
+	want := filepath.Join(dir, "book.png")
+	if result.OutputPath != want || result.PageCount != 1 {
+		t.Errorf("expected one page at %s, got %d at %s", want, result.PageCount, result.OutputPath)
+	}
+	if _, err := imageprocessing.LoadImage(want); err != nil {
+		t.Errorf("expected a readable image: %v", err)
+	}
+	if mock.TurnCount != 0 || pdfGen.ImageFiles != nil {
+		t.Errorf("expected no page turns and no PDF, got %d turns, PDF of %v", mock.TurnCount, pdfGen.ImageFiles)
+	}
+}
@@ -0,0 +1,122 @@
+package orchestrator
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/oumi/k2p/internal/automation"
+	"github.com/oumi/k2p/internal/config"
+)
+
+// FocusLostFunc is called when Kindle loses the foreground in "pause" mode
+// It should wait until the user is ready to resume and return false to abort
+type FocusLostFunc func() bool
+
+// PromptFocusLostFromStdin asks the user on stdin to resume after Kindle lost focus
+// Pressing Enter resumes; entering "q" aborts the run
+func PromptFocusLostFromStdin() bool {
+	fmt.Print("\nKindle lost focus. Press Enter to bring it back and resume (q + Enter to abort): ")
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return false
+	}
+	answer := strings.ToLower(strings.TrimSpace(line))
+	return answer != "q" && answer != "quit"
+}
+
+// SetFocusLostPrompt sets how "pause" mode waits for the user (default: stdin)
+func (o *DefaultOrchestrator) SetFocusLostPrompt(prompt FocusLostFunc) {
+	o.focusLostPrompt = prompt
+}
+
+// withFocusRecovery runs op and, if it failed because Kindle lost the
+// foreground, recovers according to OnFocusLost before retrying op once:
+//   - "abort" (default): return the failure unchanged
+//   - "refocus": bring Kindle back once
+//   - "pause": wait for the user, then bring Kindle back
+func (o *DefaultOrchestrator) withFocusRecovery(options *config.ConversionOptions, op func() error) error {
+	err := op()
+	mode := options.OnFocusLost
+	if err == nil || mode == "" || mode == "abort" {
+		return err
+	}
+
+	if inForeground, checkErr := o.automation.IsKindleInForeground(); checkErr != nil || inForeground {
+		// Not a focus problem (or we cannot tell); keep the original error
+		return err
+	}
+
+	if mode == "pause" {
+		prompt := o.focusLostPrompt
+		if prompt == nil {
+			prompt = PromptFocusLostFromStdin
+		}
+		o.soundPlayer.PlayError()
+		for {
+			if !prompt() {
+				return fmt.Errorf("%w (aborted while paused)", err)
+			}
+			// Answering the prompt focused the terminal or GUI, so activate Kindle again
+			focusErr := o.refocusKindle()
+			if focusErr == nil {
+				break
+			}
+			o.printf("Kindle is still not in the foreground: %v\n", focusErr)
+		}
+		o.println("Resuming")
+		return op()
+	}
+
+	if focusErr := o.refocusKindle(); focusErr != nil {
+		return fmt.Errorf("%w (refocus failed: %v)", err, focusErr)
+	}
+	o.println("\nKindle lost focus; brought it back to the foreground")
+
+	return op()
+}
+
+// turnRetryConfig returns the retry policy for page turns: TurnRetries
+// attempts and, with TurnRefocus, Kindle brought back to the front between
+// attempts when a turn failed because it had lost the foreground. Short focus
+// losses (a notification, a popup) then cost a retry instead of the run
+func (o *DefaultOrchestrator) turnRetryConfig(options *config.ConversionOptions) RetryConfig {
+	retryConfig := DefaultRetryConfig()
+	if options.TurnRetries > 0 {
+		retryConfig.MaxAttempts = options.TurnRetries
+	}
+	if options.TurnRefocus {
+		retryConfig.BeforeRetry = func(err error) {
+			if inForeground, checkErr := o.automation.IsKindleInForeground(); checkErr != nil || inForeground {
+				return
+			}
+			if focusErr := o.refocusKindle(); focusErr != nil {
+				if options.Verbose {
+					o.printf("\nPage turn failed and Kindle could not be refocused: %v\n", focusErr)
+				}
+				return
+			}
+			if options.Verbose {
+				o.printf("\nPage turn failed (%v); brought Kindle back to the foreground\n", err)
+			}
+		}
+	}
+	return retryConfig
+}
+
+// refocusKindle activates Kindle and re-checks the foreground once
+func (o *DefaultOrchestrator) refocusKindle() error {
+	if err := o.automation.BringKindleToForeground(); err != nil {
+		return err
+	}
+
+	inForeground, err := o.automation.IsKindleInForeground()
+	if err != nil {
+		return err
+	}
+	if !inForeground {
+		return automation.ErrKindleNotForeground
+	}
+	return nil
+}
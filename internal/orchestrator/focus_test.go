@@ -0,0 +1,112 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/oumi/k2p/internal/automation"
+	"github.com/oumi/k2p/internal/config"
+)
+
+// focusAutomation loses the foreground on turn LoseFocusAt and fails page
+// turns while Kindle is not in front, until BringKindleToForeground
+type focusAutomation struct {
+	MockAutomation
+	LoseFocusAt int
+	Refocused   int
+}
+
+func (a *focusAutomation) IsKindleInForeground() (bool, error) { return a.Foreground, nil }
+func (a *focusAutomation) BringKindleToForeground() error {
+	a.Refocused++
+	a.Foreground = true
+	return nil
+}
+func (a *focusAutomation) TurnNextPage(direction string) error {
+	a.TurnCount++
+	if a.TurnCount == a.LoseFocusAt {
+		a.Foreground = false
+	}
+	if !a.Foreground {
+		return automation.ErrKindleNotForeground
+	}
+	return nil
+}
+
+// OnFocusLost decides between stopping, pausing and refocusing
+func TestOnFocusLost(t *testing.T) {
+	for _, tt := range []struct {
+		mode          string
+		resume        []bool // prompt answers in pause mode
+		wantErr       bool
+		wantRefocused int
+		wantPrompts   int
+	}{
+		{mode: "abort", wantErr: true},
+		{mode: "refocus", wantRefocused: 1},
+		{mode: "pause", resume: []bool{true}, wantRefocused: 1, wantPrompts: 1},
+		{mode: "pause", resume: []bool{false}, wantErr: true, wantPrompts: 1},
+	} {
+		auto := &focusAutomation{MockAutomation: *readyAutomation(), LoseFocusAt: 2}
+		orch := newTestOrchestrator(t, &MockCapturer{})
+		orch.automation = auto
+		prompts := 0
+		orch.SetFocusLostPrompt(func() bool {
+			prompts++
+			return tt.resume[prompts-1]
+		})
+		opts := generateOptions()
+		opts.OnFocusLost = tt.mode
+
+		_, err := orch.ConvertCurrentBook(context.Background(), opts)
+		if tt.wantErr {
+			if !errors.Is(err, automation.ErrKindleNotForeground) {
+				t.Errorf("%s: expected ErrKindleNotForeground, got %v", tt.mode, err)
+			}
+		} else if err != nil {
+			t.Errorf("%s: expected recovery, got %v", tt.mode, err)
+		}
+		if auto.Refocused != tt.wantRefocused {
+			t.Errorf("%s: expected %d refocus, got %d", tt.mode, tt.wantRefocused, auto.Refocused)
+		}
+		if prompts != tt.wantPrompts {
+			t.Errorf("%s: expected %d prompts, got %d", tt.mode, tt.wantPrompts, prompts)
+		}
+	}
+}
+
+// Page turns have their own retry policy, which can refocus Kindle between attempts
+func TestTurnRetryConfig(t *testing.T) {
+	for _, tt := range []struct {
+		name          string
+		options       config.ConversionOptions
+		wantErr       bool
+		wantTurns     int
+		wantRefocused int
+	}{
+		{"refocus between attempts", config.ConversionOptions{TurnRetries: 2, TurnRefocus: true}, false, 2, 1},
+		{"retries without refocus", config.ConversionOptions{TurnRetries: 4}, true, 4, 0},
+		{"default attempts", config.ConversionOptions{}, true, DefaultRetryConfig().MaxAttempts, 0},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			// Kindle starts out behind another window
+			auto := &focusAutomation{MockAutomation: MockAutomation{Installed: true, BookOpen: true}}
+			orch := newTestOrchestrator(t, nil)
+			orch.automation = auto
+
+			retryConfig := orch.turnRetryConfig(&tt.options)
+			retryConfig.InitialDelay = time.Millisecond
+			err := RetryWithBackoff(context.Background(), retryConfig, func() error {
+				return auto.TurnNextPage("right")
+			})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if auto.TurnCount != tt.wantTurns || auto.Refocused != tt.wantRefocused {
+				t.Errorf("expected %d turns and %d refocuses, got %d and %d", tt.wantTurns, tt.wantRefocused, auto.TurnCount, auto.Refocused)
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package orchestrator
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"io"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/oumi/k2p/internal/config"
+	"github.com/oumi/k2p/internal/imageprocessing"
+	"github.com/oumi/k2p/internal/pdf"
+	"github.com/oumi/k2p/internal/screenshot"
+	"github.com/oumi/k2p/internal/sound"
+)
+
+// Shared test setup for the feature tests
+
+// readyAutomation returns a Kindle that is installed, has a book open and is in front
+func readyAutomation() *MockAutomation {
+	return &MockAutomation{Installed: true, BookOpen: true, Foreground: true}
+}
+
+// newTestOrchestrator returns an orchestrator for a ready Kindle that captures
+// from capturer, records the PDF in a MockPDFGenerator, writes book.pdf to a
+// temp directory and discards its log. Tests replace fields as needed
+func newTestOrchestrator(t *testing.T, capturer screenshot.Capturer) *DefaultOrchestrator {
+	t.Helper()
+	orch := &DefaultOrchestrator{
+		automation:  readyAutomation(),
+		fileManager: &MockFileManager{ResolvePath: filepath.Join(t.TempDir(), "book.pdf"), HandleExists: true},
+		pdfGen:      &MockPDFGenerator{},
+		capturer:    capturer,
+		soundPlayer: sound.NewNoOpPlayer(),
+	}
+	orch.SetLogWriter(io.Discard)
+	return orch
+}
+
+// generateOptions returns options for a quick generate run without prompts
+// The page turn key is fixed: identical mock pages cannot be auto-detected
+func generateOptions() *config.ConversionOptions {
+	return &config.ConversionOptions{
+		AutoConfirm: true,
+		Mode:        "generate",
+		PageDelay:   time.Millisecond,
+		PageTurnKey: "right",
+		PDFQuality:  "high",
+	}
+}
+
+// pageCapturer is the configurable capturer for the feature tests
+// Count numbers every capture from 1, activation and polling included
+type pageCapturer struct {
+	// Page draws capture n; nil takes the capture from Next instead
+	Page func(n int) image.Image
+
+	// Next produces the captures when Page is nil, e.g. a SyntheticCapturer
+	Next screenshot.Capturer
+
+	// Fail makes capture n fail (nil: never)
+	Fail func(n int) bool
+
+	Count int
+
+	// Options is the last configuration
+	Options screenshot.CaptureOptions
+}
+
+func (c *pageCapturer) CaptureWithoutActivation(path string) error {
+	c.Count++
+	if c.Fail != nil && c.Fail(c.Count) {
+		return fmt.Errorf("capture %d failed", c.Count)
+	}
+	if c.Page == nil {
+		return c.Next.CaptureWithoutActivation(path)
+	}
+	return imageprocessing.SavePNG(c.Page(c.Count), path)
+}
+
+func (c *pageCapturer) CaptureFrontmostWindow(path string) error {
+	return c.CaptureWithoutActivation(path)
+}
+
+func (c *pageCapturer) Configure(options screenshot.CaptureOptions) {
+	c.Options = options
+	if c.Next != nil {
+		c.Next.Configure(options)
+	}
+}
+
+// uniformPage returns a 100x100 page filled with c
+func uniformPage(c color.Color) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, 100, 100))
+	draw.Draw(img, img.Bounds(), image.NewUniform(c), image.Point{}, draw.Src)
+	return img
+}
+
+// framesCapturer captures uniform pages of the given colours in turn,
+// repeating the last one
+func framesCapturer(frames ...color.Color) *pageCapturer {
+	return &pageCapturer{Page: func(n int) image.Image {
+		return uniformPage(frames[min(n, len(frames))-1])
+	}}
+}
+
+// sequenceCapturer captures distinct different pages followed by identical
+// end-of-book pages, so direction detection and end detection both succeed
+func sequenceCapturer(distinct int) *pageCapturer {
+	return &pageCapturer{Page: func(n int) image.Image {
+		if n > distinct {
+			return uniformPage(color.White)
+		}
+		return uniformPage(color.RGBA{R: uint8(n * 40), G: uint8(255 - n*40), A: 255})
+	}}
+}
+
+// writePage saves a uniform page of colour c to path
+func writePage(t *testing.T, path string, c color.Color) {
+	t.Helper()
+	if err := imageprocessing.SavePNG(uniformPage(c), path); err != nil {
+		t.Fatal(err)
+	}
+}
+
+type MockPermissions struct {
+	ScreenErr        error
+	AccessibilityErr error
+}
+
+func (m *MockPermissions) CheckScreenRecording() error { return m.ScreenErr }
+func (m *MockPermissions) CheckAccessibility() error   { return m.AccessibilityErr }
+
+type MockOptimizer struct {
+	Installed bool
+	Calls     int
+}
+
+func (m *MockOptimizer) IsInstalled() bool { return m.Installed }
+func (m *MockOptimizer) Optimize(path string) (int64, int64, error) {
+	m.Calls++
+	return 200, 100, nil
+}
+
+// inspectingPDFGenerator looks at every page image before it is cleaned up
+type inspectingPDFGenerator struct {
+	*MockPDFGenerator
+	inspect func(path string)
+}
+
+func (g *inspectingPDFGenerator) CreatePDF(imageFiles []string, outputPath string, options pdf.PDFOptions) error {
+	for _, path := range imageFiles {
+		g.inspect(path)
+	}
+	return g.MockPDFGenerator.CreatePDF(imageFiles, outputPath, options)
+}
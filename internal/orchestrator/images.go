@@ -0,0 +1,217 @@
+package orchestrator
+
+import (
+	"archive/zip"
+	"fmt"
+	"image"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/oumi/k2p/internal/config"
+)
+
+// imageExtensions are the image files the PDF generator can embed
+var imageExtensions = map[string]bool{".png": true, ".jpg": true, ".jpeg": true}
+
+// imagesToPDF writes the images in InputFile, a directory or a CBZ/ZIP
+// archive, to OutputFile as one page each, in natural name order (page2
+// before page10). Archives are extracted to a temp directory that is removed
+// afterwards; every image is validated before anything is written
+func (o *DefaultOrchestrator) imagesToPDF(options *config.ConversionOptions) (*ConversionResult, error) {
+	startTime := time.Now()
+
+	input, outputPath := options.InputFile, options.OutputFile
+	if input == "" || outputPath == "" {
+		return nil, fmt.Errorf("input and output files are required for images2pdf mode")
+	}
+
+	info, err := os.Stat(input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read input: %w", err)
+	}
+	var images []string
+	switch ext := strings.ToLower(filepath.Ext(input)); {
+	case info.IsDir():
+		images, err = listImages(input)
+	case ext == ".cbz" || ext == ".zip":
+		tempDir, terr := os.MkdirTemp("", "k2p-images-*")
+		if terr != nil {
+			return nil, fmt.Errorf("failed to create temp directory: %w", terr)
+		}
+		defer os.RemoveAll(tempDir)
+		images, err = extractImages(input, tempDir)
+	default:
+		return nil, fmt.Errorf("input must be a directory of images or a CBZ/ZIP archive: %s", input)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(images) == 0 {
+		return nil, fmt.Errorf("no PNG or JPEG images found in %s", input)
+	}
+	for _, path := range images {
+		if err := validateImage(path); err != nil {
+			return nil, err
+		}
+	}
+
+	outputPath, proceed, err := o.claimOutput(outputPath, options)
+	if err != nil {
+		return nil, err
+	}
+	if !proceed {
+		return nil, fmt.Errorf("conversion cancelled: file already exists")
+	}
+
+	o.printf("Converting %d images...\n", len(images))
+	if err := o.pdfGen.CreatePDF(images, outputPath, pdfOptions(options)); err != nil {
+		return nil, fmt.Errorf("failed to create PDF: %w", err)
+	}
+
+	result := &ConversionResult{
+		OutputPath: outputPath,
+		PageCount:  len(images),
+		Warnings:   []string{},
+	}
+	if info, err := os.Stat(outputPath); err == nil {
+		result.FileSize = info.Size()
+	}
+	result.Duration = time.Since(startTime)
+
+	o.printf("Output: %s\n", outputPath)
+	o.printf("Pages: %d\n", result.PageCount)
+	o.printf("Size: %.2f MB\n", float64(result.FileSize)/(1024*1024))
+
+	return result, nil
+}
+
+// listImages returns the images directly in dir, in natural name order
+func listImages(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read input directory: %w", err)
+	}
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() && isImageName(entry.Name()) {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Slice(names, func(i, j int) bool { return naturalLess(names[i], names[j]) })
+
+	images := make([]string, len(names))
+	for i, name := range names {
+		images[i] = filepath.Join(dir, name)
+	}
+	return images, nil
+}
+
+// extractImages extracts the images in a CBZ/ZIP archive to dir, in natural
+// order of their paths in the archive, and returns the extracted files
+// Other entries (e.g. ComicInfo.xml) are skipped. Files are written under
+// numbered names, so entry paths never decide where anything is written
+func extractImages(archivePath, dir string) ([]string, error) {
+	r, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive %s: %w", archivePath, err)
+	}
+	defer r.Close()
+
+	var files []*zip.File
+	for _, f := range r.File {
+		if !f.FileInfo().IsDir() && isImageName(f.Name) && !strings.HasPrefix(f.Name, "__MACOSX/") {
+			files = append(files, f)
+		}
+	}
+	sort.Slice(files, func(i, j int) bool { return naturalLess(files[i].Name, files[j].Name) })
+
+	images := make([]string, 0, len(files))
+	for i, f := range files {
+		path := filepath.Join(dir, fmt.Sprintf("page_%04d%s", i+1, strings.ToLower(filepath.Ext(f.Name))))
+		if err := extractFile(f, path); err != nil {
+			return nil, fmt.Errorf("failed to extract %s: %w", f.Name, err)
+		}
+		images = append(images, path)
+	}
+	return images, nil
+}
+
+// extractFile writes one archive entry to path
+func extractFile(f *zip.File, path string) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	out, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, rc); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// isImageName reports whether name has an image extension the PDF generator
+// supports; macOS resource forks ("._name") are not images
+func isImageName(name string) bool {
+	base := filepath.Base(name)
+	return imageExtensions[strings.ToLower(filepath.Ext(base))] && !strings.HasPrefix(base, "._")
+}
+
+// validateImage checks that path decodes as an image
+func validateImage(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, _, err := image.DecodeConfig(f); err != nil {
+		return fmt.Errorf("%s is not a valid image: %w", filepath.Base(path), err)
+	}
+	return nil
+}
+
+// naturalLess orders names case-insensitively with runs of digits compared
+// by value, so "page2" sorts before "page10"
+func naturalLess(a, b string) bool {
+	a, b = strings.ToLower(a), strings.ToLower(b)
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		if isDigit(a[i]) && isDigit(b[j]) {
+			si, sj := i, j
+			for i < len(a) && isDigit(a[i]) {
+				i++
+			}
+			for j < len(b) && isDigit(b[j]) {
+				j++
+			}
+			na, nb := strings.TrimLeft(a[si:i], "0"), strings.TrimLeft(b[sj:j], "0")
+			if len(na) != len(nb) {
+				return len(na) < len(nb)
+			}
+			if na != nb {
+				return na < nb
+			}
+			continue
+		}
+		if a[i] != b[j] {
+			return a[i] < b[j]
+		}
+		i++
+		j++
+	}
+	return len(a)-i < len(b)-j
+}
+
+// isDigit reports whether c is an ASCII digit
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,111 @@
+package orchestrator
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/oumi/k2p/internal/config"
+	"github.com/oumi/k2p/internal/imageprocessing"
+)
+
+// images2pdf: archive images become pages in natural name order; other
+// entries are skipped and the extracted files are removed afterwards
+func TestImagesToPDF(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeArchive := func(name string, entries map[string][]byte) string {
+		path := filepath.Join(tmpDir, name)
+		f, err := os.Create(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		zw := zip.NewWriter(f)
+		for entry, data := range entries {
+			w, err := zw.Create(entry)
+			if err != nil {
+				t.Fatal(err)
+			}
+			w.Write(data)
+		}
+		if err := zw.Close(); err != nil {
+			t.Fatal(err)
+		}
+		f.Close()
+		return path
+	}
+	// Page n is a grey level of n, so the order can be read back
+	page := func(n uint8) []byte {
+		img := image.NewGray(image.Rect(0, 0, 10, 10))
+		draw.Draw(img, img.Bounds(), image.NewUniform(color.Gray{Y: n}), image.Point{}, draw.Src)
+		var buf bytes.Buffer
+		png.Encode(&buf, img)
+		return buf.Bytes()
+	}
+
+	archive := writeArchive("book.cbz", map[string][]byte{
+		"book/page10.png":           page(10),
+		"book/page2.png":            page(2),
+		"book/Page1.PNG":            page(1),
+		"book/ComicInfo.xml":        []byte("<ComicInfo/>"),
+		"__MACOSX/book/._page1.png": []byte("resource fork"),
+	})
+	var levels []uint8
+	pg := &MockPDFGenerator{}
+	orch := newTestOrchestrator(t, nil)
+	orch.automation = &MockAutomation{}
+	orch.fileManager = &MockFileManager{HandleExists: true}
+	orch.pdfGen = &inspectingPDFGenerator{MockPDFGenerator: pg, inspect: func(path string) {
+		if img, err := imageprocessing.LoadImage(path); err == nil {
+			levels = append(levels, color.GrayModel.Convert(img.At(0, 0)).(color.Gray).Y)
+		}
+	}}
+
+	result, err := orch.ConvertCurrentBook(context.Background(), &config.ConversionOptions{
+		Mode: "images2pdf", InputFile: archive, OutputFile: filepath.Join(tmpDir, "book.pdf"),
+	})
+	if err != nil {
+		t.Fatalf("images2pdf failed: %v", err)
+	}
+	if !reflect.DeepEqual(levels, []uint8{1, 2, 10}) || result.PageCount != 3 {
+		t.Errorf("expected pages 1, 2, 10, got %v (%d pages)", levels, result.PageCount)
+	}
+	if _, err := os.Stat(filepath.Dir(pg.ImageFiles[0])); !os.IsNotExist(err) {
+		t.Errorf("expected the extraction directory to be removed, got %v", err)
+	}
+
+	// A file named like an image must decode as one
+	broken := writeArchive("broken.zip", map[string][]byte{"1.png": page(1), "2.jpg": []byte("not a jpeg")})
+	if _, err := orch.ConvertCurrentBook(context.Background(), &config.ConversionOptions{
+		Mode: "images2pdf", InputFile: broken, OutputFile: filepath.Join(tmpDir, "broken.pdf"),
+	}); err == nil || !strings.Contains(err.Error(), "not a valid image") {
+		t.Errorf("expected an invalid image error, got %v", err)
+	}
+}
+
+func TestNaturalLess(t *testing.T) {
+	for _, tt := range []struct {
+		a, b string
+		want bool
+	}{
+		{"page2.png", "page10.png", true},
+		{"page10.png", "page2.png", false},
+		{"page02.png", "page10.png", true},
+		{"Page1.png", "page2.png", true},
+		{"ch1/page9.png", "ch2/page1.png", true},
+		{"page.png", "page1.png", true},
+		{"page1.png", "page1.png", false},
+	} {
+		if got := naturalLess(tt.a, tt.b); got != tt.want {
+			t.Errorf("naturalLess(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package orchestrator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/oumi/k2p/internal/config"
+	"github.com/oumi/k2p/internal/pdf"
+)
+
+// partialPDF keeps a PDF of the pages captured so far next to the output
+// (IncrementalPDF), so a crash or a failed run still leaves a valid document
+// Pages go in as captured: untrimmed, and including the end-of-book screens
+// that end detection drops later. A nil *partialPDF does nothing
+type partialPDF struct {
+	path    string
+	tempDir string
+	every   int
+	flushed int
+	failed  bool
+	pdfGen  pdf.PDFGenerator
+	options pdf.PDFOptions
+}
+
+// newPartialPDF returns the partial PDF for outputPath, or nil when
+// IncrementalPDF is off or the run does not write a PDF
+func (o *DefaultOrchestrator) newPartialPDF(outputPath, tempDir string, options *config.ConversionOptions) *partialPDF {
+	if options.IncrementalPDF <= 0 || options.Mode != "generate" || options.OutputFormat == "epub" {
+		return nil
+	}
+	return &partialPDF{
+		path:    partialPDFPath(outputPath),
+		tempDir: tempDir,
+		every:   options.IncrementalPDF,
+		pdfGen:  o.pdfGen,
+		options: pdfOptions(options),
+	}
+}
+
+// partialPDFPath returns where the pages captured so far are kept for an
+// output file: <name>.partial.pdf next to it
+func partialPDFPath(outputPath string) string {
+	return strings.TrimSuffix(outputPath, filepath.Ext(outputPath)) + ".partial.pdf"
+}
+
+// add flushes the pages not yet in the partial PDF once there are enough
+// of them. After a failure the partial PDF is no longer updated
+func (p *partialPDF) add(screenshots []string) error {
+	if p == nil || p.failed || len(screenshots)-p.flushed < p.every {
+		return nil
+	}
+	return p.flush(screenshots)
+}
+
+// flush appends screenshots not yet in the partial PDF to it
+// The first chunk creates the file; later chunks are rendered separately and
+// merged onto it, which replaces the file only once the merge succeeded
+func (p *partialPDF) flush(screenshots []string) error {
+	if p == nil || p.failed || len(screenshots) <= p.flushed {
+		return nil
+	}
+	pages := screenshots[p.flushed:]
+
+	var err error
+	if p.flushed == 0 {
+		if err = p.pdfGen.CreatePDF(pages, p.path, p.options); err != nil {
+			os.Remove(p.path)
+		}
+	} else {
+		chunkPath := filepath.Join(p.tempDir, "partial_chunk.pdf")
+		defer os.Remove(chunkPath)
+		if err = p.pdfGen.CreatePDF(pages, chunkPath, p.options); err == nil {
+			err = pdf.AppendFile(p.path, chunkPath)
+		}
+	}
+	if err != nil {
+		p.failed = true
+		return fmt.Errorf("failed to update the partial PDF %s, no longer updating it: %w", p.path, err)
+	}
+	p.flushed = len(screenshots)
+	return nil
+}
+
+// remove deletes the partial PDF once the complete output has been written
+func (p *partialPDF) remove() {
+	if p != nil {
+		os.Remove(p.path)
+	}
+}
@@ -0,0 +1,47 @@
+package orchestrator
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/oumi/k2p/internal/pdf"
+	"github.com/oumi/k2p/internal/screenshot"
+)
+
+// Incremental PDF: a failed run leaves the pages captured so far as a PDF,
+// and a finished run removes it
+func TestIncrementalPDF(t *testing.T) {
+	run := func(capturer screenshot.Capturer, output string) error {
+		orch := newTestOrchestrator(t, capturer)
+		orch.fileManager = &MockFileManager{ResolvePath: output, HandleExists: true}
+		orch.pdfGen = pdf.NewPDFGenerator()
+		opts := generateOptions()
+		opts.IncrementalPDF = 2
+		_, err := orch.ConvertCurrentBook(context.Background(), opts)
+		return err
+	}
+
+	// Activation and six pages, then every capture fails
+	broken := &pageCapturer{Next: screenshot.NewSyntheticCapturer(10), Fail: func(n int) bool { return n >= 8 }}
+	output := filepath.Join(t.TempDir(), "book.pdf")
+	if err := run(broken, output); err == nil {
+		t.Fatal("expected the broken capture to fail the conversion")
+	}
+	count, err := pdf.PageCount(partialPDFPath(output))
+	if err != nil {
+		t.Fatalf("expected a partial PDF: %v", err)
+	}
+	if count != 6 {
+		t.Errorf("expected the 6 captured pages in the partial PDF, got %d", count)
+	}
+
+	output = filepath.Join(t.TempDir(), "book.pdf")
+	if err := run(screenshot.NewSyntheticCapturer(6), output); err != nil {
+		t.Fatalf("conversion failed: %v", err)
+	}
+	if _, err := os.Stat(partialPDFPath(output)); !os.IsNotExist(err) {
+		t.Errorf("expected the partial PDF to be removed after success, got %v", err)
+	}
+}
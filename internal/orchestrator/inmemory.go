@@ -0,0 +1,110 @@
+package orchestrator
+
+import (
+	"image"
+
+	"github.com/oumi/k2p/internal/config"
+	"github.com/oumi/k2p/internal/imageprocessing"
+)
+
+// pageBuffer keeps every captured page decoded (InMemoryPages), so the PDF is
+// written from memory instead of from page files. The capturer still writes
+// each page to a file: past its capacity the buffer lets go of all pages and
+// the run continues through those files. A nil *pageBuffer does nothing
+type pageBuffer struct {
+	capacity int
+	images   map[string]image.Image
+	spilled  bool
+}
+
+// newPageBuffer returns the page buffer, or nil when InMemoryPages is off or
+// the run needs page files: other modes, EPUB output, AutoRotate and
+// UniformPages
+func (o *DefaultOrchestrator) newPageBuffer(options *config.ConversionOptions) *pageBuffer {
+	if options.InMemoryPages <= 0 || options.Mode != "generate" {
+		return nil
+	}
+	if options.OutputFormat == "epub" || options.AutoRotate || options.UniformPages {
+		if options.Verbose {
+			o.println("\nIn-memory pages are not used with EPUB output, AutoRotate or UniformPages")
+		}
+		return nil
+	}
+	return &pageBuffer{capacity: options.InMemoryPages, images: make(map[string]image.Image)}
+}
+
+// add keeps a decoded page under its capture path. The first page past the
+// capacity drops all of them; add reports whether this call did
+func (b *pageBuffer) add(path string, img image.Image) bool {
+	if b == nil || b.spilled {
+		return false
+	}
+	if _, ok := b.images[path]; !ok && len(b.images) >= b.capacity {
+		b.spilled = true
+		b.images = nil
+		return true
+	}
+	b.images[path] = img
+	return false
+}
+
+// take hands over the decoded pages in the order of paths and empties the
+// buffer. ok is false when the buffer spilled or lacks one of the pages
+// (e.g. one that failed to decode), and the page files are used instead
+func (b *pageBuffer) take(paths []string) ([]image.Image, bool) {
+	if b == nil || b.spilled || len(paths) == 0 {
+		return nil, false
+	}
+	images := make([]image.Image, len(paths))
+	for i, path := range paths {
+		img, ok := b.images[path]
+		if !ok {
+			return nil, false
+		}
+		images[i] = img
+	}
+	b.images = nil
+	return images, true
+}
+
+// processImages rotates, colour-adjusts and trims the decoded pages in place,
+// in the order the page file passes use. Trimming crops with SubImage, so a
+// trimmed page shares the captured page's pixels
+// A page that cannot be trimmed keeps its margins, as with page files
+func (o *DefaultOrchestrator) processImages(images []image.Image, options *config.ConversionOptions, issues *pageIssues) {
+	adjust := colorAdjustments(options)
+	trim := imageprocessing.TrimMargins{
+		Top:    options.TrimTop,
+		Bottom: options.TrimBottom,
+		Left:   options.TrimHorizontal,
+		Right:  options.TrimHorizontal,
+	}
+	hasCustomTrim := trim != imageprocessing.TrimMargins{}
+	if options.Verbose {
+		o.printf("\nProcessing %d pages in memory...\n", len(images))
+	}
+
+	forEachPage(len(images), options.MaxConcurrency, func(i int) {
+		img := images[i]
+		if options.Rotate%360 != 0 {
+			img = imageprocessing.Rotate(img, options.Rotate)
+		}
+		for _, f := range adjust {
+			img = f(img)
+		}
+
+		// Keep the cover full-bleed
+		if hasCustomTrim && !(i == 0 && options.NoTrimCover && !options.SkipCover) {
+			bounds := img.Bounds()
+			if err := imageprocessing.CheckTrimMargins(bounds.Dx(), bounds.Dy(), trim); err != nil {
+				issues.add(issueTrim)
+				if options.Verbose {
+					o.printf("  Warning: Failed to trim page %d, using original: %v\n", i+1, err)
+				}
+			} else {
+				img = imageprocessing.TrimWithCustomMargins(img, trim.Top, trim.Bottom, trim.Left, trim.Right)
+			}
+		}
+		images[i] = img
+	})
+}
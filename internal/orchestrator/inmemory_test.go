@@ -0,0 +1,109 @@
+package orchestrator
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/oumi/k2p/internal/pdf"
+	"github.com/oumi/k2p/internal/screenshot"
+)
+
+// InMemoryPages: pages that fit are trimmed in memory and handed to the PDF
+// writer decoded; a longer book goes through the page files as usual
+func TestInMemoryPages(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		capacity int
+		inMemory bool
+	}{
+		{"book fits", 20, true},
+		{"book spills", 3, false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			orch := newTestOrchestrator(t, screenshot.NewSyntheticCapturer(6))
+			opts := generateOptions()
+			opts.InMemoryPages = tt.capacity
+			opts.TrimTop = 10
+			opts.NoTrimCover = true
+
+			result, err := orch.ConvertCurrentBook(context.Background(), opts)
+			if err != nil {
+				t.Fatalf("conversion failed: %v", err)
+			}
+			gen := orch.pdfGen.(*MockPDFGenerator)
+
+			if !tt.inMemory {
+				if gen.Images != nil || len(gen.ImageFiles) != result.PageCount {
+					t.Fatalf("expected %d page files, got %d files and %d images", result.PageCount, len(gen.ImageFiles), len(gen.Images))
+				}
+				if !strings.Contains(gen.ImageFiles[1], "_trimmed") {
+					t.Errorf("expected trimmed page files, got %v", gen.ImageFiles)
+				}
+				return
+			}
+
+			if gen.ImageFiles != nil || len(gen.Images) != result.PageCount {
+				t.Fatalf("expected %d decoded pages, got %d images and %d files", result.PageCount, len(gen.Images), len(gen.ImageFiles))
+			}
+			cover, page := gen.Images[0].Bounds(), gen.Images[1].Bounds()
+			if page.Dy() != cover.Dy()-10 || page.Dx() != cover.Dx() {
+				t.Errorf("expected pages trimmed by 10px except the cover, got cover %v and page %v", cover, page)
+			}
+		})
+	}
+}
+
+// The decoded pages make a real PDF with every page in it
+func TestInMemoryPagesPDF(t *testing.T) {
+	orch := newTestOrchestrator(t, screenshot.NewSyntheticCapturer(6))
+	orch.pdfGen = pdf.NewPDFGenerator()
+	opts := generateOptions()
+	opts.InMemoryPages = 20
+	opts.Invert = true
+
+	result, err := orch.ConvertCurrentBook(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("conversion failed: %v", err)
+	}
+	count, err := pdf.PageCount(result.OutputPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.PageCount == 0 || count != result.PageCount {
+		t.Errorf("expected %d PDF pages, got %d", result.PageCount, count)
+	}
+}
+
+// The buffer holds at most its capacity and hands over pages in order
+func TestPageBuffer(t *testing.T) {
+	black, white := uniformPage(color.Black), uniformPage(color.White)
+
+	b := &pageBuffer{capacity: 2, images: make(map[string]image.Image)}
+	b.add("a", black)
+	b.add("b", white)
+	if _, ok := b.take([]string{"a", "c"}); ok {
+		t.Error("expected take to fail for a page that was never added")
+	}
+	images, ok := b.take([]string{"b", "a"})
+	if !ok || images[0] != white || images[1] != black {
+		t.Errorf("expected the pages in the requested order, got %v, %v", images, ok)
+	}
+
+	b = &pageBuffer{capacity: 2, images: make(map[string]image.Image)}
+	b.add("a", black)
+	b.add("b", white)
+	if !b.add("c", black) {
+		t.Error("expected the third page to spill the buffer")
+	}
+	if _, ok := b.take([]string{"a", "b"}); ok {
+		t.Error("expected take to fail after spilling")
+	}
+
+	var none *pageBuffer
+	if none.add("a", black) {
+		t.Error("nil buffer should ignore pages")
+	}
+}
@@ -0,0 +1,69 @@
+package orchestrator
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Recoverable per-page problems, phrased to follow "N page(s)"
+const (
+	issueMargins    = "could not be analyzed for margins"
+	issueCapture    = "needed retries to capture"
+	issueTurn       = "needed retries to turn"
+	issueAdvance    = "did not advance until the turn was sent again"
+	issueRotate     = "could not be rotated"
+	issueColors     = "could not be colour-adjusted"
+	issueTrim       = "could not be trimmed"
+	issueOrient     = "could not be checked for orientation"
+	issueBMPEncode  = "could not be converted from BMP"
+	issueAutoRotate = "could not be auto-rotated"
+	issueThumbnail  = "could not be scaled for the contact sheet"
+	issuePad        = "could not be padded to the common page size"
+	issueUnsettled  = "kept changing while the first capture was verified; the last capture was kept"
+)
+
+// pageIssues counts recoverable per-page problems, so they reach
+// ConversionResult.Warnings whatever the verbosity. Safe for concurrent use
+// by the forEachPage passes; a nil *pageIssues ignores everything
+type pageIssues struct {
+	mu     sync.Mutex
+	counts map[string]int
+	order  []string
+}
+
+// newPageIssues creates an empty collector
+func newPageIssues() *pageIssues {
+	return &pageIssues{counts: make(map[string]int)}
+}
+
+// add records one page affected by issue
+func (p *pageIssues) add(issue string) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.counts[issue] == 0 {
+		p.order = append(p.order, issue)
+	}
+	p.counts[issue]++
+}
+
+// warnings returns one line per issue in the order first seen, e.g.
+// "3 pages could not be trimmed"
+func (p *pageIssues) warnings() []string {
+	if p == nil {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var warnings []string
+	for _, issue := range p.order {
+		pages := "pages"
+		if p.counts[issue] == 1 {
+			pages = "page"
+		}
+		warnings = append(warnings, fmt.Sprintf("%d %s %s", p.counts[issue], pages, issue))
+	}
+	return warnings
+}
@@ -0,0 +1,51 @@
+package orchestrator
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/oumi/k2p/internal/screenshot"
+)
+
+func TestPageIssues(t *testing.T) {
+	issues := newPageIssues()
+	issues.add(issueTrim)
+	issues.add(issueCapture)
+	issues.add(issueTrim)
+	issues.add(issueTrim)
+
+	want := []string{"3 pages could not be trimmed", "1 page needed retries to capture"}
+	if got := issues.warnings(); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	// A nil collector ignores everything
+	var none *pageIssues
+	none.add(issueTrim)
+	if got := none.warnings(); got != nil {
+		t.Errorf("expected no warnings from a nil collector, got %v", got)
+	}
+}
+
+// Recoverable problems reach the result without verbose logging
+func TestWarningsCollected(t *testing.T) {
+	// The first attempt of the third capture after activation fails
+	capturer := &pageCapturer{Next: screenshot.NewSyntheticCapturer(6), Fail: func(n int) bool { return n == 4 }}
+	orch := newTestOrchestrator(t, capturer)
+	var logs bytes.Buffer
+	orch.SetLogWriter(&logs)
+
+	result, err := orch.ConvertCurrentBook(context.Background(), generateOptions())
+	if err != nil {
+		t.Fatalf("conversion failed: %v", err)
+	}
+	if !reflect.DeepEqual(result.Warnings, []string{"1 page needed retries to capture"}) {
+		t.Errorf("unexpected warnings: %v", result.Warnings)
+	}
+	if !strings.Contains(logs.String(), "  - 1 page needed retries to capture\n") {
+		t.Errorf("expected the warning in the summary, got:\n%s", logs.String())
+	}
+}
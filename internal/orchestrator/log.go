@@ -0,0 +1,49 @@
+package orchestrator
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/oumi/k2p/internal/automation"
+	"github.com/oumi/k2p/internal/config"
+)
+
+// SetLogWriter sets where status and progress messages go (nil = os.Stdout)
+// Each orchestrator has its own writer, so concurrent runs do not mix logs
+func (o *DefaultOrchestrator) SetLogWriter(w io.Writer) {
+	o.logWriter = w
+}
+
+// out returns the log writer, resolving the default at write time
+func (o *DefaultOrchestrator) out() io.Writer {
+	if o.logWriter != nil {
+		return o.logWriter
+	}
+	return os.Stdout
+}
+
+// printf writes a formatted message to the log writer
+func (o *DefaultOrchestrator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(o.out(), format, args...)
+}
+
+// println writes a message and a newline to the log writer
+func (o *DefaultOrchestrator) println(args ...interface{}) {
+	fmt.Fprintln(o.out(), args...)
+}
+
+// print writes a message to the log writer
+func (o *DefaultOrchestrator) print(args ...interface{}) {
+	fmt.Fprint(o.out(), args...)
+}
+
+// newSession creates the AppleScript session of one run, dumping every call
+// to the log writer when DumpAppleScript is set
+func (o *DefaultOrchestrator) newSession(options *config.ConversionOptions) *automation.Session {
+	var dump io.Writer
+	if options.DumpAppleScript {
+		dump = o.out()
+	}
+	return automation.NewSession(options.ForegroundCache, dump)
+}
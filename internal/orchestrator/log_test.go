@@ -0,0 +1,62 @@
+package orchestrator
+
+import (
+	"bytes"
+	"context"
+	"image/color"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Log writer: messages go to the configured writer instead of os.Stdout
+func TestSetLogWriter(t *testing.T) {
+	orch := newTestOrchestrator(t, &MockCapturer{})
+	outputPath := filepath.Join(t.TempDir(), "out.pdf")
+	orch.fileManager = &MockFileManager{ResolvePath: outputPath, HandleExists: true}
+	var logs bytes.Buffer
+	orch.SetLogWriter(&logs)
+
+	opts := generateOptions()
+	opts.EndDetectionMinPages = 8
+	stdout := captureStdout(func() {
+		if _, err := orch.ConvertCurrentBook(context.Background(), opts); err != nil {
+			t.Errorf("conversion failed: %v", err)
+		}
+	})
+
+	if stdout != "" {
+		t.Errorf("expected nothing on stdout, got %q", stdout)
+	}
+	for _, want := range []string{"Kindle to PDF Converter", "Capturing pages", outputPath} {
+		if !strings.Contains(logs.String(), want) {
+			t.Errorf("log writer missing %q", want)
+		}
+	}
+}
+
+// Every run gets its own AppleScript session, shared by the automation and
+// the capturer, so runs with different options do not affect each other
+func TestSessionPerRun(t *testing.T) {
+	capturer := framesCapturer(color.White)
+	orch := newTestOrchestrator(t, capturer)
+	auto := orch.automation.(*MockAutomation)
+
+	opts := generateOptions()
+	opts.ForegroundCache = time.Second
+	for run := 1; run <= 2; run++ {
+		if _, err := orch.ConvertCurrentBook(context.Background(), opts); err != nil {
+			t.Fatalf("run %d: conversion failed: %v", run, err)
+		}
+		if len(auto.Sessions) != run || auto.Sessions[run-1] == nil {
+			t.Fatalf("run %d: expected a session per run, got %v", run, auto.Sessions)
+		}
+		if capturer.Options.Session != auto.Sessions[run-1] {
+			t.Errorf("run %d: expected the capturer to share the automation's session", run)
+		}
+	}
+	if auto.Sessions[0] == auto.Sessions[1] {
+		t.Error("expected a new session for the second run")
+	}
+}
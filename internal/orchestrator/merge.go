@@ -0,0 +1,69 @@
+package orchestrator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/oumi/k2p/internal/config"
+	"github.com/oumi/k2p/internal/pdf"
+)
+
+// mergePDFs concatenates the PDFs listed in InputFile, in order, into
+// OutputFile, e.g. to join the parts of a split book again. Every input is
+// validated before anything is written, and an existing output is only
+// replaced after confirmation
+func (o *DefaultOrchestrator) mergePDFs(options *config.ConversionOptions) (*ConversionResult, error) {
+	startTime := time.Now()
+
+	inputs := config.ParseInputFiles(options.InputFile)
+	if len(inputs) < 2 {
+		return nil, fmt.Errorf("at least two input PDFs are required for merge mode")
+	}
+	outputPath := options.OutputFile
+	if outputPath == "" {
+		return nil, fmt.Errorf("output file is required for merge mode")
+	}
+
+	absOutput, _ := filepath.Abs(outputPath)
+	for _, input := range inputs {
+		if absInput, _ := filepath.Abs(input); absInput == absOutput {
+			return nil, fmt.Errorf("output file %s is also an input", outputPath)
+		}
+		if err := pdf.ValidateFile(input); err != nil {
+			return nil, err
+		}
+	}
+
+	outputPath, proceed, err := o.claimOutput(outputPath, options)
+	if err != nil {
+		return nil, err
+	}
+	if !proceed {
+		return nil, fmt.Errorf("merge cancelled: file already exists")
+	}
+
+	o.printf("Merging %d PDFs...\n", len(inputs))
+	if err := pdf.MergeFiles(inputs, outputPath); err != nil {
+		return nil, err
+	}
+
+	result := &ConversionResult{
+		OutputPath: outputPath,
+		Warnings:   []string{},
+	}
+	if result.PageCount, err = pdf.PageCount(outputPath); err != nil {
+		return nil, err
+	}
+	if info, err := os.Stat(outputPath); err == nil {
+		result.FileSize = info.Size()
+	}
+	result.Duration = time.Since(startTime)
+
+	o.printf("Output: %s\n", outputPath)
+	o.printf("Pages: %d\n", result.PageCount)
+	o.printf("Size: %.2f MB\n", float64(result.FileSize)/(1024*1024))
+
+	return result, nil
+}
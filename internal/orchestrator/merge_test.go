@@ -0,0 +1,59 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/oumi/k2p/internal/config"
+	"github.com/oumi/k2p/internal/pdf"
+	"github.com/oumi/k2p/internal/screenshot"
+)
+
+// Merge mode: inputs are concatenated in order without touching Kindle
+func TestMergeMode(t *testing.T) {
+	tmpDir := t.TempDir()
+	var parts []string
+	for i := 0; i < 2; i++ {
+		part := filepath.Join(tmpDir, fmt.Sprintf("part%d.pdf", i+1))
+		orch := newTestOrchestrator(t, screenshot.NewSyntheticCapturer(6))
+		orch.fileManager = &MockFileManager{ResolvePath: part, HandleExists: true}
+		orch.pdfGen = pdf.NewPDFGenerator()
+		if _, err := orch.ConvertCurrentBook(context.Background(), generateOptions()); err != nil {
+			t.Fatalf("conversion failed: %v", err)
+		}
+		parts = append(parts, part)
+	}
+
+	// Kindle is not even installed: merge must not check for it
+	orch := newTestOrchestrator(t, nil)
+	orch.automation = &MockAutomation{}
+	orch.fileManager = &MockFileManager{HandleExists: true}
+	output := filepath.Join(tmpDir, "merged.pdf")
+	result, err := orch.ConvertCurrentBook(context.Background(), &config.ConversionOptions{
+		Mode: "merge", InputFile: strings.Join(parts, ","), OutputFile: output,
+	})
+	if err != nil {
+		t.Fatalf("merge failed: %v", err)
+	}
+
+	if _, err := orch.ConvertCurrentBook(context.Background(), &config.ConversionOptions{
+		Mode: "merge", InputFile: parts[0] + "," + output, OutputFile: output,
+	}); err == nil {
+		t.Error("expected error when the output is also an input")
+	}
+
+	var want int
+	for _, part := range parts {
+		count, err := pdf.PageCount(part)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want += count
+	}
+	if result.PageCount != want {
+		t.Errorf("expected %d merged pages, got %d", want, result.PageCount)
+	}
+}
@@ -0,0 +1,36 @@
+package orchestrator
+
+import (
+	"fmt"
+
+	"github.com/oumi/k2p/internal/config"
+)
+
+// notificationTitle is the title of every k2p notification
+const notificationTitle = "k2p"
+
+// notifyResult posts a notification summarizing a finished conversion
+// Notifications are best effort: a failure is only logged in verbose mode
+func (o *DefaultOrchestrator) notifyResult(options *config.ConversionOptions, result *ConversionResult, err error) {
+	if o.notifier == nil {
+		return
+	}
+
+	var message string
+	switch {
+	case err != nil:
+		message = fmt.Sprintf("Failed: %v", err)
+	case result == nil:
+		return
+	case options.Mode == "detect":
+		message = fmt.Sprintf("Margin detection finished (%d pages)", result.PageCount)
+	case options.Mode == "benchmark":
+		message = fmt.Sprintf("Benchmark finished: page delay %dms recommended", result.RecommendedPageDelay.Milliseconds())
+	default:
+		message = fmt.Sprintf("Done: %d pages → %s", result.PageCount, result.OutputPath)
+	}
+
+	if nerr := o.notifier.Notify(notificationTitle, message); nerr != nil && options.Verbose {
+		o.printf("Warning: %v\n", nerr)
+	}
+}
@@ -0,0 +1,54 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// recordingNotifier keeps every posted notification
+type recordingNotifier struct {
+	messages []string
+}
+
+func (n *recordingNotifier) Notify(title, message string) error {
+	n.messages = append(n.messages, message)
+	return nil
+}
+
+// Notify: one notification per conversion, with the page count or the error
+func TestNotify(t *testing.T) {
+	notifier := &recordingNotifier{}
+	newOrch := func(auto *MockAutomation) *DefaultOrchestrator {
+		orch := newTestOrchestrator(t, &MockCapturer{})
+		orch.automation = auto
+		orch.notifier = notifier
+		return orch
+	}
+	opts := generateOptions()
+	opts.Notify = true
+
+	orch := newOrch(readyAutomation())
+	result, err := orch.ConvertCurrentBook(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("conversion failed: %v", err)
+	}
+	if len(notifier.messages) != 1 || !strings.Contains(notifier.messages[0], fmt.Sprintf("%d pages", result.PageCount)) ||
+		!strings.Contains(notifier.messages[0], result.OutputPath) {
+		t.Errorf("unexpected success notification: %q", notifier.messages)
+	}
+
+	newOrch(&MockAutomation{Installed: false}).ConvertCurrentBook(context.Background(), opts)
+	if len(notifier.messages) != 2 || !strings.HasPrefix(notifier.messages[1], "Failed:") {
+		t.Errorf("unexpected failure notification: %q", notifier.messages)
+	}
+
+	// Off by default
+	quiet := *opts
+	quiet.Notify = false
+	newOrch(readyAutomation()).ConvertCurrentBook(context.Background(), &quiet)
+	if len(notifier.messages) != 2 {
+		t.Errorf("expected no notification without Notify, got %q", notifier.messages)
+	}
+}
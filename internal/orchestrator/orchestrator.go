@@ -2,18 +2,26 @@ package orchestrator
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/oumi/k2p/internal/automation"
 	"github.com/oumi/k2p/internal/config"
+	"github.com/oumi/k2p/internal/epub"
 	"github.com/oumi/k2p/internal/filemanager"
 	"github.com/oumi/k2p/internal/imageprocessing"
+	"github.com/oumi/k2p/internal/notify"
+	"github.com/oumi/k2p/internal/ocr"
 	"github.com/oumi/k2p/internal/pdf"
+	"github.com/oumi/k2p/internal/preflight"
 	"github.com/oumi/k2p/internal/screenshot"
 	"github.com/oumi/k2p/internal/sound"
+	"github.com/oumi/k2p/internal/version"
 )
 
 // ConversionResult contains the result of a conversion
@@ -27,6 +35,9 @@ type ConversionResult struct {
 	// Total conversion duration
 	Duration time.Duration
 
+	// Time spent in the capture loop (capture + page turn for every page)
+	CaptureDuration time.Duration
+
 	// Output file size in bytes
 	FileSize int64
 
@@ -35,12 +46,36 @@ type ConversionResult struct {
 
 	// DetectedMargins contains the analysis result from detect mode
 	DetectedMargins *imageprocessing.TrimMargins
+
+	// RecommendedPageDelay contains the result of benchmark mode
+	RecommendedPageDelay time.Duration
+
+	// Version of the reader app the book was captured from (empty if unknown)
+	AppVersion string
 }
 
 // ConversionOrchestrator coordinates the entire conversion workflow
 type ConversionOrchestrator interface {
 	// ConvertCurrentBook converts the currently open book to PDF
 	ConvertCurrentBook(ctx context.Context, options *config.ConversionOptions) (*ConversionResult, error)
+
+	// ConvertBatch converts several books in a row, asking nextBook before each
+	// book after the first, and returns one entry per attempted book
+	ConvertBatch(ctx context.Context, options *config.ConversionOptions, nextBook NextBookFunc) (*BatchResult, error)
+
+	// Watch polls the open book title and converts each newly opened book
+	// until the context is cancelled
+	Watch(ctx context.Context, options *config.ConversionOptions) (*BatchResult, error)
+
+	// SetFocusLostPrompt sets how "pause" mode waits for the user after Kindle
+	// loses the foreground
+	SetFocusLostPrompt(prompt FocusLostFunc)
+
+	// SetProgressFunc sets a callback invoked after each captured page
+	SetProgressFunc(fn ProgressFunc)
+
+	// SetLogWriter sets where status and progress messages are written
+	SetLogWriter(w io.Writer)
 }
 
 // DefaultOrchestrator is the default implementation
@@ -50,6 +85,26 @@ type DefaultOrchestrator struct {
 	pdfGen      pdf.PDFGenerator
 	capturer    screenshot.Capturer
 	soundPlayer sound.Player
+	recognizer  ocr.TextRecognizer
+	epubGen     epub.EPUBGenerator
+
+	// Permission preflight (nil skips it, e.g. with injected test dependencies)
+	permissions preflight.PermissionChecker
+
+	// Waits for the user when Kindle loses focus in "pause" mode (nil = stdin)
+	focusLostPrompt FocusLostFunc
+
+	// External PDF optimizer for the Optimize option (nil skips it)
+	optimizer pdf.Optimizer
+
+	// Called after each captured page (nil = no callback)
+	progress ProgressFunc
+
+	// Destination for status and progress messages (nil = os.Stdout)
+	logWriter io.Writer
+
+	// Desktop notifications for the Notify option (nil skips them)
+	notifier notify.Notifier
 }
 
 // NewOrchestrator creates a new conversion orchestrator
@@ -60,6 +115,11 @@ func NewOrchestrator() ConversionOrchestrator {
 		pdfGen:      pdf.NewPDFGenerator(),
 		capturer:    screenshot.NewCapturer(),
 		soundPlayer: sound.NewPlayer(),
+		recognizer:  ocr.NewTextRecognizer(),
+		epubGen:     epub.NewEPUBGenerator(),
+		permissions: preflight.NewPermissionChecker(),
+		optimizer:   pdf.NewOptimizer(),
+		notifier:    notify.NewNotifier(),
 	}
 }
 
@@ -78,27 +138,137 @@ func NewOrchestratorWithDeps(
 		pdfGen:      pg,
 		capturer:    cap,
 		soundPlayer: sp,
+		recognizer:  ocr.NewTextRecognizer(),
+		epubGen:     epub.NewEPUBGenerator(),
+	}
+}
+
+// endDetectionSimilarity is the similarity at or above which consecutive pages
+// count as identical for end-of-book detection. It is deliberately much stricter
+// than DirectionChangeThreshold: the rating screens after the last page are
+// 100% identical, while real pages can be nearly identical (e.g. a blank page
+// followed by one with a single line), which must not end the book early
+const endDetectionSimilarity = 0.995
+
+// stallSimilarity is the similarity at or above which consecutive pages count
+// as the same page for the StallPages watchdog. Looser than end detection, so
+// a frozen page with a blinking cursor or progress indicator still counts
+const stallSimilarity = 0.98
+
+// SyntheticPages is the number of distinct pages produced by the synthetic backend
+const SyntheticPages = 12
+
+// NewOrchestratorForBackend creates an orchestrator for a capture backend
+// "" or "screen" is the normal macOS backend. "synthetic" replaces Kindle and
+// screen capture with generated pages so the full pipeline, including PDF
+// output, runs on any OS; it is meant for CI and is not exposed in the UI
+func NewOrchestratorForBackend(backend string) (ConversionOrchestrator, error) {
+	switch backend {
+	case "", "screen":
+		return NewOrchestrator(), nil
+	case "synthetic":
+		return NewOrchestratorWithDeps(
+			automation.NewSyntheticAutomation(),
+			filemanager.NewFileManager(),
+			pdf.NewPDFGenerator(),
+			screenshot.NewSyntheticCapturer(SyntheticPages),
+			sound.NewNoOpPlayer(),
+		), nil
+	default:
+		return nil, fmt.Errorf("unknown capture backend: %s (use screen or synthetic)", backend)
 	}
 }
 
 // ConvertCurrentBook implements the main conversion workflow
 func (o *DefaultOrchestrator) ConvertCurrentBook(ctx context.Context, options *config.ConversionOptions) (*ConversionResult, error) {
+	if options.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, options.Timeout)
+		defer cancel()
+	}
+	result, err := o.convertCurrentBook(ctx, options)
+	if options.Notify {
+		o.notifyResult(options, result, err)
+	}
+	if options.Webhook != "" {
+		o.postWebhook(options, result, err)
+	}
+	return result, err
+}
+
+// convertCurrentBook runs the conversion for ConvertCurrentBook
+func (o *DefaultOrchestrator) convertCurrentBook(ctx context.Context, options *config.ConversionOptions) (*ConversionResult, error) {
 	startTime := time.Now()
 	result := &ConversionResult{
 		Warnings: []string{},
 	}
 
+	// Trim preview works on an image file and needs no Kindle
+	if options.Mode == "trim-preview" {
+		return o.trimPreview(options)
+	}
+
+	// Merging existing PDFs needs no Kindle either
+	if options.Mode == "merge" {
+		return o.mergePDFs(options)
+	}
+
+	// Image directories and CBZ/ZIP archives go straight to a PDF
+	if options.Mode == "images2pdf" {
+		return o.imagesToPDF(options)
+	}
+
+	// The doctor reports on every check instead of stopping at the first
+	if options.Mode == "doctor" {
+		return o.doctor(options)
+	}
+
+	// Reject an unusable diff region or similarity metric before capturing any pages
+	if _, err := config.ParseDiffRegion(options.DiffRegion); err != nil {
+		return nil, err
+	}
+	if _, err := config.ParseDiffRegion(options.PageNumberRegion); err != nil {
+		return nil, fmt.Errorf("page number region: %w", err)
+	}
+	if _, err := imageprocessing.NewSizedComparer(options.SimilarityAlgorithm, options.SimilaritySize); err != nil {
+		return nil, err
+	}
+
+	// The PDF to append to must be readable before capturing any pages
+	if options.AppendTo != "" {
+		if err := pdf.ValidateFile(options.AppendTo); err != nil {
+			return nil, err
+		}
+	}
+
+	// Reject an impossible PDF layout before capturing any pages
+	if options.OutputFormat != "epub" && (options.Mode == "" || rendersPages(options)) {
+		if err := pdf.ValidateLayout(pdfOptions(options)); err != nil {
+			return nil, err
+		}
+	}
+
+	// A hung osascript call fails after this instead of blocking the run
+	o.automation.SetCommandTimeout(options.CommandTimeout)
+	o.automation.SetTarget(automation.TargetForApp(options.TargetApp))
+	// A new session per run makes the startup check a full one
+	session := o.newSession(options)
+	o.automation.SetSession(session)
+
 	// Step 1: Display preparation instructions
-	fmt.Println("=== Kindle to PDF Converter ===")
-	fmt.Println("\nPlease ensure:")
-	fmt.Println("  1. Kindle app is running")
-	fmt.Println("  2. A book is open in Kindle")
-	fmt.Println("  3. Kindle app is in the foreground")
-	fmt.Println()
+	o.println("=== Kindle to PDF Converter ===")
+	if options.Verbose {
+		o.println(version.Get().Short())
+	}
+	o.println("\nPlease ensure:")
+	o.println("  1. Kindle app is running")
+	o.println("  2. A book is open in Kindle")
+	o.println("  3. Kindle app is in the foreground")
+	o.println()
 
 	// Step 2: Wait for user confirmation
 	if !options.AutoConfirm {
-		fmt.Print("Press Enter when ready to begin conversion...")
+		o.print("Press Enter when ready to begin conversion...")
 		fmt.Scanln()
 	}
 
@@ -111,45 +281,65 @@ func (o *DefaultOrchestrator) ConvertCurrentBook(ctx context.Context, options *c
 		}
 	}
 
-	// Step 4: Validate Kindle app state
-	if err := o.validateKindleState(options.Verbose); err != nil {
-		o.soundPlayer.PlayError()
-		return nil, err
-	}
+	// Time per stage for VerboseTiming, printed with the summary
+	timer := newStageTimer(options.VerboseTiming)
 
-	// Step 5: Check disk space
-	estimatedSize := int64(100 * 1024 * 1024) // Estimate 100MB for safety
-	outputDir := options.OutputDir
-	if outputDir == "" {
-		var err error
-		outputDir, err = os.Getwd()
-		if err != nil {
-			return nil, fmt.Errorf("failed to get current directory: %w", err)
+	// Step 3b: Check Screen Recording and Accessibility permissions
+	stopChecks := timer.start(stageChecks)
+	if o.permissions != nil && !options.SkipPreflight {
+		if err := o.checkPermissions(options.Verbose); err != nil {
+			o.soundPlayer.PlayError()
+			return nil, &EnvironmentError{Err: err}
 		}
 	}
 
-	if err := o.fileManager.CheckDiskSpace(outputDir, estimatedSize); err != nil {
+	// Step 4: Validate Kindle app state
+	if err := o.validateKindleState(options.Verbose); err != nil {
 		o.soundPlayer.PlayError()
-		return nil, err
+		return nil, &EnvironmentError{Err: err}
 	}
+	result.AppVersion = o.appVersion(options.Verbose)
 
-	// Step 6: Resolve output path
-	outputPath, err := o.fileManager.ResolveOutputPath(outputDir)
+	// Step 5: Check disk space
+	outputDir, err := outputDirFor(options)
 	if err != nil {
-		return nil, fmt.Errorf("failed to resolve output path: %w", err)
+		return nil, err
 	}
-
-	// Check if file exists
-	proceed, err := o.fileManager.HandleExistingFile(outputPath, options.AutoConfirm)
-	if err != nil {
+	if err := o.fileManager.CheckDiskSpace(outputDir, estimatedOutputSize); err != nil {
+		o.soundPlayer.PlayError()
 		return nil, err
 	}
-	if !proceed {
-		return nil, fmt.Errorf("conversion cancelled: file already exists")
+	stopChecks()
+
+	// Step 6: Resolve output path (appending writes into the existing PDF)
+	outputPath := options.AppendTo
+	if outputPath == "" {
+		var err error
+		outputPath, err = o.resolveOutputPath(outputDir, options)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	result.OutputPath = outputPath
 
+	// Apply capture settings (quality lower than 100 stores JPEG)
+	o.capturer.Configure(screenshot.CaptureOptions{
+		Quality:            options.ScreenshotQuality,
+		Format:             options.CaptureFormat,
+		ActivationTimeout:  options.ActivationTimeout,
+		ActivationAttempts: options.ActivationAttempts,
+		SpaceSwitchSettle:  options.SpaceSwitchSettle,
+		CommandTimeout:     options.CommandTimeout,
+		Target:             automation.TargetForApp(options.TargetApp),
+		Session:            session,
+	})
+
+	// First page only: one capture straight to the output, nothing else
+	if options.FirstPageOnly {
+		return o.captureFirstPage(ctx, outputPath, options, startTime)
+	}
+
 	// Step 7: Create temporary directory
 	tempDir, err := o.fileManager.CreateTempDir()
 	if err != nil {
@@ -157,9 +347,65 @@ func (o *DefaultOrchestrator) ConvertCurrentBook(ctx context.Context, options *c
 	}
 	defer o.fileManager.CleanupTempDir(tempDir)
 
+	// Benchmark mode: measure the page delay instead of capturing the book
+	if options.Mode == "benchmark" {
+		lowest, steps, err := o.benchmarkPageDelay(ctx, tempDir, options)
+		if err != nil {
+			o.soundPlayer.PlayError()
+			return nil, fmt.Errorf("benchmark failed: %w", err)
+		}
+
+		result.PageCount = len(steps) + 1
+		result.RecommendedPageDelay = lowest + benchmarkSafetyMargin
+		result.Duration = time.Since(startTime)
+
+		o.println("\n=== Benchmark Complete ===")
+		o.printf("Lowest reliable delay: %dms\n", lowest.Milliseconds())
+		o.printf("Recommended page delay: %dms (+%dms safety margin)\n",
+			result.RecommendedPageDelay.Milliseconds(), benchmarkSafetyMargin.Milliseconds())
+		o.printf("Duration: %s\n", result.Duration.Round(time.Second))
+
+		o.soundPlayer.PlaySuccess()
+		return result, nil
+	}
+
 	// Step 8: Page capture loop
-	pageCount, screenshots, margins, allMargins, err := o.capturePages(ctx, tempDir, options)
+	captureStart := time.Now()
+	// Index repeated pages when they are removed or reported
+	var dups *duplicateIndex
+	if options.DedupAll || options.Verbose {
+		dups = newDuplicateIndex(diffRegion(options))
+	}
+	// Recoverable per-page problems, reported as warnings at the end
+	issues := newPageIssues()
+	// Pages captured so far, kept as a PDF in case the run does not finish
+	partial := o.newPartialPDF(outputPath, tempDir, options)
+	// Decoded pages the PDF is written from, when they fit (InMemoryPages)
+	buffer := o.newPageBuffer(options)
+	pageCount, screenshots, margins, allMargins, err := o.capturePages(ctx, tempDir, options, dups, issues, partial, buffer, timer)
+	result.CaptureDuration = time.Since(captureStart)
+	// Out of time: keep the pages captured so far instead of losing the run
+	if errors.Is(err, context.DeadlineExceeded) && len(screenshots) > 0 {
+		warning := fmt.Sprintf("Timed out after %s; the output has only the %d pages captured", options.Timeout, len(screenshots))
+		o.printf("\nWarning: %s\n", warning)
+		result.Warnings = append(result.Warnings, warning)
+		err = nil
+	}
+	if errors.Is(err, ErrPagesStalled) && len(screenshots) > 0 {
+		warning := fmt.Sprintf("Pages stopped changing for %d captures (Kindle may have frozen or the end of the book was missed); the output has the %d pages captured before",
+			options.StallPages, len(screenshots))
+		o.printf("\nWarning: %s\n", warning)
+		result.Warnings = append(result.Warnings, warning)
+		err = nil
+	}
 	if err != nil {
+		if partial != nil {
+			if ferr := partial.flush(screenshots); ferr != nil {
+				o.printf("Warning: %v\n", ferr)
+			} else if partial.flushed > 0 {
+				o.printf("\nThe %d pages captured so far are in %s\n", partial.flushed, partial.path)
+			}
+		}
 		o.soundPlayer.PlayError()
 		return nil, fmt.Errorf("failed to capture pages: %w", err)
 	}
@@ -167,31 +413,53 @@ func (o *DefaultOrchestrator) ConvertCurrentBook(ctx context.Context, options *c
 	result.PageCount = pageCount
 
 	if options.Verbose {
-		fmt.Printf("\nCaptured %d pages\n", pageCount)
+		o.printf("\nCaptured %d pages\n", pageCount)
 	}
 
 	// Step 9: Handle mode-specific workflow
 	if options.Mode == "detect" {
 		// Detection mode: analyze margins and report, no PDF generation
-		fmt.Println("\n=== Margin Analysis Complete ===")
-		fmt.Printf("Analyzed %d pages\n", pageCount)
+		// Direction detection frames (cover etc.) are captured before the
+		// capture loop and are never margin-analyzed, so allMargins only
+		// covers the pages after them
+		detectionFrames := len(screenshots) - len(allMargins)
+
+		o.println("\n=== Margin Analysis Complete ===")
+		o.printf("Analyzed %d pages", len(allMargins))
+		if detectionFrames > 0 {
+			o.printf(" (%d direction detection pages excluded)", detectionFrames)
+		}
+		o.println()
 
 		// Show per-page margins if verbose
 		if options.Verbose && len(allMargins) > 0 {
-			fmt.Println("\nPer-page margin details:")
+			o.println("\nPer-page margin details:")
 			for i, m := range allMargins {
-				fmt.Printf("  Page %3d: Top=%3d Bottom=%3d Left=%3d Right=%3d\n",
-					i+1, m.Top, m.Bottom, m.Left, m.Right)
+				o.printf("  Page %3d: Top=%3d Bottom=%3d Left=%3d Right=%3d\n",
+					detectionFrames+i+1, m.Top, m.Bottom, m.Left, m.Right)
 			}
 		}
 
-		fmt.Printf("\nMinimum removable margins (safe for all pages):\n")
-		fmt.Printf("  Top:    %d pixels\n", margins.Top)
-		fmt.Printf("  Bottom: %d pixels\n", margins.Bottom)
-		fmt.Printf("  Left:   %d pixels\n", margins.Left)
-		fmt.Printf("  Right:  %d pixels\n", margins.Right)
+		o.printMarginStats(allMargins, detectionFrames+1)
+
+		// Report the safe minimum next to the percentiles, which ignore outlier
+		// pages; the selected aggregation drives the suggestion below
+		o.printf("\nRemovable margins (pixels):\n")
+		o.printf("  %-38s %6s %6s %6s %6s\n", "", "Top", "Bottom", "Left", "Right")
+		for _, row := range []struct{ mode, label string }{
+			{"min", "min (safe for all pages)"},
+			{"p10", "p10 (10% of pages cropped into)"},
+			{"p25", "p25 (25% of pages cropped into)"},
+		} {
+			m := aggregateMargins(allMargins, row.mode)
+			label := row.label
+			if row.mode == options.TrimAggregate || (row.mode == "min" && options.TrimAggregate == "") {
+				label += " *"
+			}
+			o.printf("  %-38s %6d %6d %6d %6d\n", label, m.Top, m.Bottom, m.Left, m.Right)
+		}
 
-		fmt.Printf("\nTo generate PDF with these margins, run:\n")
+		o.printf("\nTo generate PDF with these margins, run:\n")
 		// Calculate max of left and right for suggestion (since we use trim-horizontal for PDF)
 		maxHorizontal := margins.Left
 		if margins.Right > maxHorizontal {
@@ -200,17 +468,18 @@ func (o *DefaultOrchestrator) ConvertCurrentBook(ctx context.Context, options *c
 
 		// Provide clear context about horizontal trimming
 		if margins.Left != margins.Right {
-			fmt.Printf("  (Note: PDF generation uses symmetric horizontal trimming. Using max(%d, %d) = %d)\n",
+			o.printf("  (Note: PDF generation uses symmetric horizontal trimming. Using max(%d, %d) = %d)\n",
 				margins.Left, margins.Right, maxHorizontal)
 		}
 
-		fmt.Printf("  k2p --mode generate --trim-top %d --trim-bottom %d --trim-horizontal %d\n",
+		o.printf("  k2p --mode generate --trim-top %d --trim-bottom %d --trim-horizontal %d\n",
 			margins.Top, margins.Bottom, maxHorizontal)
 
 		result.Duration = time.Since(startTime)
 		result.DetectedMargins = &margins // Store for GUI
+		result.Warnings = append(result.Warnings, issues.warnings()...)
 
-		fmt.Printf("\nDuration: %s\n", result.Duration.Round(time.Second))
+		o.printf("\nDuration: %s\n", result.Duration.Round(time.Second))
 
 		// Play completion sound
 		o.soundPlayer.PlaySuccess()
@@ -218,50 +487,231 @@ func (o *DefaultOrchestrator) ConvertCurrentBook(ctx context.Context, options *c
 		return result, nil
 	}
 
+	// Steps 9b-9e and 10b-10e are timed together, trimming on its own
+	stopProcessing := timer.start(stageProcessing)
+
+	// Step 9b: Drop the cover page (generate mode only)
+	if options.SkipCover {
+		if len(screenshots) > 1 {
+			os.Remove(screenshots[0])
+			screenshots = screenshots[1:]
+			result.PageCount = len(screenshots)
+			if options.Verbose {
+				o.println("\nSkipping cover page")
+			}
+		} else {
+			result.Warnings = append(result.Warnings, "Cover not skipped: it is the only captured page")
+		}
+	}
+
+	// Step 9c: Collapse pages repeated elsewhere in the book
+	if dups != nil {
+		repeated := make(map[string]bool)
+		for _, dup := range dups.duplicates {
+			repeated[dup.Path] = true
+		}
+		kept := screenshots[:0:0]
+		for _, path := range screenshots {
+			if !repeated[path] {
+				kept = append(kept, path)
+			}
+		}
+		if removed := len(screenshots) - len(kept); removed > 0 {
+			if options.DedupAll {
+				for path := range repeated {
+					os.Remove(path)
+				}
+				screenshots = kept
+				result.PageCount = len(screenshots)
+				o.printf("\nRemoved %d repeated pages\n", removed)
+			} else {
+				o.printf("\nFound %d pages repeating earlier pages (enable Dedup All to remove them)\n", removed)
+			}
+		}
+	}
+
+	// With every page still decoded, steps 9d-10d work on the images and the
+	// PDF is written from them; otherwise they go through the page files
+	images, inMemory := buffer.take(screenshots)
+
+	// Step 9d: Rotate pages before trimming, so trim margins apply to the final orientation
+	if rendersPages(options) && !inMemory && options.Rotate%360 != 0 {
+		if options.Verbose {
+			o.printf("\nRotating %d pages by %d°...\n", len(screenshots), options.Rotate)
+		}
+
+		rotatedScreenshots := make([]string, len(screenshots))
+		forEachPage(len(screenshots), options.MaxConcurrency, func(i int) {
+			rotatedPath := filepath.Join(tempDir, fmt.Sprintf("page_%04d_rotated.png", i+1))
+			if err := imageprocessing.RotateImageFile(screenshots[i], rotatedPath, options.Rotate); err != nil {
+				issues.add(issueRotate)
+				if options.Verbose {
+					o.printf("  Warning: Failed to rotate page %d, using original: %v\n", i+1, err)
+				}
+				rotatedScreenshots[i] = screenshots[i]
+				return
+			}
+			rotatedScreenshots[i] = rotatedPath
+			os.Remove(screenshots[i])
+		})
+		screenshots = rotatedScreenshots
+	}
+
+	// Step 9e: Colour adjustments (invert, paper normalization), before
+	// trimming so the trimmed page files are final
+	if rendersPages(options) && !inMemory {
+		screenshots = o.adjustColors(screenshots, tempDir, options, issues)
+	}
+
+	// Steps 9d-10 on the decoded pages
+	if inMemory {
+		o.processImages(images, options, issues)
+	}
+
+	stopProcessing()
+
 	// Step 10: Apply custom trimming to all screenshots (if specified)
 	// This is done AFTER capture to avoid interfering with end-of-book detection
-	hasCustomTrim := options.Mode == "generate" &&
+	hasCustomTrim := rendersPages(options) && !inMemory &&
 		(options.TrimTop != 0 || options.TrimBottom != 0 || options.TrimHorizontal != 0)
 
+	stopTrim := timer.start(stageTrim)
 	if hasCustomTrim {
 		if options.Verbose {
-			fmt.Printf("\nApplying custom trimming to %d pages...\n", len(screenshots))
-			fmt.Printf("  Trim margins: Top=%d Bottom=%d Horizontal=%d (applied to Left/Right)\n",
+			o.printf("\nApplying custom trimming to %d pages...\n", len(screenshots))
+			o.printf("  Trim margins: Top=%d Bottom=%d Horizontal=%d (applied to Left/Right)\n",
 				options.TrimTop, options.TrimBottom, options.TrimHorizontal)
 		}
 
-		trimmedScreenshots := make([]string, 0, len(screenshots))
-		for i, screenshot := range screenshots {
+		// Pages are trimmed in parallel (bounded by MaxConcurrency) into their own slots
+		trimmedScreenshots := make([]string, len(screenshots))
+		forEachPage(len(screenshots), options.MaxConcurrency, func(i int) {
+			screenshot := screenshots[i]
+
+			// Keep the cover full-bleed
+			if i == 0 && options.NoTrimCover && !options.SkipCover {
+				trimmedScreenshots[i] = screenshot
+				return
+			}
+
 			trimmedPath := filepath.Join(tempDir, fmt.Sprintf("page_%04d_trimmed.png", i+1))
 			if err := o.trimScreenshotWithCustomMargins(screenshot, trimmedPath,
 				options.TrimTop, options.TrimBottom, options.TrimHorizontal, options.TrimHorizontal, false); err != nil {
 
+				issues.add(issueTrim)
 				if options.Verbose {
-					fmt.Printf("  Warning: Failed to trim page %d, using original: %v\n", i+1, err)
+					o.printf("  Warning: Failed to trim page %d, using original: %v\n", i+1, err)
 				}
-				trimmedScreenshots = append(trimmedScreenshots, screenshot)
+				trimmedScreenshots[i] = screenshot
 			} else {
-				trimmedScreenshots = append(trimmedScreenshots, trimmedPath)
+				trimmedScreenshots[i] = trimmedPath
 				// Remove original to save space
 				os.Remove(screenshot)
 			}
-		}
+		})
 		screenshots = trimmedScreenshots
 
 		if options.Verbose {
-			fmt.Printf("✓ Trimming complete\n")
+			o.printf("✓ Trimming complete\n")
 		}
 	}
 
-	// Step 11: Generate PDF (generate mode only)
-	fmt.Println("\nGenerating PDF...")
-	pdfOpts := pdf.GetQualitySettings(options.PDFQuality)
-	if err := o.pdfGen.CreatePDF(screenshots, outputPath, pdfOpts); err != nil {
-		o.soundPlayer.PlayError()
-		return nil, fmt.Errorf("failed to generate PDF: %w", err)
+	stopTrim()
+
+	// Step 10b: Reorient pages that differ from the rest of the book, after
+	// trimming so the orientation of the final page counts
+	stopProcessing = timer.start(stageProcessing)
+	if rendersPages(options) && options.AutoRotate {
+		var rotated int
+		screenshots, rotated = o.autoRotatePages(screenshots, tempDir, options, issues)
+		if rotated > 0 {
+			o.printf("\nRotated %d pages to match the book's orientation\n", rotated)
+		}
+	}
+
+	// Step 10c: Pad pages to one size, after trimming and reorienting so the
+	// final page sizes count
+	if rendersPages(options) && options.UniformPages {
+		var padded int
+		screenshots, padded = o.uniformPages(screenshots, tempDir, options, issues)
+		if padded > 0 {
+			o.printf("\nPadded %d pages to the common page size\n", padded)
+		}
+	}
+
+	// Step 10d: The PDF and EPUB writers take PNG/JPEG only, so store
+	// untouched BMP captures as PNG (decoded pages are encoded by the writer)
+	if rendersPages(options) && !inMemory {
+		screenshots = o.encodeBMPPages(screenshots, options, issues)
+	}
+
+	// Step 10e: Contact sheets tile small thumbnails, not full pages
+	pdfOpts := pdfOptions(options)
+	if options.Mode == "contact-sheet" {
+		screenshots = o.contactSheetThumbnails(screenshots, tempDir, options, issues)
+		pdfOpts.PageLabels = contactSheetLabels(len(screenshots), options.PageStep)
+	}
+	result.Warnings = append(result.Warnings, issues.warnings()...)
+	stopProcessing()
+
+	// Step 11: Generate output document (generate mode only)
+	stopOutput := timer.start(stageOutput)
+	if options.OutputFormat == "epub" {
+		o.println("\nGenerating EPUB...")
+		written, warnings, err := o.generateEPUB(screenshots, outputPath, tempDir, options)
+		result.Warnings = append(result.Warnings, warnings...)
+		if err != nil {
+			o.soundPlayer.PlayError()
+			return nil, fmt.Errorf("failed to generate EPUB: %w", err)
+		}
+		outputPath = written
+	} else {
+		o.println("\nGenerating PDF...")
+		// Writes the pages to path, from memory or from the page files
+		createPDF := func(path string) error {
+			if inMemory {
+				return o.pdfGen.CreatePDFFromImages(images, path, pdfOpts)
+			}
+			return o.pdfGen.CreatePDF(screenshots, path, pdfOpts)
+		}
+		if options.AppendTo != "" {
+			// The new pages are rendered to a temp PDF and merged onto the file
+			pdfPath := filepath.Join(tempDir, "append.pdf")
+			if err := createPDF(pdfPath); err != nil {
+				o.soundPlayer.PlayError()
+				return nil, fmt.Errorf("failed to generate PDF: %w", err)
+			}
+			o.printf("Appending %d pages to %s...\n", len(screenshots), outputPath)
+			if err := pdf.AppendFile(outputPath, pdfPath); err != nil {
+				o.soundPlayer.PlayError()
+				return nil, err
+			}
+		} else {
+			// A vanished output volume must not discard the captured pages
+			written, warning, err := o.writeOutput(outputPath, tempDir, createPDF)
+			if err != nil {
+				o.soundPlayer.PlayError()
+				return nil, fmt.Errorf("failed to generate PDF: %w", err)
+			}
+			if warning != "" {
+				o.printf("Warning: %s\n", warning)
+				result.Warnings = append(result.Warnings, warning)
+			}
+			outputPath = written
+		}
+		if options.Optimize {
+			if warning := o.optimizePDF(outputPath); warning != "" {
+				o.printf("Warning: %s\n", warning)
+				result.Warnings = append(result.Warnings, warning)
+			}
+		}
 	}
 
-	// Step 11: Get file size
+	stopOutput()
+
+	// Step 11: Get file size (of the fallback file if the output went there)
+	result.OutputPath = outputPath
+	partial.remove()
 	fileInfo, err := os.Stat(outputPath)
 	if err == nil {
 		result.FileSize = fileInfo.Size()
@@ -271,26 +721,192 @@ func (o *DefaultOrchestrator) ConvertCurrentBook(ctx context.Context, options *c
 
 	// Step 12: Wait for macOS to clear screen recording indicator (blue dot)
 	// The optimization removed the 2-second wait that previously allowed this
-	time.Sleep(1 * time.Second)
+	if options.PostCaptureDelay != nil && *options.PostCaptureDelay > 0 {
+		time.Sleep(*options.PostCaptureDelay)
+	}
 
 	// Step 13: Play completion sound
 	// Use macOS system sound to notify user (helpful when Kindle is in foreground)
 	o.soundPlayer.PlaySuccess()
 
 	// Step 14: Display success message
-	fmt.Println("\n=== Conversion Complete ===")
-	fmt.Printf("Output: %s\n", outputPath)
-	fmt.Printf("Pages: %d\n", len(screenshots)) // Show actual PDF page count
-	fmt.Printf("Size: %.2f MB\n", float64(result.FileSize)/(1024*1024))
-	fmt.Printf("Duration: %s\n", result.Duration.Round(time.Second))
+	if options.OutputSummary == "json" {
+		o.printSummaryJSON(options, result)
+		return result, nil
+	}
+	o.println("\n=== Conversion Complete ===")
+	o.printf("Output: %s\n", outputPath)
+	o.printf("Pages: %d\n", len(screenshots)) // Show actual PDF page count
+	o.printf("Size: %.2f MB\n", float64(result.FileSize)/(1024*1024))
+	o.printf("Duration: %s\n", result.Duration.Round(time.Second))
+	if result.PageCount > 0 {
+		o.printf("Throughput: %.1f pages/min\n", pagesPerMinute(result.PageCount, result.Duration))
+		o.printf("Avg per page: %s (capture + turn)\n",
+			(result.CaptureDuration / time.Duration(result.PageCount)).Round(time.Millisecond))
+	}
+	if len(result.Warnings) > 0 {
+		o.printf("Warnings: %d\n", len(result.Warnings))
+		for _, warning := range result.Warnings {
+			o.printf("  - %s\n", warning)
+		}
+	}
+	o.printTiming(timer, result.Duration)
 
 	return result, nil
 }
 
+// estimatedOutputSize is the free space required before a conversion starts
+// (100MB for safety)
+const estimatedOutputSize = int64(100 * 1024 * 1024)
+
+// outputDirFor returns the directory the output goes to: AppendTo's
+// directory, OutputDir, or the current directory
+func outputDirFor(options *config.ConversionOptions) (string, error) {
+	if options.AppendTo != "" {
+		return filepath.Dir(options.AppendTo), nil
+	}
+	if options.OutputDir != "" {
+		return options.OutputDir, nil
+	}
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("failed to get current directory: %w", err)
+	}
+	return dir, nil
+}
+
+// resolveOutputPath picks the output file in outputDir and confirms
+// overwriting an existing file
+func (o *DefaultOrchestrator) resolveOutputPath(outputDir string, options *config.ConversionOptions) (string, error) {
+	outputPath, err := o.fileManager.ResolveOutputPath(outputDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve output path: %w", err)
+	}
+	if options.OutputFileName != "" {
+		outputPath = filepath.Join(filepath.Dir(outputPath),
+			filemanager.SanitizeFileName(options.OutputFileName)+filepath.Ext(outputPath))
+	}
+	if options.FirstPageOnly {
+		outputPath = strings.TrimSuffix(outputPath, filepath.Ext(outputPath)) + ".png"
+	} else if options.OutputFormat == "epub" {
+		outputPath = strings.TrimSuffix(outputPath, filepath.Ext(outputPath)) + ".epub"
+	}
+
+	// Check if file exists
+	outputPath, proceed, err := o.claimOutput(outputPath, options)
+	if err != nil {
+		return "", err
+	}
+	if !proceed {
+		return "", fmt.Errorf("conversion cancelled: file already exists")
+	}
+	return outputPath, nil
+}
+
+// pagesPerMinute returns the conversion throughput (0 for an empty duration)
+func pagesPerMinute(pages int, d time.Duration) float64 {
+	if d <= 0 {
+		return 0
+	}
+	return float64(pages) / d.Minutes()
+}
+
+// rendersPages reports whether the mode writes the captured pages into an
+// output document: generate, or contact-sheet thumbnails
+func rendersPages(options *config.ConversionOptions) bool {
+	return options.Mode == "generate" || options.Mode == "contact-sheet"
+}
+
+// pdfOptions builds the PDF generator options from the conversion options
+func pdfOptions(options *config.ConversionOptions) pdf.PDFOptions {
+	pdfOpts := pdf.GetQualitySettings(options.PDFQuality)
+	pdfOpts.Producer = version.Get().Short()
+	pdfOpts.StampPageNumbers = options.StampPageNumbers
+	pdfOpts.StampPosition = options.StampPosition
+	pdfOpts.StampFontSize = options.StampFontSize
+	pdfOpts.PageSize = options.PageSize
+	pdfOpts.PageMargin = options.PageMargin
+	pdfOpts.Background, _ = config.ParseBackgroundColor(options.BackgroundColor)
+	pdfOpts.NUp = options.NUp
+	if options.Mode == "contact-sheet" {
+		pdfOpts.ContactSheetColumns = options.ContactSheetColumns
+	}
+	pdfOpts.DPI = float64(options.DPI)
+	// Decoded pages (InMemoryPages) are embedded like the captures
+	if screenshot.FileExtension(options.ScreenshotQuality, options.CaptureFormat) == ".jpg" {
+		pdfOpts.JPEGQuality = options.ScreenshotQuality
+	}
+	return pdfOpts
+}
+
+// optimizePDF shrinks the generated PDF with qpdf or gs and reports the sizes
+// Returns a warning instead of failing, since the unoptimized PDF is still usable
+func (o *DefaultOrchestrator) optimizePDF(outputPath string) string {
+	if o.optimizer == nil || !o.optimizer.IsInstalled() {
+		return "PDF optimization skipped: install qpdf (brew install qpdf) or ghostscript"
+	}
+
+	o.println("Optimizing PDF...")
+	before, after, err := o.optimizer.Optimize(outputPath)
+	if err != nil {
+		return fmt.Sprintf("PDF optimization failed, keeping unoptimized PDF: %v", err)
+	}
+
+	if after < before {
+		o.printf("Optimized: %.2f MB -> %.2f MB (%.0f%% smaller)\n",
+			float64(before)/(1024*1024), float64(after)/(1024*1024), 100*(1-float64(after)/float64(before)))
+	} else {
+		o.println("Optimization did not reduce the size; kept the original PDF")
+	}
+	return ""
+}
+
+// checkPermissions runs the permission preflight and prints what to fix
+func (o *DefaultOrchestrator) checkPermissions(verbose bool) error {
+	if verbose {
+		o.println("Checking macOS permissions...")
+	}
+
+	problems := preflight.Run(o.permissions)
+	if len(problems) == 0 {
+		if verbose {
+			o.println("✓ Screen Recording and Accessibility permissions look fine")
+		}
+		return nil
+	}
+
+	o.println("\nPermission problems detected:")
+	for _, p := range problems {
+		o.printf("  ✗ %v\n", p)
+		if hint := Hint(p); hint != "" {
+			o.printf("    → %s\n", hint)
+		}
+	}
+	o.println("(Skip this check with the Skip Preflight option if it is wrong for your setup)")
+
+	return errors.Join(problems...)
+}
+
+// appVersion returns the reader app's version for the log and the summary, so
+// reports can be matched to Kindle releases. A failed query only leaves it empty
+func (o *DefaultOrchestrator) appVersion(verbose bool) string {
+	version, err := o.automation.GetKindleVersion()
+	if err != nil {
+		if verbose {
+			o.printf("Warning: could not read the Kindle version: %v\n", err)
+		}
+		return ""
+	}
+	if verbose {
+		o.printf("Kindle version: %s\n", version)
+	}
+	return version
+}
+
 // validateKindleState validates that Kindle is ready for conversion
 func (o *DefaultOrchestrator) validateKindleState(verbose bool) error {
 	if verbose {
-		fmt.Println("Checking Kindle app state...")
+		o.println("Checking Kindle app state...")
 	}
 
 	// Check if Kindle is installed
@@ -299,7 +915,7 @@ func (o *DefaultOrchestrator) validateKindleState(verbose bool) error {
 		return fmt.Errorf("failed to check Kindle installation: %w", err)
 	}
 	if !installed {
-		return fmt.Errorf("Kindle app is not installed. Please install from the Mac App Store")
+		return fmt.Errorf("%w. Please install from the Mac App Store", automation.ErrKindleNotInstalled)
 	}
 
 	// Check if book is open
@@ -308,7 +924,7 @@ func (o *DefaultOrchestrator) validateKindleState(verbose bool) error {
 		return fmt.Errorf("failed to check if book is open: %w", err)
 	}
 	if !bookOpen {
-		return fmt.Errorf("no book is currently open in Kindle app. Please open a book and try again")
+		return fmt.Errorf("%w. Please open a book and try again", automation.ErrNoBookOpen)
 	}
 
 	// Check if Kindle is in foreground
@@ -317,11 +933,11 @@ func (o *DefaultOrchestrator) validateKindleState(verbose bool) error {
 		return fmt.Errorf("failed to check if Kindle is in foreground: %w", err)
 	}
 	if !inForeground {
-		return fmt.Errorf("Kindle app is not in foreground. Please bring Kindle to the front and try again")
+		return fmt.Errorf("%w. Please bring Kindle to the front and try again", automation.ErrKindleNotForeground)
 	}
 
 	if verbose {
-		fmt.Println("✓ Kindle app is ready")
+		o.println("✓ Kindle app is ready")
 	}
 
 	return nil
@@ -329,128 +945,291 @@ func (o *DefaultOrchestrator) validateKindleState(verbose bool) error {
 
 // capturePages captures all pages from the current book
 // Returns: pageCount, screenshot paths, aggregated margins, all page margins, error
-func (o *DefaultOrchestrator) capturePages(ctx context.Context, tempDir string, options *config.ConversionOptions) (int, []string, imageprocessing.TrimMargins, []imageprocessing.TrimMargins, error) {
+func (o *DefaultOrchestrator) capturePages(ctx context.Context, tempDir string, options *config.ConversionOptions, dups *duplicateIndex, issues *pageIssues, partial *partialPDF, buffer *pageBuffer, timer *stageTimer) (int, []string, imageprocessing.TrimMargins, []imageprocessing.TrimMargins, error) {
 	var screenshots []string
 	var allMargins []imageprocessing.TrimMargins
 	pageNum := 1
 	maxPages := 1000 // Safety limit
 	retryConfig := DefaultRetryConfig()
+	turnRetry := o.turnRetryConfig(options)
 
 	// Determine if we should apply custom trimming
 	// Allow 0 values - user can trim only specific edges
 	// Trimming is enabled if any trim value is non-zero
-	hasCustomTrim := options.Mode == "generate" &&
+	hasCustomTrim := rendersPages(options) &&
 		(options.TrimTop != 0 || options.TrimBottom != 0 || options.TrimHorizontal != 0)
 
 	// Debug: Show trimming configuration
 	if options.Verbose {
-		fmt.Printf("\n[DEBUG] Custom trimming configuration:\n")
-		fmt.Printf("  Mode:           %s\n", options.Mode)
-		fmt.Printf("  TrimTop:        %d\n", options.TrimTop)
-		fmt.Printf("  TrimBottom:     %d\n", options.TrimBottom)
-		fmt.Printf("  TrimHorizontal: %d\n", options.TrimHorizontal)
-		fmt.Printf("  hasCustomTrim:  %v\n", hasCustomTrim)
+		o.printf("\n[DEBUG] Custom trimming configuration:\n")
+		o.printf("  Mode:           %s\n", options.Mode)
+		o.printf("  TrimTop:        %d\n", options.TrimTop)
+		o.printf("  TrimBottom:     %d\n", options.TrimBottom)
+		o.printf("  TrimHorizontal: %d\n", options.TrimHorizontal)
+		o.printf("  hasCustomTrim:  %v\n", hasCustomTrim)
 	}
 
-	// Auto-detect page turn direction (unless explicitly set to "left")
-	direction := options.PageTurnKey
-	if direction != "left" {
+	// Auto-detect page turn direction unless "right" or "left" is configured
+	// An unset key is treated as "auto"
+	direction := config.NormalizePageTurnKey(options.PageTurnKey)
+	if direction == "" || direction == "auto" {
 		// Try to auto-detect
 		if options.Verbose {
-			fmt.Println("\nAuto-detecting page turn direction...")
+			o.println("\nAuto-detecting page turn direction...")
 		}
 
+		stopDirection := timer.start(stageDirection)
 		detectedDirection, detectionImages, err := o.detectPageTurnDirection(ctx, tempDir, retryConfig, options)
-		if err == nil && detectedDirection != "" {
-			direction = detectedDirection
-
-			// Add detection images to screenshots (no trimming needed since trimming is opt-in now)
-			screenshots = append(screenshots, detectionImages...)
-		} else {
-			direction = "right" // fallback to default
-			if options.Verbose {
-				fmt.Println("Using default direction: right")
+		stopDirection()
+		if errors.Is(err, ErrBlackCapture) {
+			return 0, nil, imageprocessing.TrimMargins{}, nil, err
+		}
+		if err != nil {
+			return 0, nil, imageprocessing.TrimMargins{}, nil, fmt.Errorf("%w (set the page turn key to \"right\" or \"left\" explicitly)", err)
+		}
+		direction = detectedDirection
+
+		// Keep the detection frames as the first pages, except the last one:
+		// Kindle is still showing that page, so the capture loop captures it again
+		// They are deliberately left out of allMargins: the cover and other
+		// detection frames would skew the aggregated margins
+		if len(detectionImages) > 0 {
+			screenshots = append(screenshots, detectionImages[:len(detectionImages)-1]...)
+		}
+		if buffer != nil {
+			for _, path := range screenshots {
+				if img, err := imageprocessing.LoadImage(path); err == nil {
+					buffer.add(path, img)
+				}
 			}
 		}
 	} else if options.Verbose {
-		fmt.Println("\nUsing configured direction: left")
+		o.printf("\nUsing configured direction: %s\n", direction)
+	}
+
+	// EndDetectionMinPages counts book pages; with PageStep every capture is
+	// PageStep pages further on. The 5-capture window stays as it is: past the
+	// end every step lands on the same end screen
+	endDetectionMinPages := options.EndDetectionMinPages
+	if options.PageStep > 1 {
+		endDetectionMinPages = (endDetectionMinPages + options.PageStep - 1) / options.PageStep
+	}
+	if endDetectionMinPages < 5 {
+		endDetectionMinPages = 5
+	}
+
+	// One page turn; with PageStep, the pages in between go in one batched call
+	turn := func() error {
+		if options.PageStep > 1 {
+			return o.automation.TurnPages(direction, options.PageStep)
+		}
+		return o.automation.TurnNextPage(direction)
 	}
 
-	fmt.Println("\nCapturing pages...")
+	o.println("\nCapturing pages...")
+
+	// Consecutive near-identical page pairs for the StallPages watchdog
+	stalled := 0
+
+	// Consecutive turns that left the page indicator (PageNumberRegion) as it was
+	numberRegion := pageNumberRegion(options)
+	numberStalled := 0
+
+	// End detection looks at the last 5 pages; keep exactly those decoded
+	cache := newPageCache(5)
+	cache.region = diffRegion(options)
+	cache.comparer = comparer(options)
 
 	// Activate Kindle once before starting page capture
 	// This ensures Kindle is in the foreground and waits for Space switching
-	fmt.Println("Activating Kindle app...")
+	o.println("Activating Kindle app...")
 	dummyPath := filepath.Join(tempDir, "activation_check.png")
+	stopActivation := timer.start(stageActivation)
 	if err := o.capturer.CaptureFrontmostWindow(dummyPath); err != nil {
 		return 0, nil, imageprocessing.TrimMargins{}, nil, fmt.Errorf("failed to activate Kindle: %w", err)
 	}
+	stopActivation()
 	// Remove the dummy screenshot
 	os.Remove(dummyPath)
-	fmt.Println("✓ Kindle is active and ready")
+	o.println("✓ Kindle is active and ready")
 
 	for pageNum <= maxPages {
 		// Check context cancellation
 		select {
 		case <-ctx.Done():
-			aggregatedMargins := imageprocessing.AggregateMinimumMargins(allMargins)
+			aggregatedMargins := aggregateMargins(allMargins, options.TrimAggregate)
 			return pageNum - 1, screenshots, aggregatedMargins, allMargins, ctx.Err()
 		default:
 		}
 
 		// Display progress
-		fmt.Printf("\rCapturing page %d...", pageNum)
+		o.printf("\rCapturing page %d...", pageNum)
 
 		// Capture screenshot with retry (without activation - much faster!)
-		screenshotPath := filepath.Join(tempDir, fmt.Sprintf("page_%04d.png", pageNum))
-		err := RetryWithBackoff(ctx, retryConfig, func() error {
-			return o.capturer.CaptureWithoutActivation(screenshotPath)
+		screenshotPath := filepath.Join(tempDir, fmt.Sprintf("page_%04d%s", pageNum, screenshot.FileExtension(options.ScreenshotQuality, options.CaptureFormat)))
+		attempts := 0
+		stopCapture := timer.start(stageCapture)
+		err := o.withFocusRecovery(options, func() error {
+			return RetryWithBackoff(ctx, retryConfig, func() error {
+				attempts++
+				return o.capturer.CaptureWithoutActivation(screenshotPath)
+			})
 		})
+		stopCapture()
 		if err != nil {
 			// CRITICAL: If we can't capture screenshots, the entire conversion is pointless
-			aggregatedMargins := imageprocessing.AggregateMinimumMargins(allMargins)
+			aggregatedMargins := aggregateMargins(allMargins, options.TrimAggregate)
 			return pageNum - 1, screenshots, aggregatedMargins, allMargins, fmt.Errorf("failed to capture page %d: %w", pageNum, err)
 		}
+		if attempts > 1 {
+			issues.add(issueCapture)
+		}
+		if pageNum == 1 && options.VerifyFirstCapture {
+			stopCapture = timer.start(stageCapture)
+			settled, err := o.verifyFirstCapture(ctx, screenshotPath, options, cache.comparer)
+			stopCapture()
+			if err != nil {
+				aggregatedMargins := aggregateMargins(allMargins, options.TrimAggregate)
+				return 0, screenshots, aggregatedMargins, allMargins, err
+			}
+			if !settled {
+				o.printf("\nWarning: the first page kept changing over %d captures; keeping the last one\n", firstCaptureChecks)
+				issues.add(issueUnsettled)
+			}
+		}
+		if pageNum == 1 && len(screenshots) == 0 {
+			if err := checkFirstCapture(screenshotPath, options.AllowBlackFirstPage); err != nil {
+				return 0, nil, imageprocessing.TrimMargins{}, nil, err
+			}
+		}
+
+		// A turn Kindle ignored leaves the previous page on screen
+		if options.AdvanceRetries > 0 && pageNum > 1 && len(screenshots) > 0 {
+			stopTurn := timer.start(stageTurn)
+			advanced, err := o.ensureAdvanced(ctx, screenshots[len(screenshots)-1], screenshotPath, options, cache, func() error {
+				return o.withFocusRecovery(options, func() error {
+					return RetryWithBackoff(ctx, turnRetry, turn)
+				})
+			})
+			stopTurn()
+			if err != nil {
+				aggregatedMargins := aggregateMargins(allMargins, options.TrimAggregate)
+				return pageNum - 1, screenshots, aggregatedMargins, allMargins, err
+			}
+			if advanced {
+				issues.add(issueAdvance)
+			}
+		}
 
-		// Calculate margins for this page (for detection mode or analysis)
-		margins, err := imageprocessing.CalculateTrimMarginsFromFile(screenshotPath)
-		if err != nil && options.Verbose {
-			fmt.Printf("\nWarning: Failed to calculate margins for page %d: %v\n", pageNum, err)
+		// Decode the page once; margins and end detection both use the cached image
+		var margins imageprocessing.TrimMargins
+		stopMargins := timer.start(stageMargins)
+		img, err := cache.get(screenshotPath)
+		if err != nil {
+			issues.add(issueMargins)
+			if options.Verbose {
+				o.printf("\nWarning: Failed to calculate margins for page %d: %v\n", pageNum, err)
+			}
+		} else {
+			// Report margins for the page as it will be after rotation
+			margins = imageprocessing.RotateMargins(imageprocessing.CalculateTrimMarginsWithOptions(img, trimOptions(options)), options.Rotate)
+			if pageNum == 1 && hasCustomTrim {
+				if err := checkTrimFits(img, options); err != nil {
+					return 0, nil, imageprocessing.TrimMargins{}, nil, err
+				}
+			}
 		}
 		allMargins = append(allMargins, margins)
+		stopMargins()
+		o.reportProgress(pageNum, screenshotPath, img)
+
+		// Repeats of earlier, non-adjacent pages (e.g. recurring ads)
+		if dups != nil && img != nil {
+			if dup, ok := dups.add(pageNum, screenshotPath, img); ok && options.Verbose {
+				o.printf("\nPage %d repeats page %d\n", dup.Page, dup.OrigPage)
+			}
+		}
+
+		// Keep the decoded page for writing the PDF from memory
+		if img != nil && buffer.add(screenshotPath, img) && options.Verbose {
+			o.printf("\nMore than %d pages: the PDF is written from the page files\n", options.InMemoryPages)
+		}
 
 		// Store screenshot path (trimming will be done in batch before PDF generation)
 		screenshots = append(screenshots, screenshotPath)
+		if err := partial.add(screenshots); err != nil {
+			o.printf("\nWarning: %v\n", err)
+		}
+
+		stopEnd := timer.start(stageEnd)
+
+		// Kindle's own end-of-book screen; this page is that screen, not content
+		// A failed UI query is not an end signal
+		if options.UIEndDetection {
+			more, err := o.automation.HasMorePages()
+			if err != nil && options.Verbose {
+				o.printf("\n[DEBUG] Warning: UI end detection failed: %v\n", err)
+			}
+			if err == nil && !more {
+				o.printf("\n\nReached end of book (Kindle shows the end-of-book screen)\n")
+				screenshots = screenshots[:len(screenshots)-1]
+				allMargins = allMargins[:len(allMargins)-1]
+				stopEnd()
+				break
+			}
+		}
+
+		// The page indicator stopped changing: the end of the book even when an
+		// animation keeps the rest of the screen from matching. Keep the first
+		// of the pages that share the indicator
+		if !numberRegion.Empty() && len(screenshots) >= 2 {
+			similarity, err := cache.compareIn(screenshots[len(screenshots)-2], screenshots[len(screenshots)-1], numberRegion)
+			if err == nil && similarity >= endDetectionSimilarity {
+				numberStalled++
+			} else {
+				numberStalled = 0
+			}
+			if numberStalled >= pageNumberStallTurns(options) && len(screenshots) >= endDetectionMinPages {
+				o.printf("\n\nReached end of book (page number unchanged for %d turns)\n", numberStalled)
+				screenshots = screenshots[:len(screenshots)-numberStalled]
+				if len(allMargins) >= numberStalled {
+					allMargins = allMargins[:len(allMargins)-numberStalled]
+				}
+				stopEnd()
+				break
+			}
+		}
 
 		// Check for end of book (last 5 pages identical)
-		if len(screenshots) >= 5 {
+		// Short books can have similar early pages, so wait for EndDetectionMinPages first
+		if len(screenshots) >= endDetectionMinPages {
 			// Show debug info for end detection only in verbose mode
 			if options.Verbose {
-				fmt.Printf("\n[DEBUG] End detection check: total screenshots = %d\n", len(screenshots))
-				fmt.Printf("[DEBUG] Checking last 5 screenshots (indices %d-%d):\n",
+				o.printf("\n[DEBUG] End detection check: total screenshots = %d\n", len(screenshots))
+				o.printf("[DEBUG] Checking last 5 screenshots (indices %d-%d):\n",
 					len(screenshots)-5, len(screenshots)-1)
 				for i := len(screenshots) - 5; i < len(screenshots); i++ {
-					fmt.Printf("[DEBUG]   [%d] %s\n", i, filepath.Base(screenshots[i]))
+					o.printf("[DEBUG]   [%d] %s\n", i, filepath.Base(screenshots[i]))
 				}
 			}
 
 			allIdentical := true
 			for i := len(screenshots) - 4; i < len(screenshots); i++ {
-				similarity, err := imageprocessing.CompareImages(screenshots[i-1], screenshots[i])
+				similarity, err := cache.compare(screenshots[i-1], screenshots[i])
 				if err != nil {
 					if options.Verbose {
-						fmt.Printf("\n[DEBUG] Warning: Failed to compare screenshots for end detection: %v\n", err)
+						o.printf("\n[DEBUG] Warning: Failed to compare screenshots for end detection: %v\n", err)
 					}
 					allIdentical = false
 					break
 				}
 				if options.Verbose {
-					fmt.Printf("[DEBUG] Compare [%d] %s vs [%d] %s: %.2f%% similarity\n",
+					o.printf("[DEBUG] Compare [%d] %s vs [%d] %s: %.2f%% similarity\n",
 						i-1, filepath.Base(screenshots[i-1]),
 						i, filepath.Base(screenshots[i]),
 						similarity*100)
 				}
-				if similarity < 0.995 { // 99.5% - end-of-book pages are 100% identical
+				if similarity < endDetectionSimilarity {
 					allIdentical = false
 					break
 				}
@@ -460,53 +1239,92 @@ func (o *DefaultOrchestrator) capturePages(ctx context.Context, tempDir string,
 				// Last 5 pages are identical - we've reached the end
 				// These are the rating/review screens, not actual book content
 				// Remove the last 5 pages from screenshots AND margins
-				fmt.Printf("\n\nReached end of book (last 5 pages are identical)\n")
-				fmt.Printf("Removing last 5 pages (rating screens) from PDF and margin analysis\n")
+				o.printf("\n\nReached end of book (last 5 pages are identical)\n")
+				o.printf("Removing last 5 pages (rating screens) from PDF and margin analysis\n")
 				screenshots = screenshots[:len(screenshots)-5]
 				// Also remove from margin analysis to prevent gray backgrounds from affecting detection
 				if len(allMargins) >= 5 {
 					allMargins = allMargins[:len(allMargins)-5]
 				}
+				stopEnd()
 				break
 			} else if options.Verbose {
-				fmt.Printf("[DEBUG] Not all identical, continuing...\n")
+				o.printf("[DEBUG] Not all identical, continuing...\n")
 			}
 		}
 
+		// Watchdog: pages that stopped changing without looking like the end
+		// screens (Kindle froze, or the end was missed). Keep the first of the
+		// repeated pages and stop
+		if options.StallPages >= 2 && len(screenshots) >= 2 {
+			similarity, err := cache.compare(screenshots[len(screenshots)-2], screenshots[len(screenshots)-1])
+			if err == nil && similarity >= stallSimilarity {
+				stalled++
+			} else {
+				stalled = 0
+			}
+			if stalled+1 >= options.StallPages {
+				o.printf("\n\nPages stopped changing (last %d pages are near-identical)\n", options.StallPages)
+				screenshots = screenshots[:len(screenshots)-stalled]
+				if len(allMargins) >= stalled {
+					allMargins = allMargins[:len(allMargins)-stalled]
+				}
+				stopEnd()
+				aggregatedMargins := aggregateMargins(allMargins, options.TrimAggregate)
+				return len(screenshots), screenshots, aggregatedMargins, allMargins, ErrPagesStalled
+			}
+		}
+
+		stopEnd()
+
 		// Turn to next page with retry
-		err = RetryWithBackoff(ctx, retryConfig, func() error {
-			return o.automation.TurnNextPage(direction)
+		stopTurn := timer.start(stageTurn)
+		attempts = 0
+		err = o.withFocusRecovery(options, func() error {
+			return RetryWithBackoff(ctx, turnRetry, func() error {
+				attempts++
+				return turn()
+			})
 		})
+		stopTurn()
 		if err != nil {
-			aggregatedMargins := imageprocessing.AggregateMinimumMargins(allMargins)
+			aggregatedMargins := aggregateMargins(allMargins, options.TrimAggregate)
 			return pageNum, screenshots, aggregatedMargins, allMargins, fmt.Errorf("failed to turn page after retries: %w", err)
 		}
+		if attempts > 1 {
+			issues.add(issueTurn)
+		}
 
-		// Wait for page delay
-		time.Sleep(options.PageDelay)
+		// Wait for page delay (or, with AdaptiveDelay, for the new page)
+		stopDelay := timer.start(stageDelay)
+		o.waitForPage(ctx, screenshots[len(screenshots)-1], tempDir, options, cache)
+		stopDelay()
 
 		pageNum++
 	}
 
-	fmt.Println() // New line after progress
+	o.println() // New line after progress
 
 	if pageNum > maxPages {
-		aggregatedMargins := imageprocessing.AggregateMinimumMargins(allMargins)
+		aggregatedMargins := aggregateMargins(allMargins, options.TrimAggregate)
 		return pageNum - 1, screenshots, aggregatedMargins, allMargins, fmt.Errorf("reached maximum page limit (%d)", maxPages)
 	}
 
 	// Aggregate all margins and return
-	aggregatedMargins := imageprocessing.AggregateMinimumMargins(allMargins)
-	return pageNum, screenshots, aggregatedMargins, allMargins, nil
+	// The page count covers detection frames too, so it always matches the output
+	aggregatedMargins := aggregateMargins(allMargins, options.TrimAggregate)
+	return len(screenshots), screenshots, aggregatedMargins, allMargins, nil
 }
 
 // showCountdown displays a countdown timer
 func (o *DefaultOrchestrator) showCountdown(duration time.Duration) {
-	fmt.Printf("Starting in ")
+	o.printf("Starting in ")
 	seconds := int(duration.Seconds())
+	// Sleep off any fraction first so the last number is followed by "Go!"
+	time.Sleep(duration - time.Duration(seconds)*time.Second)
 	for i := seconds; i > 0; i-- {
-		fmt.Printf("%d...", i)
+		o.printf("%d...", i)
 		time.Sleep(time.Second)
 	}
-	fmt.Println("Go!")
+	o.println("Go!")
 }
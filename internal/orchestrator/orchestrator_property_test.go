@@ -3,12 +3,14 @@ package orchestrator
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"image"
 	"image/color"
 	"image/png"
 	"io"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -20,6 +22,7 @@ import (
 	"github.com/oumi/k2p/internal/config"
 	"github.com/oumi/k2p/internal/filemanager"
 	"github.com/oumi/k2p/internal/pdf"
+	"github.com/oumi/k2p/internal/screenshot"
 	"github.com/oumi/k2p/internal/sound"
 )
 
@@ -31,6 +34,18 @@ type MockAutomation struct {
 	Foreground bool
 	TurnError  error
 	TurnCount  int
+	// Page counts of successive TurnPages calls
+	BatchTurns []int
+
+	// Titles returned by successive GetBookTitle calls; the last one repeats
+	Titles     []string
+	TitleCalls int
+	// HasMorePages reports the end-of-book screen after this many turns (0 = never)
+	EndAfterTurns int
+	// Returned by GetKindleVersion
+	Version string
+	// Sessions set with SetSession, in order
+	Sessions []*automation.Session
 }
 
 func (m *MockAutomation) IsKindleInstalled() (bool, error)    { return m.Installed, nil }
@@ -41,7 +56,31 @@ func (m *MockAutomation) TurnNextPage(direction string) error {
 	m.TurnCount++
 	return m.TurnError
 }
-func (m *MockAutomation) HasMorePages() (bool, error) { return true, nil }
+func (m *MockAutomation) TurnPages(direction string, count int) error {
+	m.TurnCount += count
+	m.BatchTurns = append(m.BatchTurns, count)
+	return m.TurnError
+}
+func (m *MockAutomation) GetKindleVersion() (string, error)       { return m.Version, nil }
+func (m *MockAutomation) SetCommandTimeout(timeout time.Duration) {}
+func (m *MockAutomation) SetTarget(target automation.Target)      {}
+func (m *MockAutomation) SetSession(session *automation.Session) {
+	m.Sessions = append(m.Sessions, session)
+}
+func (m *MockAutomation) HasMorePages() (bool, error) {
+	return m.EndAfterTurns == 0 || m.TurnCount < m.EndAfterTurns, nil
+}
+func (m *MockAutomation) GetBookTitle() (string, error) {
+	if len(m.Titles) == 0 {
+		return "", nil
+	}
+	i := m.TitleCalls
+	if i >= len(m.Titles) {
+		i = len(m.Titles) - 1
+	}
+	m.TitleCalls++
+	return m.Titles[i], nil
+}
 
 type MockFileManager struct {
 	DiskSpaceError error
@@ -70,10 +109,29 @@ func (m *MockFileManager) HandleExistingFile(path string, autoConfirm bool) (boo
 
 type MockPDFGenerator struct {
 	GenerateError error
+	ImageFiles    []string
+	Images        []image.Image
+	Options       pdf.PDFOptions
 }
 
 func (m *MockPDFGenerator) CreatePDF(imageFiles []string, outputPath string, options pdf.PDFOptions) error {
-	return m.GenerateError
+	m.ImageFiles = imageFiles
+	m.Options = options
+	return m.writeStub(outputPath)
+}
+
+func (m *MockPDFGenerator) CreatePDFFromImages(images []image.Image, outputPath string, options pdf.PDFOptions) error {
+	m.Images = images
+	m.Options = options
+	return m.writeStub(outputPath)
+}
+
+// writeStub writes a placeholder document, as the output is copied from it
+func (m *MockPDFGenerator) writeStub(outputPath string) error {
+	if m.GenerateError != nil {
+		return m.GenerateError
+	}
+	return os.WriteFile(outputPath, []byte("%PDF-1.4"), 0644)
 }
 
 type MockCapturer struct {
@@ -102,6 +160,7 @@ func (m *MockCapturer) CaptureWithoutActivation(path string) error {
 func (m *MockCapturer) CaptureFrontmostWindow(path string) error {
 	return m.CaptureWithoutActivation(path)
 }
+func (m *MockCapturer) Configure(options screenshot.CaptureOptions) {}
 
 // Property tests
 
@@ -115,7 +174,7 @@ func TestProperty21_DiskSpaceCheck(t *testing.T) {
 			// Setup mocks
 			auto := &MockAutomation{Installed: true, BookOpen: true, Foreground: true}
 			fm := &MockFileManager{
-				DiskSpaceError: fmt.Errorf("insufficient space"),
+				DiskSpaceError: fmt.Errorf("%w: need 100 MB, only 1 MB available", filemanager.ErrInsufficientDiskSpace),
 			}
 			pg := &MockPDFGenerator{}
 			cap := &MockCapturer{}
@@ -136,7 +195,7 @@ func TestProperty21_DiskSpaceCheck(t *testing.T) {
 			_, err := orch.ConvertCurrentBook(context.Background(), opts)
 
 			// Should fail with disk space error
-			return err != nil && err.Error() == "insufficient space"
+			return errors.Is(err, filemanager.ErrInsufficientDiskSpace)
 		},
 	))
 
@@ -147,6 +206,7 @@ func TestProperty5_NoBookOpenDetection(t *testing.T) {
 	parameters := gopter.DefaultTestParameters()
 	parameters.MinSuccessfulTests = 50
 	properties := gopter.NewProperties(parameters)
+	outputPath := filepath.Join(t.TempDir(), "out.pdf")
 
 	properties.Property("No book open detected prevents conversion", prop.ForAll(
 		func() bool {
@@ -155,7 +215,7 @@ func TestProperty5_NoBookOpenDetection(t *testing.T) {
 				BookOpen:   false, // No book
 				Foreground: true,
 			}
-			fm := &MockFileManager{ResolvePath: "/tmp/out.pdf", HandleExists: true}
+			fm := &MockFileManager{ResolvePath: outputPath, HandleExists: true}
 			pg := &MockPDFGenerator{}
 			cap := &MockCapturer{}
 
@@ -170,8 +230,7 @@ func TestProperty5_NoBookOpenDetection(t *testing.T) {
 			opts := &config.ConversionOptions{AutoConfirm: true}
 			_, err := orch.ConvertCurrentBook(context.Background(), opts)
 
-			return err != nil && ((err.Error() == "no book is currently open in Kindle app. Please open a book and try again") ||
-				(len(err.Error()) > 0))
+			return errors.Is(err, automation.ErrNoBookOpen)
 		},
 	))
 
@@ -183,6 +242,7 @@ func TestProperty15_SequentialProcessing(t *testing.T) {
 	parameters := gopter.DefaultTestParameters()
 	parameters.MinSuccessfulTests = 20
 	properties := gopter.NewProperties(parameters)
+	outputPath := filepath.Join(t.TempDir(), "out.pdf")
 
 	properties.Property("Captures pages sequentially", prop.ForAll(
 		func(limit int) bool {
@@ -195,7 +255,7 @@ func TestProperty15_SequentialProcessing(t *testing.T) {
 
 			// Mock Automation
 			auto := &MockAutomation{Installed: true, BookOpen: true, Foreground: true}
-			fm := &MockFileManager{ResolvePath: "/tmp/out.pdf", HandleExists: true}
+			fm := &MockFileManager{ResolvePath: outputPath, HandleExists: true}
 			pg := &MockPDFGenerator{}
 
 			orch := &DefaultOrchestrator{
@@ -238,11 +298,12 @@ func TestProperty11_32_KindleStateValidation(t *testing.T) {
 	parameters := gopter.DefaultTestParameters()
 	parameters.MinSuccessfulTests = 50
 	properties := gopter.NewProperties(parameters)
+	outputPath := filepath.Join(t.TempDir(), "out.pdf")
 
 	properties.Property("Detects if Kindle is not installed", prop.ForAll(
 		func() bool {
 			auto := &MockAutomation{Installed: false} // Not installed
-			fm := &MockFileManager{ResolvePath: "/tmp/out.pdf", HandleExists: true}
+			fm := &MockFileManager{ResolvePath: outputPath, HandleExists: true}
 			pg := &MockPDFGenerator{}
 			cap := &MockCapturer{}
 
@@ -254,7 +315,7 @@ func TestProperty11_32_KindleStateValidation(t *testing.T) {
 				soundPlayer: sound.NewNoOpPlayer(),
 			}
 			_, err := orch.ConvertCurrentBook(context.Background(), &config.ConversionOptions{AutoConfirm: true})
-			return err != nil && err.Error() == "Kindle app is not installed. Please install from the Mac App Store"
+			return errors.Is(err, automation.ErrKindleNotInstalled)
 		},
 	))
 
@@ -262,7 +323,7 @@ func TestProperty11_32_KindleStateValidation(t *testing.T) {
 		func() bool {
 			// Installed but not in foreground
 			auto := &MockAutomation{Installed: true, BookOpen: true, Foreground: false}
-			fm := &MockFileManager{ResolvePath: "/tmp/out.pdf", HandleExists: true}
+			fm := &MockFileManager{ResolvePath: outputPath, HandleExists: true}
 			pg := &MockPDFGenerator{}
 			cap := &MockCapturer{}
 
@@ -274,7 +335,7 @@ func TestProperty11_32_KindleStateValidation(t *testing.T) {
 				soundPlayer: sound.NewNoOpPlayer(),
 			}
 			_, err := orch.ConvertCurrentBook(context.Background(), &config.ConversionOptions{AutoConfirm: true})
-			return err != nil && err.Error() == "Kindle app is not in foreground. Please bring Kindle to the front and try again"
+			return errors.Is(err, automation.ErrKindleNotForeground)
 		},
 	))
 
@@ -286,13 +347,14 @@ func TestProperty2_OutputDirectoryRespected(t *testing.T) {
 	parameters := gopter.DefaultTestParameters()
 	parameters.MinSuccessfulTests = 50
 	properties := gopter.NewProperties(parameters)
+	outputPath := filepath.Join(t.TempDir(), "out.pdf")
 
 	properties.Property("Respects user specified output directory", prop.ForAll(
 		func(outputDir string) bool {
 			// Mock successful environment
 			auto := &MockAutomation{Installed: true, BookOpen: true, Foreground: true}
 			// We want ResolveOutputPath to be called with outputDir
-			fm := &MockFileManager{ResolvePath: "/tmp/resolved/out.pdf", HandleExists: true}
+			fm := &MockFileManager{ResolvePath: outputPath, HandleExists: true}
 			pg := &MockPDFGenerator{}
 			cap := &MockCapturer{}
 
@@ -327,11 +389,12 @@ func TestProperty3_DefaultOutputLocation(t *testing.T) {
 	parameters := gopter.DefaultTestParameters()
 	parameters.MinSuccessfulTests = 50
 	properties := gopter.NewProperties(parameters)
+	outputPath := filepath.Join(t.TempDir(), "out.pdf")
 
 	properties.Property("Uses current directory when output dir is empty", prop.ForAll(
 		func() bool {
 			auto := &MockAutomation{Installed: true, BookOpen: true, Foreground: true}
-			fm := &MockFileManager{ResolvePath: "/tmp/resolved/out.pdf", HandleExists: true}
+			fm := &MockFileManager{ResolvePath: outputPath, HandleExists: true}
 			pg := &MockPDFGenerator{}
 			cap := &MockCapturer{}
 
@@ -381,9 +444,11 @@ func TestProperty4_SuccessMessageDisplay(t *testing.T) {
 	parameters := gopter.DefaultTestParameters()
 	parameters.MinSuccessfulTests = 50
 	properties := gopter.NewProperties(parameters)
+	dir := t.TempDir()
 
 	properties.Property("Success message contains output path", prop.ForAll(
-		func(outputPath string) bool {
+		func(name string) bool {
+			outputPath := filepath.Join(dir, name)
 			// Setup successful mock environment
 			auto := &MockAutomation{Installed: true, BookOpen: true, Foreground: true}
 			// Ensure resolve path returns the generated outputPath
@@ -403,6 +468,7 @@ func TestProperty4_SuccessMessageDisplay(t *testing.T) {
 				AutoConfirm: true,
 				Mode:        "generate",
 				PageDelay:   time.Millisecond,
+				PageTurnKey: "right", // identical mock pages cannot be auto-detected
 			}
 
 			// Capture output
@@ -435,6 +501,7 @@ func (m *MockCapturerFunc) CaptureWithoutActivation(path string) error {
 func (m *MockCapturerFunc) CaptureFrontmostWindow(path string) error {
 	return os.WriteFile(path, []byte("dummy"), 0644)
 }
+func (m *MockCapturerFunc) Configure(options screenshot.CaptureOptions) {}
 
 // Ensure mock structs satisfy interfaces
 var _ automation.KindleAutomation = &MockAutomation{}
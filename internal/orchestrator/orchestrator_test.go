@@ -0,0 +1,226 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/oumi/k2p/internal/pdf"
+	"github.com/oumi/k2p/internal/preflight"
+	"github.com/oumi/k2p/internal/screenshot"
+)
+
+// SkipCover drops the first page; NoTrimCover keeps it untrimmed
+func TestCoverHandling(t *testing.T) {
+	run := func(skipCover, noTrimCover bool, trimTop int) []string {
+		orch := newTestOrchestrator(t, &MockCapturer{})
+		opts := generateOptions()
+		opts.EndDetectionMinPages = 8
+		opts.SkipCover = skipCover
+		opts.NoTrimCover = noTrimCover
+		opts.TrimTop = trimTop
+
+		if _, err := orch.ConvertCurrentBook(context.Background(), opts); err != nil {
+			t.Errorf("conversion failed: %v", err)
+		}
+		return orch.pdfGen.(*MockPDFGenerator).ImageFiles
+	}
+
+	if pages := run(false, false, 0); len(pages) != 3 {
+		t.Fatalf("expected 3 pages without cover options, got %d", len(pages))
+	}
+
+	if pages := run(true, false, 0); len(pages) != 2 {
+		t.Errorf("SkipCover: expected 2 pages, got %d", len(pages))
+	} else if strings.Contains(pages[0], "0001") {
+		t.Errorf("SkipCover: cover still included: %s", pages[0])
+	}
+
+	pages := run(false, true, 2)
+	if len(pages) != 3 {
+		t.Fatalf("NoTrimCover: expected 3 pages, got %d", len(pages))
+	}
+	if strings.Contains(pages[0], "_trimmed") {
+		t.Errorf("NoTrimCover: cover was trimmed: %s", pages[0])
+	}
+	for _, p := range pages[1:] {
+		if !strings.Contains(p, "_trimmed") {
+			t.Errorf("NoTrimCover: content page not trimmed: %s", p)
+		}
+	}
+}
+
+func TestPagesPerMinute(t *testing.T) {
+	if got := pagesPerMinute(30, 2*time.Minute); got != 15 {
+		t.Errorf("expected 15 pages/min, got %.2f", got)
+	}
+	if got := pagesPerMinute(10, 0); got != 0 {
+		t.Errorf("expected 0 for zero duration, got %.2f", got)
+	}
+}
+
+// Missing permissions stop the run before Kindle is touched, unless skipped
+func TestPermissionPreflight(t *testing.T) {
+	newOrch := func() (*DefaultOrchestrator, *MockAutomation) {
+		orch := newTestOrchestrator(t, &MockCapturer{})
+		orch.permissions = &MockPermissions{AccessibilityErr: preflight.ErrAccessibilityDenied}
+		return orch, orch.automation.(*MockAutomation)
+	}
+	opts := generateOptions()
+
+	orch, auto := newOrch()
+	_, err := orch.ConvertCurrentBook(context.Background(), opts)
+	if !errors.Is(err, preflight.ErrAccessibilityDenied) || ExitCode(err) != ExitEnvironment {
+		t.Errorf("expected accessibility environment error, got %v", err)
+	}
+	if auto.TurnCount != 0 {
+		t.Errorf("expected no page turns after failed preflight, got %d", auto.TurnCount)
+	}
+
+	orch, _ = newOrch()
+	opts.SkipPreflight = true
+	if _, err := orch.ConvertCurrentBook(context.Background(), opts); err != nil {
+		t.Errorf("expected conversion to succeed with SkipPreflight, got %v", err)
+	}
+}
+
+// Optimize runs the external optimizer, or warns and skips when it is missing
+func TestOptimizePDF(t *testing.T) {
+	for _, installed := range []bool{false, true} {
+		optimizer := &MockOptimizer{Installed: installed}
+		orch := newTestOrchestrator(t, &MockCapturer{})
+		orch.optimizer = optimizer
+		opts := generateOptions()
+		opts.Optimize = true
+
+		result, err := orch.ConvertCurrentBook(context.Background(), opts)
+		if err != nil {
+			t.Fatalf("installed=%v: conversion failed: %v", installed, err)
+		}
+
+		wantCalls := 0
+		if installed {
+			wantCalls = 1
+		}
+		if optimizer.Calls != wantCalls {
+			t.Errorf("installed=%v: expected %d optimize calls, got %d", installed, wantCalls, optimizer.Calls)
+		}
+		if hasWarning := len(result.Warnings) > 0; hasWarning == installed {
+			t.Errorf("installed=%v: unexpected warnings %v", installed, result.Warnings)
+		}
+	}
+}
+
+// Append: new pages land after the existing PDF's pages, invalid files are rejected early
+func TestAppendTo(t *testing.T) {
+	tmpDir := t.TempDir()
+	run := func(fm *MockFileManager, appendTo string) (*ConversionResult, error) {
+		orch := newTestOrchestrator(t, screenshot.NewSyntheticCapturer(8))
+		orch.fileManager = fm
+		orch.pdfGen = pdf.NewPDFGenerator()
+		opts := generateOptions()
+		opts.AppendTo = appendTo
+		return orch.ConvertCurrentBook(context.Background(), opts)
+	}
+
+	notPDF := filepath.Join(tmpDir, "notes.pdf")
+	if err := os.WriteFile(notPDF, []byte("not a pdf"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := run(&MockFileManager{}, notPDF); err == nil {
+		t.Error("expected error for invalid PDF")
+	}
+
+	existing := filepath.Join(tmpDir, "book.pdf")
+	first, err := run(&MockFileManager{ResolvePath: existing, HandleExists: true}, "")
+	if err != nil {
+		t.Fatalf("initial conversion failed: %v", err)
+	}
+	second, err := run(&MockFileManager{}, existing)
+	if err != nil {
+		t.Fatalf("append failed: %v", err)
+	}
+
+	if second.OutputPath != existing {
+		t.Errorf("expected output %s, got %s", existing, second.OutputPath)
+	}
+	count, err := pdf.PageCount(existing)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.PageCount == 0 || count != first.PageCount+second.PageCount {
+		t.Errorf("expected %d+%d pages after appending, got %d", first.PageCount, second.PageCount, count)
+	}
+}
+
+// Timeout: capture stops at the deadline and the captured pages are still written
+func TestTimeout(t *testing.T) {
+	opts := generateOptions()
+	opts.PageDelay = 10 * time.Millisecond
+	opts.Timeout = 300 * time.Millisecond
+
+	result, err := newTestOrchestrator(t, screenshot.NewSyntheticCapturer(1000)).ConvertCurrentBook(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("expected the captured pages to be written, got error: %v", err)
+	}
+	if result.PageCount == 0 || result.PageCount >= 1000 {
+		t.Errorf("expected a partial book, got %d pages", result.PageCount)
+	}
+	found := false
+	for _, w := range result.Warnings {
+		if strings.Contains(w, "Timed out") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected a timeout warning, got %v", result.Warnings)
+	}
+}
+
+// PageStep turns the pages between captures in one batched call, and end
+// detection waits for EndDetectionMinPages book pages, not captures
+func TestPageStep(t *testing.T) {
+	for _, tt := range []struct {
+		step      int
+		wantPages int
+	}{
+		{0, 25}, // 30 captures before end detection, minus the 5 end screens
+		{3, 5},  // 10 captures of 3 pages each
+	} {
+		orch := newTestOrchestrator(t, screenshot.NewSyntheticCapturer(6))
+		mock := orch.automation.(*MockAutomation)
+		opts := generateOptions()
+		opts.EndDetectionMinPages = 30
+		opts.PageStep = tt.step
+
+		result, err := orch.ConvertCurrentBook(context.Background(), opts)
+		if err != nil {
+			t.Fatalf("PageStep=%d: conversion failed: %v", tt.step, err)
+		}
+		if result.PageCount != tt.wantPages {
+			t.Errorf("PageStep=%d: expected %d pages, got %d", tt.step, tt.wantPages, result.PageCount)
+		}
+
+		if tt.step == 0 && len(mock.BatchTurns) != 0 {
+			t.Errorf("expected single page turns, got batches %v", mock.BatchTurns)
+		}
+		if tt.step == 3 {
+			if len(mock.BatchTurns) == 0 {
+				t.Fatal("expected batched page turns")
+			}
+			for _, n := range mock.BatchTurns {
+				if n != 3 {
+					t.Errorf("expected batches of 3 turns, got %v", mock.BatchTurns)
+					break
+				}
+			}
+			if mock.TurnCount != 3*len(mock.BatchTurns) {
+				t.Errorf("expected only batched turns, got %d turns in %d batches", mock.TurnCount, len(mock.BatchTurns))
+			}
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package orchestrator
+
+import (
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+
+	"github.com/oumi/k2p/internal/config"
+	"github.com/oumi/k2p/internal/imageprocessing"
+)
+
+// dominantOrientation returns the orientation most pages share (portrait on
+// a tie); square pages do not count
+func dominantOrientation(sizes []image.Point) imageprocessing.Orientation {
+	portrait, landscape := 0, 0
+	for _, size := range sizes {
+		switch imageprocessing.OrientationOf(size) {
+		case imageprocessing.OrientationPortrait:
+			portrait++
+		case imageprocessing.OrientationLandscape:
+			landscape++
+		}
+	}
+	if landscape > portrait {
+		return imageprocessing.OrientationLandscape
+	}
+	return imageprocessing.OrientationPortrait
+}
+
+// autoRotatePages turns pages whose orientation differs from the book's
+// dominant one by 90° clockwise, so every PDF page has the same orientation
+// (e.g. a landscape spread in a portrait book is no longer shrunk onto a
+// portrait sheet or shown as the only landscape page). Returns the page list
+// and the number of rotated pages; pages that cannot be read stay as they are
+func (o *DefaultOrchestrator) autoRotatePages(screenshots []string, tempDir string, options *config.ConversionOptions, issues *pageIssues) ([]string, int) {
+	sizes := make([]image.Point, len(screenshots))
+	for i, path := range screenshots {
+		size, err := imageprocessing.ImageSize(path)
+		if err != nil {
+			issues.add(issueOrient)
+			if options.Verbose {
+				o.printf("  Warning: Failed to read size of page %d: %v\n", i+1, err)
+			}
+		}
+		sizes[i] = size
+	}
+	dominant := dominantOrientation(sizes)
+
+	var outliers []int
+	for i, size := range sizes {
+		orientation := imageprocessing.OrientationOf(size)
+		if orientation != imageprocessing.OrientationSquare && orientation != dominant {
+			outliers = append(outliers, i)
+		}
+	}
+
+	rotated := append([]string(nil), screenshots...)
+	done := make([]bool, len(outliers))
+	forEachPage(len(outliers), options.MaxConcurrency, func(j int) {
+		i := outliers[j]
+		rotatedPath := filepath.Join(tempDir, fmt.Sprintf("page_%04d_oriented.png", i+1))
+		if err := imageprocessing.RotateImageFile(screenshots[i], rotatedPath, 90); err != nil {
+			issues.add(issueAutoRotate)
+			if options.Verbose {
+				o.printf("  Warning: Failed to rotate page %d, keeping it: %v\n", i+1, err)
+			}
+			return
+		}
+		rotated[i] = rotatedPath
+		done[j] = true
+		os.Remove(screenshots[i])
+	})
+
+	count := 0
+	for j, ok := range done {
+		if ok {
+			count++
+			if options.Verbose {
+				o.printf("  Page %d rotated to match the book's orientation\n", outliers[j]+1)
+			}
+		}
+	}
+	return rotated, count
+}
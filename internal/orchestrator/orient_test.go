@@ -0,0 +1,78 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"io"
+	"path/filepath"
+	"testing"
+
+	"github.com/oumi/k2p/internal/config"
+	"github.com/oumi/k2p/internal/imageprocessing"
+	"github.com/oumi/k2p/internal/screenshot"
+)
+
+// Rotation: pages reach trimming and the PDF generator in the rotated orientation
+func TestRotatePages(t *testing.T) {
+	var sizes []image.Point
+	orch := newTestOrchestrator(t, screenshot.NewSyntheticCapturer(4))
+	orch.pdfGen = &inspectingPDFGenerator{MockPDFGenerator: &MockPDFGenerator{}, inspect: func(path string) {
+		if img, err := imageprocessing.LoadImage(path); err == nil {
+			sizes = append(sizes, img.Bounds().Size())
+		}
+	}}
+	opts := generateOptions()
+	opts.Rotate = 90
+	opts.TrimTop = 10
+
+	if _, err := orch.ConvertCurrentBook(context.Background(), opts); err != nil {
+		t.Fatalf("conversion failed: %v", err)
+	}
+
+	if len(sizes) == 0 {
+		t.Fatal("no pages reached the PDF generator")
+	}
+	// Synthetic pages are 300x400; rotated to 400x300, then 10px trimmed off the top
+	for i, size := range sizes {
+		if size != image.Pt(400, 290) {
+			t.Errorf("page %d: expected 400x290, got %v", i+1, size)
+		}
+	}
+}
+
+// Auto-rotate: pages against the book's dominant orientation are turned 90°
+func TestAutoRotatePages(t *testing.T) {
+	tmpDir := t.TempDir()
+	var pages []string
+	for i, size := range []image.Point{{300, 400}, {800, 600}, {300, 400}, {500, 500}, {300, 400}} {
+		path := filepath.Join(tmpDir, fmt.Sprintf("page_%d.png", i))
+		if err := imageprocessing.SavePNG(image.NewRGBA(image.Rectangle{Max: size}), path); err != nil {
+			t.Fatal(err)
+		}
+		pages = append(pages, path)
+	}
+
+	orch := &DefaultOrchestrator{}
+	orch.SetLogWriter(io.Discard)
+	rotated, count := orch.autoRotatePages(pages, tmpDir, &config.ConversionOptions{}, nil)
+	if count != 1 {
+		t.Errorf("expected 1 rotated page, got %d", count)
+	}
+
+	want := []image.Point{{300, 400}, {600, 800}, {300, 400}, {500, 500}, {300, 400}}
+	for i, path := range rotated {
+		size, err := imageprocessing.ImageSize(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if size != want[i] {
+			t.Errorf("page %d: expected %v, got %v", i+1, want[i], size)
+		}
+	}
+
+	// A tie keeps portrait
+	if got := dominantOrientation([]image.Point{{300, 400}, {400, 300}}); got != imageprocessing.OrientationPortrait {
+		t.Errorf("expected portrait on a tie, got %d", got)
+	}
+}
@@ -0,0 +1,79 @@
+package orchestrator
+
+import (
+	"context"
+	"image"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/oumi/k2p/internal/config"
+	"github.com/oumi/k2p/internal/imageprocessing"
+	"github.com/oumi/k2p/internal/screenshot"
+)
+
+// adaptivePollInterval is the pause between polling captures (AdaptiveDelay)
+const adaptivePollInterval = 100 * time.Millisecond
+
+// waitForPage waits after a page turn. Without AdaptiveDelay that is the
+// fixed PageDelay; with it, captures are polled after MinPageDelay until one
+// differs from previous (the page before the turn) and the next agrees with
+// it, i.e. the new page has finished rendering, or MaxPageDelay has passed
+// A page that never changes (the end screens) just costs MaxPageDelay, and
+// end detection handles it as before
+func (o *DefaultOrchestrator) waitForPage(ctx context.Context, previous, tempDir string, options *config.ConversionOptions, cache *pageCache) {
+	if !options.AdaptiveDelay {
+		sleepContext(ctx, options.PageDelay)
+		return
+	}
+
+	deadline := time.Now().Add(options.MaxPageDelay)
+	sleepContext(ctx, options.MinPageDelay)
+
+	before, err := cache.get(previous)
+	if err != nil {
+		sleepContext(ctx, time.Until(deadline))
+		return
+	}
+	pollPath := filepath.Join(tempDir, "pacing_poll"+screenshot.FileExtension(options.ScreenshotQuality, options.CaptureFormat))
+	defer os.Remove(pollPath)
+
+	var changed image.Image
+	for ctx.Err() == nil {
+		if err := o.capturer.CaptureWithoutActivation(pollPath); err == nil {
+			if img, err := imageprocessing.LoadImage(pollPath); err == nil {
+				if changed == nil {
+					if cache.similarity(before, img) < endDetectionSimilarity {
+						changed = img
+					}
+				} else if cache.similarity(changed, img) >= endDetectionSimilarity {
+					return
+				} else {
+					changed = img
+				}
+			}
+		}
+
+		if time.Now().Add(adaptivePollInterval).After(deadline) {
+			break
+		}
+		sleepContext(ctx, adaptivePollInterval)
+	}
+
+	if options.Verbose {
+		o.printf("\nPage did not settle within %s\n", options.MaxPageDelay)
+	}
+}
+
+// sleepContext sleeps for d or until ctx is done
+func sleepContext(ctx context.Context, d time.Duration) {
+	if d <= 0 {
+		return
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+}
@@ -0,0 +1,58 @@
+package orchestrator
+
+import (
+	"context"
+	"image/color"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/oumi/k2p/internal/config"
+)
+
+// AdaptiveDelay: after a turn, captures are polled until the page differs
+// from the one before the turn and two polls agree, or MaxPageDelay passes
+func TestWaitForPage(t *testing.T) {
+	gray := color.Gray{Y: 128}
+	for _, tt := range []struct {
+		name         string
+		adaptive     bool
+		frames       []color.Color
+		wantCaptures int
+		wantTimeout  bool
+	}{
+		{"fixed delay", false, []color.Color{color.Black}, 0, false},
+		{"new page settled", true, []color.Color{color.White, color.Black, color.Black}, 3, false},
+		{"still rendering", true, []color.Color{gray, color.Black, color.Black}, 3, false},
+		{"page never changes", true, []color.Color{color.White}, 0, true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			previous := filepath.Join(dir, "page_0001.png")
+			writePage(t, previous, color.White)
+
+			capturer := framesCapturer(tt.frames...)
+			orch := &DefaultOrchestrator{capturer: capturer}
+			orch.SetLogWriter(io.Discard)
+			options := &config.ConversionOptions{
+				AdaptiveDelay: tt.adaptive, MinPageDelay: time.Millisecond, MaxPageDelay: time.Second,
+			}
+			start := time.Now()
+			orch.waitForPage(context.Background(), previous, dir, options, newPageCache(5))
+			if timedOut := time.Since(start) >= options.MaxPageDelay/2; timedOut != tt.wantTimeout {
+				t.Errorf("expected timeout=%v, waited %s", tt.wantTimeout, time.Since(start))
+			}
+			if tt.wantCaptures > 0 && capturer.Count != tt.wantCaptures {
+				t.Errorf("expected %d polling captures, got %d", tt.wantCaptures, capturer.Count)
+			}
+			if !tt.adaptive && capturer.Count != 0 {
+				t.Errorf("expected no polling with a fixed delay, got %d captures", capturer.Count)
+			}
+			if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+				t.Errorf("expected the polling capture to be removed, got %d files", len(entries))
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package orchestrator
+
+import (
+	"image"
+
+	"github.com/oumi/k2p/internal/imageprocessing"
+)
+
+// pageCache keeps the most recently decoded pages in memory
+// End detection compares each new page with the four before it; without the
+// cache every comparison decodes both files again. The capacity bounds memory
+// to a few decoded pages however long the book is
+type pageCache struct {
+	capacity int
+	order    []string
+	images   map[string]image.Image
+
+	// Region compared by compare (empty = whole page)
+	region image.Rectangle
+
+	// Similarity metric used by compare (nil = pixel)
+	comparer imageprocessing.Comparer
+}
+
+// newPageCache creates a cache holding at most capacity decoded pages
+func newPageCache(capacity int) *pageCache {
+	return &pageCache{capacity: capacity, images: make(map[string]image.Image)}
+}
+
+// get returns the decoded page, decoding and caching it on a miss
+func (c *pageCache) get(path string) (image.Image, error) {
+	if img, ok := c.images[path]; ok {
+		return img, nil
+	}
+
+	img, err := imageprocessing.LoadImage(path)
+	if err != nil {
+		return nil, err
+	}
+	c.put(path, img)
+	return img, nil
+}
+
+// put stores a decoded page, evicting the oldest one when full
+func (c *pageCache) put(path string, img image.Image) {
+	if _, ok := c.images[path]; ok {
+		c.images[path] = img
+		return
+	}
+	if len(c.order) >= c.capacity {
+		delete(c.images, c.order[0])
+		c.order = c.order[1:]
+	}
+	c.order = append(c.order, path)
+	c.images[path] = img
+}
+
+// forget drops a page whose file was replaced, so it is decoded again
+func (c *pageCache) forget(path string) {
+	if _, ok := c.images[path]; !ok {
+		return
+	}
+	delete(c.images, path)
+	for i, p := range c.order {
+		if p == path {
+			c.order = append(c.order[:i], c.order[i+1:]...)
+			break
+		}
+	}
+}
+
+// compare returns the similarity of two pages (within region) using the cache
+func (c *pageCache) compare(path1, path2 string) (float64, error) {
+	return c.compareIn(path1, path2, c.region)
+}
+
+// compareIn returns the similarity of two pages within another region, e.g.
+// the page number, using the cache
+func (c *pageCache) compareIn(path1, path2 string, region image.Rectangle) (float64, error) {
+	img1, err := c.get(path1)
+	if err != nil {
+		return 0, err
+	}
+	img2, err := c.get(path2)
+	if err != nil {
+		return 0, err
+	}
+	return imageprocessing.CompareInRegion(c.metric(), img1, img2, region), nil
+}
+
+// similarity returns the similarity of two decoded pages within region
+func (c *pageCache) similarity(img1, img2 image.Image) float64 {
+	return imageprocessing.CompareInRegion(c.metric(), img1, img2, c.region)
+}
+
+// metric returns the similarity metric (nil comparer: pixel)
+func (c *pageCache) metric() imageprocessing.Comparer {
+	if c.comparer == nil {
+		return imageprocessing.PixelComparer{}
+	}
+	return c.comparer
+}
@@ -0,0 +1,65 @@
+package orchestrator
+
+import (
+	"fmt"
+	"image"
+	"path/filepath"
+	"testing"
+
+	"github.com/oumi/k2p/internal/imageprocessing"
+)
+
+// The page cache decodes each page once and holds at most its capacity
+func TestPageCache(t *testing.T) {
+	tmpDir := t.TempDir()
+	capturer := sequenceCapturer(4)
+	var paths []string
+	for i := 0; i < 4; i++ {
+		path := filepath.Join(tmpDir, fmt.Sprintf("page_%d.png", i))
+		if err := capturer.CaptureWithoutActivation(path); err != nil {
+			t.Fatalf("capture failed: %v", err)
+		}
+		paths = append(paths, path)
+	}
+
+	cache := newPageCache(2)
+	first, err := cache.get(paths[0])
+	if err != nil {
+		t.Fatalf("get failed: %v", err)
+	}
+	again, _ := cache.get(paths[0])
+	if first != again {
+		t.Error("expected cached image on second get")
+	}
+
+	for _, p := range paths[1:] {
+		if _, err := cache.get(p); err != nil {
+			t.Fatalf("get failed: %v", err)
+		}
+	}
+	if len(cache.images) != 2 {
+		t.Errorf("expected 2 cached pages, got %d", len(cache.images))
+	}
+	if _, ok := cache.images[paths[0]]; ok {
+		t.Error("oldest page not evicted")
+	}
+
+	similarity, err := cache.compare(paths[2], paths[3])
+	if err != nil {
+		t.Fatalf("compare failed: %v", err)
+	}
+	want, _ := imageprocessing.CompareImages(paths[2], paths[3])
+	if similarity != want {
+		t.Errorf("cached similarity %.3f differs from file comparison %.3f", similarity, want)
+	}
+
+	cache.region = image.Rect(0, 0, 50, 50)
+	similarity, err = cache.compare(paths[2], paths[3])
+	if err != nil {
+		t.Fatalf("compare failed: %v", err)
+	}
+	want, _ = imageprocessing.CompareImagesInRegion(paths[2], paths[3], cache.region)
+	if similarity != want {
+		t.Errorf("cached region similarity %.3f differs from file comparison %.3f", similarity, want)
+	}
+}
@@ -0,0 +1,32 @@
+package orchestrator
+
+import "image"
+
+// ProgressEvent describes a page that was just captured
+type ProgressEvent struct {
+	// 1-based number of the captured page
+	PageNumber int
+
+	// Screenshot in the temporary directory; it is removed after the run,
+	// so read it during the callback if needed
+	ImagePath string
+
+	// Decoded screenshot (nil if decoding failed)
+	Image image.Image
+}
+
+// ProgressFunc receives an event after each captured page
+// It runs on the capture goroutine, so it should return quickly
+type ProgressFunc func(event ProgressEvent)
+
+// SetProgressFunc sets the callback for captured pages (nil disables it)
+func (o *DefaultOrchestrator) SetProgressFunc(fn ProgressFunc) {
+	o.progress = fn
+}
+
+// reportProgress sends a progress event if a callback is set
+func (o *DefaultOrchestrator) reportProgress(pageNum int, path string, img image.Image) {
+	if o.progress != nil {
+		o.progress(ProgressEvent{PageNumber: pageNum, ImagePath: path, Image: img})
+	}
+}
@@ -0,0 +1,38 @@
+package orchestrator
+
+import (
+	"context"
+	"os"
+	"testing"
+)
+
+// Progress callback: one event per captured page, in order, with the decoded image
+func TestProgressFunc(t *testing.T) {
+	orch := newTestOrchestrator(t, &MockCapturer{})
+	var events []ProgressEvent
+	orch.SetProgressFunc(func(ev ProgressEvent) {
+		if _, err := os.Stat(ev.ImagePath); err != nil {
+			t.Errorf("page %d: screenshot not readable during callback: %v", ev.PageNumber, err)
+		}
+		events = append(events, ev)
+	})
+
+	opts := generateOptions()
+	opts.EndDetectionMinPages = 8
+	result, err := orch.ConvertCurrentBook(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("conversion failed: %v", err)
+	}
+
+	if len(events) < result.PageCount {
+		t.Fatalf("expected at least %d events, got %d", result.PageCount, len(events))
+	}
+	for i, ev := range events {
+		if ev.PageNumber != i+1 {
+			t.Errorf("event %d: expected page %d, got %d", i, i+1, ev.PageNumber)
+		}
+		if ev.Image == nil {
+			t.Errorf("event %d: missing decoded image", i)
+		}
+	}
+}
@@ -12,6 +12,10 @@ type RetryConfig struct {
 	InitialDelay time.Duration
 	MaxDelay     time.Duration
 	Multiplier   float64
+
+	// Called after the backoff delay, before each retry, with the error of
+	// the failed attempt (nil = nothing to do between attempts)
+	BeforeRetry func(err error)
 }
 
 // DefaultRetryConfig returns default retry configuration
@@ -54,6 +58,10 @@ func RetryWithBackoff(ctx context.Context, config RetryConfig, fn func() error)
 			if delay > config.MaxDelay {
 				delay = config.MaxDelay
 			}
+
+			if config.BeforeRetry != nil {
+				config.BeforeRetry(err)
+			}
 		}
 	}
 
@@ -0,0 +1,66 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/oumi/k2p/internal/config"
+	"github.com/oumi/k2p/internal/imageprocessing"
+)
+
+const (
+	// firstCaptureSettle is the pause before the first page is captured again
+	// to confirm it (VerifyFirstCapture)
+	firstCaptureSettle = 500 * time.Millisecond
+
+	// firstCaptureChecks bounds the confirming captures of the first page
+	firstCaptureChecks = 3
+
+	// firstCaptureSimilarity is the similarity two captures of the first page
+	// need to count as the same frame; a Space transition frame (another
+	// Space, or the slide between them) is far below it
+	firstCaptureSimilarity = 0.80
+)
+
+// verifyFirstCapture captures the first page again after a short pause and
+// compares the two (VerifyFirstCapture). Right after Kindle's fullscreen
+// Space is activated, a capture can still show the Space transition; when
+// the frames differ, the newer one replaces path and is checked in turn
+// Only the first page pays for this. Returns false when the frame never
+// settled: the last capture is kept, since the capture itself succeeded
+func (o *DefaultOrchestrator) verifyFirstCapture(ctx context.Context, path string, options *config.ConversionOptions, metric imageprocessing.Comparer) (bool, error) {
+	verifyPath := filepath.Join(filepath.Dir(path), "verify_"+filepath.Base(path))
+	defer os.Remove(verifyPath)
+
+	for check := 1; check <= firstCaptureChecks; check++ {
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		case <-time.After(firstCaptureSettle):
+		}
+
+		if err := o.capturer.CaptureWithoutActivation(verifyPath); err != nil {
+			return false, fmt.Errorf("failed to verify the first page: %w", err)
+		}
+		similarity, err := imageprocessing.CompareFiles(metric, path, verifyPath, diffRegion(options))
+		if err != nil {
+			return false, fmt.Errorf("failed to compare the first page captures: %w", err)
+		}
+		if similarity >= firstCaptureSimilarity {
+			return true, nil
+		}
+
+		// The earlier frame was probably still the Space transition
+		if options.Verbose {
+			o.printf("\nFirst page changed between captures (%.0f%% similar), recapturing...\n", similarity*100)
+		}
+		if err := os.Rename(verifyPath, path); err != nil {
+			return false, fmt.Errorf("failed to replace the first page: %w", err)
+		}
+	}
+
+	return false, nil
+}
@@ -0,0 +1,109 @@
+package orchestrator
+
+import (
+	"context"
+	"image/color"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/oumi/k2p/internal/config"
+	"github.com/oumi/k2p/internal/imageprocessing"
+	"github.com/oumi/k2p/internal/screenshot"
+)
+
+// The first page is captured again until two frames agree, so a Space
+// transition frame is replaced by the settled page
+func TestVerifyFirstCapture(t *testing.T) {
+	for _, tt := range []struct {
+		name         string
+		first        color.Color
+		frames       []color.Color
+		wantCaptures int
+		wantSettled  bool
+	}{
+		{"settled", color.White, []color.Color{color.White}, 1, true},
+		{"transition frame replaced", color.Black, []color.Color{color.White, color.White}, 2, true},
+		{"never settles", color.Black, []color.Color{color.White, color.Black, color.White}, firstCaptureChecks, false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "page_0001.png")
+			writePage(t, path, tt.first)
+
+			capturer := framesCapturer(tt.frames...)
+			orch := &DefaultOrchestrator{capturer: capturer}
+			orch.SetLogWriter(io.Discard)
+			settled, err := orch.verifyFirstCapture(context.Background(), path, &config.ConversionOptions{}, imageprocessing.PixelComparer{})
+			if err != nil {
+				t.Fatalf("verifyFirstCapture failed: %v", err)
+			}
+			if settled != tt.wantSettled {
+				t.Errorf("expected settled=%v, got %v", tt.wantSettled, settled)
+			}
+
+			if capturer.Count != tt.wantCaptures {
+				t.Errorf("expected %d confirming captures, got %d", tt.wantCaptures, capturer.Count)
+			}
+			img, err := imageprocessing.LoadImage(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// The last capture is kept: white in every case
+			if got, want := color.RGBAModel.Convert(img.At(50, 50)), color.RGBAModel.Convert(color.White); got != want {
+				t.Errorf("expected the first page to be %v, got %v", want, got)
+			}
+			if entries, _ := os.ReadDir(filepath.Dir(path)); len(entries) != 1 {
+				t.Errorf("expected only the first page to remain, got %d files", len(entries))
+			}
+		})
+	}
+}
+
+// garbageCapturer writes files that are not images
+type garbageCapturer struct{}
+
+func (garbageCapturer) CaptureFrontmostWindow(path string) error {
+	return garbageCapturer{}.CaptureWithoutActivation(path)
+}
+func (garbageCapturer) CaptureWithoutActivation(path string) error {
+	return os.WriteFile(path, []byte("not an image"), 0644)
+}
+func (garbageCapturer) Configure(screenshot.CaptureOptions) {}
+
+// A confirming capture that cannot be compared fails the check instead of
+// replacing the first page
+func TestVerifyFirstCaptureCompareError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "page_0001.png")
+	writePage(t, path, color.White)
+
+	orch := &DefaultOrchestrator{capturer: garbageCapturer{}}
+	orch.SetLogWriter(io.Discard)
+	if _, err := orch.verifyFirstCapture(context.Background(), path, &config.ConversionOptions{}, imageprocessing.PixelComparer{}); err == nil {
+		t.Fatal("expected the compare error")
+	}
+	if _, err := imageprocessing.LoadImage(path); err != nil {
+		t.Errorf("expected the first page to be kept, got %v", err)
+	}
+}
+
+// A first page that never settles is reported in the result warnings
+func TestVerifyFirstCaptureWarning(t *testing.T) {
+	red, blue := color.RGBA{R: 255, A: 255}, color.RGBA{B: 255, A: 255}
+	orch := newTestOrchestrator(t, framesCapturer(red, blue, red, blue, red, blue))
+	opts := generateOptions()
+	opts.VerifyFirstCapture = true
+
+	result, err := orch.ConvertCurrentBook(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("conversion failed: %v", err)
+	}
+	found := false
+	for _, w := range result.Warnings {
+		found = found || strings.Contains(w, issueUnsettled)
+	}
+	if !found {
+		t.Errorf("expected a warning about the first page, got %v", result.Warnings)
+	}
+}
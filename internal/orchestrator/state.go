@@ -0,0 +1,80 @@
+package orchestrator
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// StateFileName is the suggested state file name, e.g. in the output directory
+const StateFileName = ".k2p-state.json"
+
+// CompletedBook records one finished conversion in the state file
+type CompletedBook struct {
+	Title       string    `json:"title"`
+	OutputPath  string    `json:"output_path"`
+	CompletedAt time.Time `json:"completed_at"`
+}
+
+// sessionState is the batch/watch progress that survives a restart
+// Books already in it are skipped; every finished book is written at once
+type sessionState struct {
+	path  string
+	Books []CompletedBook `json:"books"`
+}
+
+// loadState reads the state file; a missing file is an empty state
+// An empty path is a state that is off: nothing is skipped or saved. A
+// damaged file is an error rather than silently starting over
+func loadState(path string) (*sessionState, error) {
+	state := &sessionState{path: path}
+	if path == "" {
+		return state, nil
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state file: %w", err)
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("failed to parse state file %s: %w", path, err)
+	}
+	return state, nil
+}
+
+// done reports whether a book with this title was already converted
+func (s *sessionState) done(title string) bool {
+	for _, b := range s.Books {
+		if b.Title == title {
+			return true
+		}
+	}
+	return false
+}
+
+// record adds a finished book and saves the state file
+// The file is written to a temporary name and renamed, so a crash while
+// saving never leaves a truncated state behind
+func (s *sessionState) record(title, outputPath string) error {
+	if s.path == "" {
+		return nil
+	}
+	s.Books = append(s.Books, CompletedBook{Title: title, OutputPath: outputPath, CompletedAt: time.Now()})
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode state: %w", err)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package orchestrator
+
+import (
+	"sync"
+	"time"
+)
+
+// Stages timed for VerboseTiming, in the order they usually run
+const (
+	stageChecks     = "Checks"
+	stageActivation = "Activation"
+	stageDirection  = "Direction detection"
+	stageCapture    = "Capture"
+	stageMargins    = "Margin analysis"
+	stageEnd        = "End detection"
+	stageTurn       = "Page turn"
+	stageDelay      = "Page delay"
+	stageProcessing = "Image processing"
+	stageTrim       = "Trimming"
+	stageOutput     = "Writing output"
+)
+
+// stageTimer accumulates wall time per stage (VerboseTiming), so a slow run
+// shows whether delays, capture, trimming or encoding dominate. Safe for
+// concurrent use; a nil *stageTimer times nothing
+type stageTimer struct {
+	mu     sync.Mutex
+	totals map[string]time.Duration
+	counts map[string]int
+	order  []string
+}
+
+// newStageTimer returns a timer, or nil when timing is off
+func newStageTimer(enabled bool) *stageTimer {
+	if !enabled {
+		return nil
+	}
+	return &stageTimer{totals: make(map[string]time.Duration), counts: make(map[string]int)}
+}
+
+// start starts a stopwatch for stage; calling the returned function adds the
+// time since start to the stage
+func (t *stageTimer) start(stage string) func() {
+	if t == nil {
+		return func() {}
+	}
+	started := time.Now()
+	return func() { t.add(stage, time.Since(started)) }
+}
+
+// add records one run of stage that took d
+func (t *stageTimer) add(stage string, d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.counts[stage] == 0 {
+		t.order = append(t.order, stage)
+	}
+	t.totals[stage] += d
+	t.counts[stage]++
+}
+
+// printTiming writes one line per stage in the order first timed, with its
+// share of total and, for per-page stages, the count and average
+func (o *DefaultOrchestrator) printTiming(t *stageTimer, total time.Duration) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	o.println("\n=== Timing ===")
+	for _, stage := range t.order {
+		d := t.totals[stage]
+		o.printf("%-20s %9s %5.1f%%", stage+":", d.Round(time.Millisecond), percentOf(d, total))
+		if n := t.counts[stage]; n > 1 {
+			o.printf("  (%d × %s)", n, (d / time.Duration(n)).Round(time.Millisecond))
+		}
+		o.println()
+	}
+	o.printf("%-20s %9s\n", "Total:", total.Round(time.Millisecond))
+}
+
+// percentOf returns d as a percentage of total (0 for no total)
+func percentOf(d, total time.Duration) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return float64(d) / float64(total) * 100
+}
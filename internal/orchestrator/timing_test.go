@@ -0,0 +1,42 @@
+package orchestrator
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/oumi/k2p/internal/screenshot"
+)
+
+// VerboseTiming prints the time per stage after the summary, only when set
+func TestVerboseTiming(t *testing.T) {
+	for _, enabled := range []bool{true, false} {
+		orch := newTestOrchestrator(t, screenshot.NewSyntheticCapturer(6))
+		var buf bytes.Buffer
+		orch.SetLogWriter(&buf)
+		opts := generateOptions()
+		opts.TrimTop = 5
+		opts.VerboseTiming = enabled
+		if _, err := orch.ConvertCurrentBook(context.Background(), opts); err != nil {
+			t.Fatalf("conversion failed: %v", err)
+		}
+
+		out := buf.String()
+		if got := strings.Contains(out, "=== Timing ==="); got != enabled {
+			t.Fatalf("VerboseTiming=%v: timing printed = %v:\n%s", enabled, got, out)
+		}
+		if !enabled {
+			continue
+		}
+		for _, stage := range []string{stageChecks, stageActivation, stageCapture, stageTurn, stageDelay, stageTrim, stageOutput} {
+			if !strings.Contains(out, stage+":") {
+				t.Errorf("expected a %q line in:\n%s", stage, out)
+			}
+		}
+	}
+
+	// A nil timer times nothing
+	var timer *stageTimer
+	timer.start(stageCapture)()
+}
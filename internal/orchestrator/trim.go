@@ -1,6 +1,14 @@
 package orchestrator
 
 import (
+	"fmt"
+	"image"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/oumi/k2p/internal/config"
 	"github.com/oumi/k2p/internal/imageprocessing"
 )
 
@@ -8,3 +16,170 @@ import (
 func (o *DefaultOrchestrator) trimScreenshotWithCustomMargins(inputPath, outputPath string, top, bottom, left, right int, verbose bool) error {
 	return imageprocessing.TrimImageFileWithCustomMargins(inputPath, outputPath, top, bottom, left, right)
 }
+
+// trimOptions returns the border thresholds for margin detection
+func trimOptions(options *config.ConversionOptions) imageprocessing.TrimOptions {
+	return imageprocessing.TrimOptions{
+		BlackThreshold: options.BlackThreshold,
+		WhiteThreshold: options.WhiteThreshold,
+	}
+}
+
+// checkTrimFits fails fast when the custom trim margins would leave nothing of
+// a captured page, reporting the page size (after Rotate, which trimming
+// applies to) so the values can be corrected before the whole book is captured
+func checkTrimFits(img image.Image, options *config.ConversionOptions) error {
+	bounds := img.Bounds()
+	width, height := imageprocessing.RotatedSize(bounds.Dx(), bounds.Dy(), options.Rotate)
+	margins := imageprocessing.TrimMargins{
+		Top:    options.TrimTop,
+		Bottom: options.TrimBottom,
+		Left:   options.TrimHorizontal,
+		Right:  options.TrimHorizontal,
+	}
+	if err := imageprocessing.CheckTrimMargins(width, height, margins); err != nil {
+		return fmt.Errorf("%w; lower TrimTop/TrimBottom/TrimHorizontal", err)
+	}
+	return nil
+}
+
+// trimAggregatePercentiles maps TrimAggregate to a percentile ("min" = 0)
+var trimAggregatePercentiles = map[string]float64{"": 0, "min": 0, "p10": 10, "p25": 25}
+
+// aggregateMargins combines per-page margins as selected by TrimAggregate
+func aggregateMargins(margins []imageprocessing.TrimMargins, mode string) imageprocessing.TrimMargins {
+	p := trimAggregatePercentiles[mode]
+	if p <= 0 {
+		return imageprocessing.AggregateMinimumMargins(margins)
+	}
+	return imageprocessing.AggregatePercentileMargins(margins, p)
+}
+
+// maxListedOutliers caps the page numbers listed per edge in detect mode
+const maxListedOutliers = 5
+
+// edgeStats summarizes one margin edge across the analyzed pages
+type edgeStats struct {
+	Min, Median, Max int
+
+	// Indices into the margins slice of the pages at Min, only when Min is
+	// below Median (otherwise no page stands out)
+	MinPages []int
+}
+
+// marginEdgeStats returns min/median/max and the minimum outliers of one edge
+func marginEdgeStats(margins []imageprocessing.TrimMargins, get func(imageprocessing.TrimMargins) int) edgeStats {
+	if len(margins) == 0 {
+		return edgeStats{}
+	}
+
+	values := make([]int, len(margins))
+	for i, m := range margins {
+		values[i] = get(m)
+	}
+	sorted := append([]int(nil), values...)
+	sort.Ints(sorted)
+
+	stats := edgeStats{Min: sorted[0], Median: sorted[(len(sorted)-1)/2], Max: sorted[len(sorted)-1]}
+	if stats.Min < stats.Median {
+		for i, v := range values {
+			if v == stats.Min {
+				stats.MinPages = append(stats.MinPages, i)
+			}
+		}
+	}
+	return stats
+}
+
+// printMarginStats reports per-edge spread so an outlier page dragging the
+// minimum (cover, full-bleed diagram) is visible. firstPage is the page
+// number of margins[0]
+func (o *DefaultOrchestrator) printMarginStats(margins []imageprocessing.TrimMargins, firstPage int) {
+	if len(margins) == 0 {
+		return
+	}
+
+	o.println("\nMargin spread (pixels):")
+	o.printf("  %-7s %6s %6s %6s  %s\n", "", "Min", "Median", "Max", "Pages at min")
+	for _, edge := range []struct {
+		name string
+		get  func(imageprocessing.TrimMargins) int
+	}{
+		{"Top", func(m imageprocessing.TrimMargins) int { return m.Top }},
+		{"Bottom", func(m imageprocessing.TrimMargins) int { return m.Bottom }},
+		{"Left", func(m imageprocessing.TrimMargins) int { return m.Left }},
+		{"Right", func(m imageprocessing.TrimMargins) int { return m.Right }},
+	} {
+		stats := marginEdgeStats(margins, edge.get)
+
+		pages := "-"
+		if len(stats.MinPages) > 0 {
+			var listed []string
+			for i, idx := range stats.MinPages {
+				if i == maxListedOutliers {
+					listed = append(listed, fmt.Sprintf("+%d more", len(stats.MinPages)-maxListedOutliers))
+					break
+				}
+				listed = append(listed, fmt.Sprintf("%d", firstPage+idx))
+			}
+			pages = strings.Join(listed, ", ")
+		}
+		o.printf("  %-7s %6d %6d %6d  %s\n", edge.name+":", stats.Min, stats.Median, stats.Max, pages)
+	}
+}
+
+// trimPreview trims a single image file with the configured margins and writes
+// it to OutputFile (default: <input>_trimmed.png), so trim values can be
+// checked on one page before converting a whole book. Without custom margins
+// the auto-detected ones are used, as detect mode would suggest them
+func (o *DefaultOrchestrator) trimPreview(options *config.ConversionOptions) (*ConversionResult, error) {
+	startTime := time.Now()
+
+	img, err := imageprocessing.LoadImage(options.InputFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", options.InputFile, err)
+	}
+	// Trim values refer to the rotated page, as in the conversion pipeline
+	img = imageprocessing.Rotate(img, options.Rotate)
+
+	margins := imageprocessing.TrimMargins{
+		Top:    options.TrimTop,
+		Bottom: options.TrimBottom,
+		Left:   options.TrimHorizontal,
+		Right:  options.TrimHorizontal,
+	}
+	source := "custom"
+	if margins == (imageprocessing.TrimMargins{}) {
+		margins = imageprocessing.CalculateTrimMarginsWithOptions(img, trimOptions(options))
+		source = "auto-detected"
+	}
+
+	bounds := img.Bounds()
+	if err := imageprocessing.CheckTrimMargins(bounds.Dx(), bounds.Dy(), margins); err != nil {
+		return nil, err
+	}
+	width := bounds.Dx() - margins.Left - margins.Right
+	height := bounds.Dy() - margins.Top - margins.Bottom
+
+	outputPath := options.OutputFile
+	if outputPath == "" {
+		outputPath = strings.TrimSuffix(options.InputFile, filepath.Ext(options.InputFile)) + "_trimmed.png"
+	}
+	trimmed := imageprocessing.TrimWithCustomMargins(img, margins.Top, margins.Bottom, margins.Left, margins.Right)
+	if err := imageprocessing.SavePNG(trimmed, outputPath); err != nil {
+		return nil, err
+	}
+
+	o.printf("Trim preview (%s margins): Top=%d Bottom=%d Left=%d Right=%d\n",
+		source, margins.Top, margins.Bottom, margins.Left, margins.Right)
+	o.printf("  %dx%d -> %dx%d\n", bounds.Dx(), bounds.Dy(), width, height)
+	o.printf("Saved: %s\n", outputPath)
+
+	return &ConversionResult{
+		OutputPath:      outputPath,
+		PageCount:       1,
+		Duration:        time.Since(startTime),
+		DetectedMargins: &margins,
+		Warnings:        []string{},
+	}, nil
+}
@@ -0,0 +1,134 @@
+package orchestrator
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/oumi/k2p/internal/config"
+	"github.com/oumi/k2p/internal/imageprocessing"
+	"github.com/oumi/k2p/internal/screenshot"
+)
+
+// Trim preview: trims one image file without touching Kindle
+func TestTrimPreview(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "page.png")
+	img := uniformPage(color.White).(*image.RGBA)
+	draw.Draw(img, image.Rect(20, 30, 80, 70), image.NewUniform(color.Black), image.Point{}, draw.Src)
+	if err := imageprocessing.SavePNG(img, input); err != nil {
+		t.Fatal(err)
+	}
+
+	// No automation or capturer: the mode must not need Kindle
+	orch := &DefaultOrchestrator{}
+	orch.SetLogWriter(io.Discard)
+	run := func(opts *config.ConversionOptions) (image.Rectangle, error) {
+		opts.Mode = "trim-preview"
+		opts.InputFile = input
+		result, err := orch.ConvertCurrentBook(context.Background(), opts)
+		if err != nil {
+			return image.Rectangle{}, err
+		}
+		out, err := imageprocessing.LoadImage(result.OutputPath)
+		if err != nil {
+			return image.Rectangle{}, err
+		}
+		return image.Rect(0, 0, out.Bounds().Dx(), out.Bounds().Dy()), nil
+	}
+
+	custom := filepath.Join(dir, "custom.png")
+	if got, err := run(&config.ConversionOptions{TrimTop: 10, TrimBottom: 20, TrimHorizontal: 5, OutputFile: custom}); err != nil {
+		t.Fatalf("custom trim failed: %v", err)
+	} else if got != image.Rect(0, 0, 90, 70) {
+		t.Errorf("custom trim: expected 90x70, got %v", got)
+	}
+	if _, err := os.Stat(custom); err != nil {
+		t.Errorf("expected output at %s: %v", custom, err)
+	}
+
+	// Auto-detected margins trim down to the content box
+	if got, err := run(&config.ConversionOptions{}); err != nil {
+		t.Fatalf("auto trim failed: %v", err)
+	} else if got.Dx() >= 100 || got.Dy() >= 100 {
+		t.Errorf("auto trim: expected a smaller image, got %v", got)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "page_trimmed.png")); err != nil {
+		t.Errorf("expected default output next to the input: %v", err)
+	}
+
+	if _, err := run(&config.ConversionOptions{TrimTop: 60, TrimBottom: 60}); err == nil {
+		t.Error("expected an error when the margins remove the whole image")
+	}
+}
+
+// Trim aggregation: "min" keeps the outlier's margin, percentiles ignore it
+func TestAggregateMargins(t *testing.T) {
+	margins := []imageprocessing.TrimMargins{{Top: 0}, {Top: 30}, {Top: 32}, {Top: 35}}
+	for mode, want := range map[string]int{"": 0, "min": 0, "p25": 0, "p10": 0} {
+		if got := aggregateMargins(margins, mode).Top; got != want {
+			t.Errorf("%q: expected top %d, got %d", mode, want, got)
+		}
+	}
+
+	margins = append(margins, imageprocessing.TrimMargins{Top: 40}, imageprocessing.TrimMargins{Top: 41},
+		imageprocessing.TrimMargins{Top: 42}, imageprocessing.TrimMargins{Top: 43})
+	if got := aggregateMargins(margins, "p25").Top; got != 30 {
+		t.Errorf("p25 of 8 pages: expected top 30, got %d", got)
+	}
+	if got := aggregateMargins(margins, "min").Top; got != 0 {
+		t.Errorf("min: expected top 0, got %d", got)
+	}
+}
+
+// Margin spread: min/median/max per edge and the pages dragging the minimum
+func TestMarginEdgeStats(t *testing.T) {
+	margins := []imageprocessing.TrimMargins{{Top: 30}, {Top: 0}, {Top: 32}, {Top: 35}, {Top: 0}}
+	stats := marginEdgeStats(margins, func(m imageprocessing.TrimMargins) int { return m.Top })
+	if stats.Min != 0 || stats.Median != 30 || stats.Max != 35 {
+		t.Errorf("expected 0/30/35, got %d/%d/%d", stats.Min, stats.Median, stats.Max)
+	}
+	if len(stats.MinPages) != 2 || stats.MinPages[0] != 1 || stats.MinPages[1] != 4 {
+		t.Errorf("expected min pages [1 4], got %v", stats.MinPages)
+	}
+
+	// Uniform margins: nothing stands out
+	stats = marginEdgeStats(margins, func(m imageprocessing.TrimMargins) int { return m.Left })
+	if len(stats.MinPages) != 0 {
+		t.Errorf("expected no outliers for uniform edge, got %v", stats.MinPages)
+	}
+
+	var buf bytes.Buffer
+	o := &DefaultOrchestrator{}
+	o.SetLogWriter(&buf)
+	o.printMarginStats(margins, 3)
+	if !strings.Contains(buf.String(), "4, 7") {
+		t.Errorf("expected page numbers offset by first page, got:\n%s", buf.String())
+	}
+}
+
+// Trim margins larger than the captured page stop the run after the first page
+func TestTrimLargerThanPage(t *testing.T) {
+	orch := newTestOrchestrator(t, screenshot.NewSyntheticCapturer(6))
+	mock := orch.automation.(*MockAutomation)
+	opts := generateOptions()
+	opts.TrimTop = 2000
+	opts.TrimBottom = 2000
+
+	_, err := orch.ConvertCurrentBook(context.Background(), opts)
+	if !errors.Is(err, imageprocessing.ErrTrimTooLarge) {
+		t.Fatalf("expected ErrTrimTooLarge, got %v", err)
+	}
+	pdfGen := orch.pdfGen.(*MockPDFGenerator)
+	if !strings.Contains(err.Error(), "300x400") || mock.TurnCount != 0 || pdfGen.ImageFiles != nil {
+		t.Errorf("expected the page size, no page turns and no PDF, got %q, %d turns", err, mock.TurnCount)
+	}
+}
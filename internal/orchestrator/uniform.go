@@ -0,0 +1,62 @@
+package orchestrator
+
+import (
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+
+	"github.com/oumi/k2p/internal/config"
+	"github.com/oumi/k2p/internal/imageprocessing"
+)
+
+// uniformPages pads every page to the largest width and height among the
+// pages (UniformPages), centring it on BackgroundColor or, without one, on
+// its own background colour, so pages whose trimmed or captured size differs
+// slightly still give one page size
+// Returns the page list and the number of padded pages; pages that cannot be
+// read or padded stay as they are
+func (o *DefaultOrchestrator) uniformPages(screenshots []string, tempDir string, options *config.ConversionOptions, issues *pageIssues) ([]string, int) {
+	sizes := make([]image.Point, len(screenshots))
+	var largest image.Point
+	for i, path := range screenshots {
+		size, err := imageprocessing.ImageSize(path)
+		if err != nil {
+			issues.add(issuePad)
+			if options.Verbose {
+				o.printf("  Warning: Failed to read size of page %d: %v\n", i+1, err)
+			}
+		}
+		sizes[i] = size
+		largest.X = max(largest.X, size.X)
+		largest.Y = max(largest.Y, size.Y)
+	}
+
+	bg, _ := config.ParseBackgroundColor(options.BackgroundColor)
+	padded := append([]string(nil), screenshots...)
+	done := make([]bool, len(screenshots))
+	forEachPage(len(screenshots), options.MaxConcurrency, func(i int) {
+		if sizes[i] == (image.Point{}) || sizes[i] == largest {
+			return
+		}
+		paddedPath := filepath.Join(tempDir, fmt.Sprintf("page_%04d_padded.png", i+1))
+		if err := imageprocessing.PadImageFile(screenshots[i], paddedPath, largest.X, largest.Y, bg); err != nil {
+			issues.add(issuePad)
+			if options.Verbose {
+				o.printf("  Warning: Failed to pad page %d, keeping it: %v\n", i+1, err)
+			}
+			return
+		}
+		padded[i] = paddedPath
+		done[i] = true
+		os.Remove(screenshots[i])
+	})
+
+	count := 0
+	for _, ok := range done {
+		if ok {
+			count++
+		}
+	}
+	return padded, count
+}
@@ -0,0 +1,51 @@
+package orchestrator
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"path/filepath"
+	"testing"
+
+	"github.com/oumi/k2p/internal/config"
+	"github.com/oumi/k2p/internal/imageprocessing"
+)
+
+// Uniform pages: every page is padded to the largest width and height
+func TestUniformPages(t *testing.T) {
+	tmpDir := t.TempDir()
+	var pages []string
+	for i, size := range []image.Point{{300, 400}, {296, 410}, {300, 400}} {
+		path := filepath.Join(tmpDir, fmt.Sprintf("page_%d.png", i))
+		if err := imageprocessing.SavePNG(image.NewRGBA(image.Rectangle{Max: size}), path); err != nil {
+			t.Fatal(err)
+		}
+		pages = append(pages, path)
+	}
+
+	orch := &DefaultOrchestrator{}
+	orch.SetLogWriter(io.Discard)
+	padded, count := orch.uniformPages(pages, tmpDir, &config.ConversionOptions{BackgroundColor: "#102030"}, nil)
+	if count != 3 {
+		t.Errorf("expected 3 padded pages, got %d", count)
+	}
+	for i, path := range padded {
+		size, err := imageprocessing.ImageSize(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if size != image.Pt(300, 410) {
+			t.Errorf("page %d: expected 300x410, got %v", i+1, size)
+		}
+	}
+
+	// The padding takes BackgroundColor
+	img, err := imageprocessing.LoadImage(padded[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := color.RGBAModel.Convert(img.At(0, 0)), (color.RGBA{R: 0x10, G: 0x20, B: 0x30, A: 255}); got != want {
+		t.Errorf("expected padding %v, got %v", want, got)
+	}
+}
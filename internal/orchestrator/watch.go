@@ -0,0 +1,112 @@
+package orchestrator
+
+import (
+	"context"
+	"time"
+
+	"github.com/oumi/k2p/internal/automation"
+	"github.com/oumi/k2p/internal/config"
+)
+
+// Watch polls the title of the open book and converts every newly opened book
+// The book open when watching starts is treated as already seen. A title must
+// stay unchanged for WatchDebounce before it is converted, so quickly paging
+// through the library does not trigger conversions. Each output file is named
+// after the book title. With StateFile set, titles in it count as seen, and
+// each converted book is added to it. Watching stops when the context is
+// cancelled
+func (o *DefaultOrchestrator) Watch(ctx context.Context, options *config.ConversionOptions) (*BatchResult, error) {
+	startTime := time.Now()
+	batch := &BatchResult{}
+
+	state, err := loadState(options.StateFile)
+	if err != nil {
+		return nil, err
+	}
+
+	interval := options.WatchInterval
+	if interval <= 0 {
+		interval = 3 * time.Second
+	}
+
+	seen := make(map[string]bool)
+	for _, b := range state.Books {
+		seen[b.Title] = true
+	}
+	if len(state.Books) > 0 {
+		o.printf("State file %s: %d books already converted\n", state.path, len(state.Books))
+	}
+	o.automation.SetCommandTimeout(options.CommandTimeout)
+	o.automation.SetTarget(automation.TargetForApp(options.TargetApp))
+	// Each book runs with a session of its own; polling goes back to this one
+	session := o.newSession(options)
+	o.automation.SetSession(session)
+	if title, err := o.automation.GetBookTitle(); err == nil && title != "" {
+		seen[title] = true
+		o.printf("Watching for new books (current: %s). Press Stop or Ctrl+C to finish.\n", title)
+	} else {
+		o.println("Watching for new books. Press Stop or Ctrl+C to finish.")
+	}
+
+	candidate := ""
+	var candidateSince time.Time
+	// Last state-file title reported as skipped, so it is logged once per opening
+	skipped := ""
+	bookNum := 0
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			batch.Duration = time.Since(startTime)
+			o.printBatchSummary(batch)
+			return batch, nil
+		case <-ticker.C:
+		}
+
+		title, err := o.automation.GetBookTitle()
+		if err == nil && title != skipped && state.done(title) {
+			skipped = title
+			o.printf("Skipping %s: already converted (listed in %s)\n", title, state.path)
+		}
+		if err != nil || title == "" || seen[title] {
+			candidate = ""
+			if err != nil && options.Verbose {
+				o.printf("Warning: failed to get book title: %v\n", err)
+			}
+			continue
+		}
+
+		// Debounce: wait until the same new title has been stable long enough
+		if title != candidate {
+			candidate = title
+			candidateSince = time.Now()
+			if options.Verbose {
+				o.printf("New book detected: %s (waiting %s)\n", title, options.WatchDebounce)
+			}
+		}
+		if time.Since(candidateSince) < options.WatchDebounce {
+			continue
+		}
+
+		seen[title] = true
+		candidate = ""
+		bookNum++
+
+		bookOptions := *options
+		bookOptions.AutoConfirm = true
+		bookOptions.OutputFileName = title
+
+		o.printf("\n=== Book %d: %s ===\n", bookNum, title)
+		result, err := o.ConvertCurrentBook(ctx, &bookOptions)
+		o.automation.SetSession(session)
+		batch.Entries = append(batch.Entries, BatchEntry{BookNumber: bookNum, Result: result, Err: err})
+		if err != nil {
+			o.printf("Book %d failed: %v\n", bookNum, err)
+			continue
+		}
+		o.recordCompleted(state, title, result)
+	}
+}
@@ -0,0 +1,42 @@
+package orchestrator
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/oumi/k2p/internal/config"
+)
+
+func TestWatchConvertsNewBooks(t *testing.T) {
+	orch := newTestOrchestrator(t, &MockCapturer{})
+	orch.automation.(*MockAutomation).Titles = []string{"Already Open", "Book/One", "Book/One", "Already Open", "Book Two"}
+	outputDir := filepath.Dir(orch.fileManager.(*MockFileManager).ResolvePath)
+
+	opts := &config.ConversionOptions{
+		Mode:          "generate",
+		PageDelay:     time.Millisecond,
+		PageTurnKey:   "right",
+		WatchInterval: time.Millisecond,
+		StateFile:     filepath.Join(t.TempDir(), "state.json"),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+
+	batch, err := orch.Watch(ctx, opts)
+	if err != nil {
+		t.Fatalf("Watch returned error: %v", err)
+	}
+	results := batch.Results()
+	if len(results) != 2 {
+		t.Fatalf("expected 2 converted books, got %d", len(results))
+	}
+	if want := filepath.Join(outputDir, "Book_One.pdf"); results[0].OutputPath != want {
+		t.Errorf("unexpected output path for first book: %s", results[0].OutputPath)
+	}
+	if want := filepath.Join(outputDir, "Book Two.pdf"); results[1].OutputPath != want {
+		t.Errorf("unexpected output path for second book: %s", results[1].OutputPath)
+	}
+}
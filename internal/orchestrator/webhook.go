@@ -0,0 +1,80 @@
+package orchestrator
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/oumi/k2p/internal/config"
+)
+
+// webhookTimeout bounds the whole webhook request
+const webhookTimeout = 10 * time.Second
+
+// WebhookPayload is the JSON body posted to the Webhook URL
+type WebhookPayload struct {
+	Mode            string   `json:"mode"`
+	Success         bool     `json:"success"`
+	OutputPath      string   `json:"output_path,omitempty"`
+	Pages           int      `json:"pages"`
+	SizeBytes       int64    `json:"size_bytes"`
+	DurationSeconds float64  `json:"duration_seconds"`
+	Warnings        []string `json:"warnings,omitempty"`
+	Error           string   `json:"error,omitempty"`
+	AppVersion      string   `json:"app_version,omitempty"`
+}
+
+// newWebhookPayload summarizes a finished conversion
+func newWebhookPayload(options *config.ConversionOptions, result *ConversionResult, err error) WebhookPayload {
+	payload := WebhookPayload{Mode: options.Mode, Success: err == nil}
+	if result != nil {
+		payload.OutputPath = result.OutputPath
+		payload.Pages = result.PageCount
+		payload.SizeBytes = result.FileSize
+		payload.DurationSeconds = result.Duration.Seconds()
+		payload.Warnings = result.Warnings
+		payload.AppVersion = result.AppVersion
+	}
+	if err != nil {
+		payload.Error = err.Error()
+	}
+	return payload
+}
+
+// printSummaryJSON prints the conversion outcome as one line of JSON, the
+// same document the Webhook receives (OutputSummary "json")
+func (o *DefaultOrchestrator) printSummaryJSON(options *config.ConversionOptions, result *ConversionResult) {
+	body, err := json.Marshal(newWebhookPayload(options, result, nil))
+	if err != nil {
+		o.printf("Warning: failed to encode summary: %v\n", err)
+		return
+	}
+	o.println(string(body))
+}
+
+// postWebhook sends the conversion outcome to the Webhook URL
+// Webhook failures never change the conversion result; they are only logged
+func (o *DefaultOrchestrator) postWebhook(options *config.ConversionOptions, result *ConversionResult, err error) {
+	body, merr := json.Marshal(newWebhookPayload(options, result, err))
+	if merr != nil {
+		o.printf("Warning: failed to encode webhook payload: %v\n", merr)
+		return
+	}
+
+	client := &http.Client{Timeout: webhookTimeout}
+	resp, perr := client.Post(options.Webhook, "application/json", bytes.NewReader(body))
+	if perr != nil {
+		o.printf("Warning: webhook failed: %v\n", perr)
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		o.printf("Warning: webhook returned %s\n", resp.Status)
+		return
+	}
+	if options.Verbose {
+		o.printf("Webhook notified: %s\n", options.Webhook)
+	}
+}
@@ -0,0 +1,93 @@
+package orchestrator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// Webhook: the JSON summary is posted, and a failing webhook does not fail the conversion
+func TestWebhook(t *testing.T) {
+	var payloads []WebhookPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var p WebhookPayload
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			t.Errorf("invalid payload: %v", err)
+		}
+		payloads = append(payloads, p)
+		if r.URL.Path == "/broken" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	run := func(auto *MockAutomation, webhook string) (*ConversionResult, error) {
+		orch := newTestOrchestrator(t, &MockCapturer{})
+		orch.automation = auto
+		opts := generateOptions()
+		opts.Webhook = webhook
+		return orch.ConvertCurrentBook(context.Background(), opts)
+	}
+
+	ready := readyAutomation()
+	result, err := run(ready, server.URL+"/hook")
+	if err != nil {
+		t.Fatalf("conversion failed: %v", err)
+	}
+	if len(payloads) != 1 || !payloads[0].Success || payloads[0].OutputPath != result.OutputPath ||
+		payloads[0].Pages != result.PageCount {
+		t.Errorf("unexpected success payload: %+v", payloads)
+	}
+
+	if _, err := run(&MockAutomation{}, server.URL+"/hook"); err == nil {
+		t.Fatal("expected conversion error without Kindle")
+	}
+	if len(payloads) != 2 || payloads[1].Success || payloads[1].Error == "" {
+		t.Errorf("unexpected failure payload: %+v", payloads[1:])
+	}
+
+	// Neither a 500 nor an unreachable server fails the conversion
+	if _, err := run(ready, server.URL+"/broken"); err != nil {
+		t.Errorf("webhook error changed the result: %v", err)
+	}
+	if _, err := run(ready, "http://127.0.0.1:1/hook"); err != nil {
+		t.Errorf("unreachable webhook changed the result: %v", err)
+	}
+}
+
+// JSON summary: the last output line is the summary document and the
+// decorative block is left out
+func TestOutputSummaryJSON(t *testing.T) {
+	orch := newTestOrchestrator(t, &MockCapturer{})
+	orch.automation.(*MockAutomation).Version = "7.35"
+	var logs bytes.Buffer
+	orch.SetLogWriter(&logs)
+	opts := generateOptions()
+	opts.OutputSummary = "json"
+
+	result, err := orch.ConvertCurrentBook(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("conversion failed: %v", err)
+	}
+	output := logs.String()
+	if strings.Contains(output, "=== Conversion Complete ===") {
+		t.Error("expected no human-readable summary")
+	}
+
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	var summary WebhookPayload
+	if err := json.Unmarshal([]byte(lines[len(lines)-1]), &summary); err != nil {
+		t.Fatalf("expected the last line to be JSON: %v\n%s", err, output)
+	}
+	if !summary.Success || summary.OutputPath != result.OutputPath || summary.Pages != result.PageCount {
+		t.Errorf("unexpected summary: %+v", summary)
+	}
+	// The Kindle version goes into the summary for bug reports
+	if result.AppVersion != "7.35" || summary.AppVersion != "7.35" {
+		t.Errorf("expected app version 7.35, got %q in the result and %q in the summary", result.AppVersion, summary.AppVersion)
+	}
+}
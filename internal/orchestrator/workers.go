@@ -0,0 +1,37 @@
+package orchestrator
+
+import (
+	"runtime"
+	"sync"
+)
+
+// forEachPage calls fn for page indexes 0..n-1 with at most maxConcurrency
+// calls running at once (0 or less = runtime.NumCPU())
+// Every image post-processing pass goes through here so they share one limit;
+// fn should store its result by index so page order is preserved
+func forEachPage(n, maxConcurrency int, fn func(i int)) {
+	if maxConcurrency <= 0 {
+		maxConcurrency = runtime.NumCPU()
+	}
+	if maxConcurrency > n {
+		maxConcurrency = n
+	}
+
+	pages := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < maxConcurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range pages {
+				fn(i)
+			}
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		pages <- i
+	}
+	close(pages)
+	wg.Wait()
+}
@@ -0,0 +1,37 @@
+package orchestrator
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// forEachPage visits every page once and never exceeds the concurrency cap
+func TestForEachPageConcurrencyCap(t *testing.T) {
+	for _, limit := range []int{1, 3} {
+		var active, peak int32
+		visited := make([]int32, 20)
+
+		forEachPage(len(visited), limit, func(i int) {
+			n := atomic.AddInt32(&active, 1)
+			for {
+				p := atomic.LoadInt32(&peak)
+				if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+					break
+				}
+			}
+			time.Sleep(2 * time.Millisecond)
+			atomic.AddInt32(&visited[i], 1)
+			atomic.AddInt32(&active, -1)
+		})
+
+		if peak > int32(limit) {
+			t.Errorf("limit %d: peak concurrency %d", limit, peak)
+		}
+		for i, v := range visited {
+			if v != 1 {
+				t.Errorf("limit %d: page %d visited %d times", limit, i, v)
+			}
+		}
+	}
+}
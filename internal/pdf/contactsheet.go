@@ -0,0 +1,89 @@
+package pdf
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// contactSheetCaptionSize is the font size of thumbnail captions in points
+const contactSheetCaptionSize float64 = 8
+
+// minContactSheetCellWidth is the narrowest thumbnail accepted in points
+const minContactSheetCellWidth float64 = 36
+
+// contactSheetCaptionHeight is the space below each thumbnail for its caption
+const contactSheetCaptionHeight = 2 * contactSheetCaptionSize
+
+// validateContactSheet checks that the columns fit on the sheet
+func validateContactSheet(options PDFOptions) error {
+	sheet := nUpSheet(options)
+	cellWidth, _ := nUpCellSize(sheet, options.ContactSheetColumns, 1, options.PageMargin)
+	if cellWidth < minContactSheetCellWidth {
+		return fmt.Errorf("%d contact sheet columns would be %.0fpt wide on %s pages (minimum %.0fpt); use fewer columns or a smaller margin",
+			options.ContactSheetColumns, cellWidth, nUpSheetName(options), minContactSheetCellWidth)
+	}
+	return nil
+}
+
+// addContactSheetPages tiles the images on portrait sheets in reading order,
+// ContactSheetColumns per row, each with its caption below
+// Cells take the aspect ratio of the first image, and as many rows as fit
+func addContactSheetPages(pdf *gofpdf.Fpdf, imageFiles []string, options PDFOptions) error {
+	sheet := nUpSheet(options)
+	cols := options.ContactSheetColumns
+	cellWidth, _ := nUpCellSize(sheet, cols, 1, options.PageMargin)
+
+	_, firstWidth, firstHeight, err := registerImage(pdf, imageFiles[0], options.DPI)
+	if err != nil {
+		return err
+	}
+	thumbHeight := cellWidth * firstHeight / firstWidth
+	usable := sheet.Ht - 2*options.PageMargin
+	rows := int((usable + nUpGutter) / (thumbHeight + contactSheetCaptionHeight + nUpGutter))
+	if rows < 1 {
+		// Very tall pages: one row, shrunk to fit the sheet
+		rows = 1
+		thumbHeight = usable - contactSheetCaptionHeight
+	}
+	cell := gofpdf.SizeType{Wd: cellWidth, Ht: thumbHeight}
+	perSheet := cols * rows
+
+	pdf.SetTextColor(0, 0, 0)
+	for i, imgPath := range imageFiles {
+		slot := i % perSheet
+		if slot == 0 {
+			pdf.AddPageFormat("P", sheet)
+		}
+
+		opts, imgWidth, imgHeight, err := registerImage(pdf, imgPath, options.DPI)
+		if err != nil {
+			return err
+		}
+
+		cellX := options.PageMargin + float64(slot%cols)*(cellWidth+nUpGutter)
+		cellY := options.PageMargin + float64(slot/cols)*(thumbHeight+contactSheetCaptionHeight+nUpGutter)
+		x, y, w, h := fitImage(imgWidth, imgHeight, cell, 0)
+		pdf.ImageOptions(imgPath, cellX+x, cellY+y, w, h, false, opts, 0, "")
+
+		label := contactSheetLabel(options, i)
+		pdf.SetFont("Helvetica", "", contactSheetCaptionSize)
+		pdf.Text(cellX+(cellWidth-pdf.GetStringWidth(label))/2, cellY+thumbHeight+1.5*contactSheetCaptionSize, label)
+
+		lastOnSheet := slot == perSheet-1 || i == len(imageFiles)-1
+		if options.StampPageNumbers && lastOnSheet {
+			stampPageNumber(pdf, pdf.PageNo(), sheet.Wd, sheet.Ht, options)
+		}
+	}
+
+	return nil
+}
+
+// contactSheetLabel returns the caption of the i-th image (from 0)
+func contactSheetLabel(options PDFOptions, i int) string {
+	if i < len(options.PageLabels) && options.PageLabels[i] != "" {
+		return options.PageLabels[i]
+	}
+	return strconv.Itoa(i + 1)
+}
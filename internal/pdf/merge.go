@@ -0,0 +1,60 @@
+package pdf
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+)
+
+// pdfcpu otherwise creates a configuration directory in the user's config dir
+func init() {
+	api.DisableConfigDir()
+}
+
+// ValidateFile checks that path exists and is a readable PDF document
+func ValidateFile(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("cannot read %s: %w", path, err)
+	}
+	if info.IsDir() {
+		return fmt.Errorf("%s is a directory, not a PDF", path)
+	}
+	if err := api.ValidateFile(path, model.NewDefaultConfiguration()); err != nil {
+		return fmt.Errorf("%s is not a valid PDF: %w", path, err)
+	}
+	return nil
+}
+
+// AppendFile appends the pages of extraPath to the PDF at basePath
+// The merged document is written next to basePath and renamed over it, so a
+// failed merge leaves basePath unchanged
+func AppendFile(basePath, extraPath string) error {
+	if err := api.MergeAppendFile([]string{extraPath}, basePath, false, model.NewDefaultConfiguration()); err != nil {
+		return fmt.Errorf("failed to append pages to %s: %w", basePath, err)
+	}
+	return nil
+}
+
+// MergeFiles concatenates the PDFs at inputPaths, in order, into outputPath
+// Nothing is left at outputPath when the merge fails
+func MergeFiles(inputPaths []string, outputPath string) error {
+	if len(inputPaths) == 0 {
+		return fmt.Errorf("no PDFs to merge")
+	}
+	if err := api.MergeCreateFile(inputPaths, outputPath, false, model.NewDefaultConfiguration()); err != nil {
+		return fmt.Errorf("failed to merge PDFs into %s: %w", outputPath, err)
+	}
+	return nil
+}
+
+// PageCount returns the number of pages in a PDF file
+func PageCount(path string) (int, error) {
+	count, err := api.PageCountFile(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count pages of %s: %w", path, err)
+	}
+	return count, nil
+}
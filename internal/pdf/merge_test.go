@@ -0,0 +1,98 @@
+package pdf
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// createTestPDF writes a PDF with one page per size
+func createTestPDF(t *testing.T, dir, name string, sizes []int) string {
+	t.Helper()
+	var images []string
+	for i, size := range sizes {
+		imgPath := filepath.Join(dir, fmt.Sprintf("%s_%d.png", name, i))
+		if err := createDummyImage(imgPath, size, size, "png"); err != nil {
+			t.Fatalf("failed to create test image: %v", err)
+		}
+		images = append(images, imgPath)
+	}
+	path := filepath.Join(dir, name+".pdf")
+	if err := NewPDFGenerator().CreatePDF(images, path, PDFOptions{Quality: "high"}); err != nil {
+		t.Fatalf("CreatePDF failed: %v", err)
+	}
+	return path
+}
+
+func TestValidateFile(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	if err := ValidateFile(createTestPDF(t, tmpDir, "book", []int{100})); err != nil {
+		t.Errorf("expected generated PDF to be valid, got %v", err)
+	}
+
+	notPDF := filepath.Join(tmpDir, "notes.pdf")
+	if err := os.WriteFile(notPDF, []byte("not a pdf"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{notPDF, filepath.Join(tmpDir, "missing.pdf"), tmpDir} {
+		if err := ValidateFile(path); err == nil {
+			t.Errorf("expected error for %s", path)
+		}
+	}
+}
+
+func TestAppendFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	base := createTestPDF(t, tmpDir, "chapters1-3", []int{100, 110, 120})
+	extra := createTestPDF(t, tmpDir, "chapters4-6", []int{130, 140})
+
+	if err := AppendFile(base, extra); err != nil {
+		t.Fatalf("AppendFile failed: %v", err)
+	}
+
+	count, err := PageCount(base)
+	if err != nil {
+		t.Fatalf("PageCount failed: %v", err)
+	}
+	if count != 5 {
+		t.Errorf("expected 5 pages after appending, got %d", count)
+	}
+	if _, err := os.Stat(base + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("expected temporary merge file to be gone, got %v", err)
+	}
+}
+
+func TestMergeFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	parts := []string{
+		createTestPDF(t, tmpDir, "part1", []int{100, 110}),
+		createTestPDF(t, tmpDir, "part2", []int{120}),
+		createTestPDF(t, tmpDir, "part3", []int{130, 140, 150}),
+	}
+	output := filepath.Join(tmpDir, "merged.pdf")
+
+	if err := MergeFiles(parts, output); err != nil {
+		t.Fatalf("MergeFiles failed: %v", err)
+	}
+	count, err := PageCount(output)
+	if err != nil {
+		t.Fatalf("PageCount failed: %v", err)
+	}
+	if count != 6 {
+		t.Errorf("expected 6 pages, got %d", count)
+	}
+
+	if err := MergeFiles(nil, output); err == nil {
+		t.Error("expected error for empty input list")
+	}
+
+	broken := filepath.Join(tmpDir, "broken.pdf")
+	if err := MergeFiles([]string{parts[0], filepath.Join(tmpDir, "missing.pdf")}, broken); err == nil {
+		t.Error("expected error for missing input")
+	}
+	if _, err := os.Stat(broken); !os.IsNotExist(err) {
+		t.Errorf("expected no output after failed merge, got %v", err)
+	}
+}
@@ -0,0 +1,95 @@
+package pdf
+
+import (
+	"strings"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// nUpGutter is the space between cells on an n-up page in points
+const nUpGutter float64 = 18
+
+// minNUpCellSize is the smallest cell width or height accepted for n-up in points
+const minNUpCellSize float64 = 144
+
+// nUpDefaultSheet is the sheet size used for n-up when the page size is "auto"
+const nUpDefaultSheet = "a4"
+
+// nUpSheetName returns the page size name used for n-up sheets
+func nUpSheetName(options PDFOptions) string {
+	if _, ok := pageSizes[strings.ToLower(options.PageSize)]; ok {
+		return options.PageSize
+	}
+	return "A4"
+}
+
+// nUpSheet returns the portrait sheet size for n-up and contact sheets
+func nUpSheet(options PDFOptions) gofpdf.SizeType {
+	if sheet, ok := pageSizes[strings.ToLower(options.PageSize)]; ok {
+		return sheet
+	}
+	return pageSizes[nUpDefaultSheet]
+}
+
+// nUpGrid returns the oriented sheet size and the grid for n-up layout
+// 2-up places two portrait pages side by side on a landscape sheet, 4-up
+// uses a 2x2 grid on a portrait sheet
+func nUpGrid(options PDFOptions) (gofpdf.SizeType, int, int) {
+	sheet := nUpSheet(options)
+	if options.NUp == 2 {
+		return gofpdf.SizeType{Wd: sheet.Ht, Ht: sheet.Wd}, 2, 1
+	}
+	return sheet, 2, 2
+}
+
+// nUpCellSize returns the size of one grid cell inside the margins and gutters
+func nUpCellSize(sheet gofpdf.SizeType, cols, rows int, margin float64) (float64, float64) {
+	cellWidth := (sheet.Wd - 2*margin - float64(cols-1)*nUpGutter) / float64(cols)
+	cellHeight := (sheet.Ht - 2*margin - float64(rows-1)*nUpGutter) / float64(rows)
+	return cellWidth, cellHeight
+}
+
+// addNUpPages places the images on sheets in reading order, NUp per sheet
+// A final sheet that is not full leaves its remaining cells empty
+func addNUpPages(pdf *gofpdf.Fpdf, imageFiles []string, options PDFOptions) error {
+	sheet, cols, rows := nUpGrid(options)
+	cellWidth, cellHeight := nUpCellSize(sheet, cols, rows, options.PageMargin)
+	cell := gofpdf.SizeType{Wd: cellWidth, Ht: cellHeight}
+
+	orientation := "P"
+	format := sheet
+	if sheet.Wd > sheet.Ht {
+		// gofpdf swaps width and height itself for "L"
+		orientation = "L"
+		format = gofpdf.SizeType{Wd: sheet.Ht, Ht: sheet.Wd}
+	}
+
+	for i, imgPath := range imageFiles {
+		slot := i % options.NUp
+		if slot == 0 {
+			pdf.AddPageFormat(orientation, format)
+			fillBackground(pdf, sheet, options.Background)
+		}
+
+		opts, imgWidth, imgHeight, err := registerImage(pdf, imgPath, options.DPI)
+		if err != nil {
+			return err
+		}
+
+		col := slot % cols
+		row := slot / cols
+		cellX := options.PageMargin + float64(col)*(cellWidth+nUpGutter)
+		cellY := options.PageMargin + float64(row)*(cellHeight+nUpGutter)
+
+		x, y, w, h := fitImage(imgWidth, imgHeight, cell, 0)
+		pdf.ImageOptions(imgPath, cellX+x, cellY+y, w, h, false, opts, 0, "")
+
+		// Stamp once the sheet is complete so images do not cover the number
+		lastOnSheet := slot == options.NUp-1 || i == len(imageFiles)-1
+		if options.StampPageNumbers && lastOnSheet {
+			stampPageNumber(pdf, pdf.PageNo(), sheet.Wd, sheet.Ht, options)
+		}
+	}
+
+	return nil
+}
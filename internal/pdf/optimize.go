@@ -0,0 +1,97 @@
+package pdf
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+// Optimizer shrinks a finished PDF with an external tool
+type Optimizer interface {
+	// IsInstalled reports whether a supported tool (qpdf or gs) is available
+	IsInstalled() bool
+
+	// Optimize rewrites the PDF in place and returns its size before and after
+	// The original is kept if the rewritten file is not smaller
+	Optimize(path string) (before, after int64, err error)
+}
+
+// ExternalOptimizer implements Optimizer with qpdf (preferred) or Ghostscript
+type ExternalOptimizer struct {
+	// lookPath and run are replaced in tests
+	lookPath func(file string) (string, error)
+	run      func(name string, args ...string) error
+}
+
+// NewOptimizer creates a new Optimizer instance
+func NewOptimizer() Optimizer {
+	return &ExternalOptimizer{
+		lookPath: exec.LookPath,
+		run: func(name string, args ...string) error {
+			output, err := exec.Command(name, args...).CombinedOutput()
+			if err != nil {
+				return fmt.Errorf("%s failed: %w (output: %s)", name, err, output)
+			}
+			return nil
+		},
+	}
+}
+
+// tool returns the first available optimizer binary, or "" if none is installed
+func (o *ExternalOptimizer) tool() string {
+	for _, name := range []string{"qpdf", "gs"} {
+		if _, err := o.lookPath(name); err == nil {
+			return name
+		}
+	}
+	return ""
+}
+
+// IsInstalled reports whether qpdf or gs is on PATH
+func (o *ExternalOptimizer) IsInstalled() bool {
+	return o.tool() != ""
+}
+
+// Optimize rewrites the PDF with object streams and recompressed streams
+// qpdf is lossless; Ghostscript's /prepress settings keep 300 dpi images
+func (o *ExternalOptimizer) Optimize(path string) (int64, int64, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to stat PDF: %w", err)
+	}
+	before := info.Size()
+
+	tool := o.tool()
+	if tool == "" {
+		return before, before, fmt.Errorf("no PDF optimizer installed (install qpdf or ghostscript)")
+	}
+
+	tmpPath := path + ".optimized"
+	defer os.Remove(tmpPath)
+
+	var args []string
+	switch tool {
+	case "qpdf":
+		args = []string{"--object-streams=generate", "--compress-streams=y", "--recompress-flate",
+			"--compression-level=9", path, tmpPath}
+	case "gs":
+		args = []string{"-sDEVICE=pdfwrite", "-dPDFSETTINGS=/prepress", "-dNOPAUSE", "-dBATCH", "-dQUIET",
+			"-sOutputFile=" + tmpPath, path}
+	}
+	if err := o.run(tool, args...); err != nil {
+		return before, before, err
+	}
+
+	optimized, err := os.Stat(tmpPath)
+	if err != nil {
+		return before, before, fmt.Errorf("optimized PDF not written: %w", err)
+	}
+	if optimized.Size() >= before {
+		return before, before, nil
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		return before, before, fmt.Errorf("failed to replace PDF: %w", err)
+	}
+	return before, optimized.Size(), nil
+}
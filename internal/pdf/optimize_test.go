@@ -0,0 +1,82 @@
+package pdf
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOptimizerToolSelection(t *testing.T) {
+	tests := []struct {
+		name      string
+		available map[string]bool
+		want      string
+	}{
+		{"none", map[string]bool{}, ""},
+		{"qpdf preferred", map[string]bool{"qpdf": true, "gs": true}, "qpdf"},
+		{"ghostscript fallback", map[string]bool{"gs": true}, "gs"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := &ExternalOptimizer{lookPath: func(file string) (string, error) {
+				if tt.available[file] {
+					return "/usr/bin/" + file, nil
+				}
+				return "", fmt.Errorf("not found")
+			}}
+			if got := o.tool(); got != tt.want {
+				t.Errorf("tool() = %q, want %q", got, tt.want)
+			}
+			if o.IsInstalled() != (tt.want != "") {
+				t.Errorf("IsInstalled() = %v", o.IsInstalled())
+			}
+		})
+	}
+}
+
+func TestOptimize(t *testing.T) {
+	// fakeRun writes an output of the given size to the last argument (qpdf's output path)
+	fakeRun := func(size int) func(name string, args ...string) error {
+		return func(name string, args ...string) error {
+			return os.WriteFile(args[len(args)-1], make([]byte, size), 0644)
+		}
+	}
+	newOptimizer := func(size int) *ExternalOptimizer {
+		return &ExternalOptimizer{
+			lookPath: func(file string) (string, error) { return "/usr/bin/" + file, nil },
+			run:      fakeRun(size),
+		}
+	}
+
+	for _, tt := range []struct {
+		name      string
+		outSize   int
+		wantAfter int64
+	}{
+		{"smaller output replaces original", 40, 40},
+		{"larger output keeps original", 200, 100},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "book.pdf")
+			if err := os.WriteFile(path, make([]byte, 100), 0644); err != nil {
+				t.Fatalf("failed to write PDF: %v", err)
+			}
+
+			before, after, err := newOptimizer(tt.outSize).Optimize(path)
+			if err != nil {
+				t.Fatalf("Optimize failed: %v", err)
+			}
+			if before != 100 || after != tt.wantAfter {
+				t.Errorf("sizes = %d -> %d, want 100 -> %d", before, after, tt.wantAfter)
+			}
+			if info, _ := os.Stat(path); info.Size() != tt.wantAfter {
+				t.Errorf("file size = %d, want %d", info.Size(), tt.wantAfter)
+			}
+			if _, err := os.Stat(path + ".optimized"); !os.IsNotExist(err) {
+				t.Error("temporary file not removed")
+			}
+		})
+	}
+}
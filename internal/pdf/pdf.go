@@ -1,9 +1,17 @@
 package pdf
 
 import (
+	"bytes"
+	"crypto/sha256"
 	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/jung-kurt/gofpdf"
 )
@@ -12,6 +20,10 @@ import (
 type PDFGenerator interface {
 	// CreatePDF creates a PDF from a sequence of image files
 	CreatePDF(imageFiles []string, outputPath string, options PDFOptions) error
+
+	// CreatePDFFromImages creates a PDF from decoded pages, with the same
+	// layout as CreatePDF, without reading or writing image files
+	CreatePDFFromImages(images []image.Image, outputPath string, options PDFOptions) error
 }
 
 // PDFOptions contains options for PDF generation
@@ -21,6 +33,64 @@ type PDFOptions struct {
 
 	// Enable compression
 	Compression bool
+
+	// Producer metadata field (empty = gofpdf default)
+	Producer string
+
+	// Stamp each page with its page number
+	StampPageNumbers bool
+
+	// Corner for the page number: "bottom-right" (default), "bottom-left",
+	// "bottom-center", "top-right", "top-left", "top-center"
+	StampPosition string
+
+	// Font size of the page number in points (default: 10)
+	StampFontSize float64
+
+	// Page size: "auto" (default, page matches each image), "A4", "A5",
+	// "Letter" or "Legal" (case-insensitive)
+	PageSize string
+
+	// Margin around the image in points for fixed page sizes
+	PageMargin float64
+
+	// Fills fixed-size pages and n-up sheets behind the images, so the
+	// letterbox matches the book (nil = white). "auto" pages have no letterbox
+	Background color.Color
+
+	// Images per output page: 0 or 1 (one per page), 2 (side by side on a
+	// landscape sheet) or 4 (2x2 grid on a portrait sheet)
+	NUp int
+
+	// Thumbnails per row on contact sheets (0 = normal pages). Overrides NUp;
+	// an "auto" page size uses A4 sheets
+	ContactSheetColumns int
+
+	// Caption under each contact sheet thumbnail (missing entries: the
+	// image's position, starting at 1)
+	PageLabels []string
+
+	// Resolution assigned to every image, which sets its physical size with
+	// "auto" page size (0 = the image's own DPI tag, or 72 without one)
+	DPI float64
+
+	// CreatePDFFromImages embeds pages as JPEG at this quality (1-100), like
+	// JPEG captures; 0 embeds them as PNG
+	JPEGQuality int
+}
+
+// ValidStampPositions lists the accepted StampPosition values
+var ValidStampPositions = []string{"bottom-right", "bottom-left", "bottom-center", "top-right", "top-left", "top-center"}
+
+// ValidPageSizes lists the accepted PageSize values
+var ValidPageSizes = []string{"auto", "A4", "A5", "Letter", "Legal"}
+
+// pageSizes are the fixed page sizes in points, portrait orientation
+var pageSizes = map[string]gofpdf.SizeType{
+	"a4":     {Wd: 595.28, Ht: 841.89},
+	"a5":     {Wd: 419.53, Ht: 595.28},
+	"letter": {Wd: 612, Ht: 792},
+	"legal":  {Wd: 612, Ht: 1008},
 }
 
 // DefaultPDFGenerator is the default implementation using gofpdf
@@ -32,6 +102,8 @@ func NewPDFGenerator() PDFGenerator {
 }
 
 // CreatePDF creates a PDF from a sequence of image files
+// Pages are not streamed: gofpdf holds the encoded images and the document
+// in memory until the file is written
 func (g *DefaultPDFGenerator) CreatePDF(imageFiles []string, outputPath string, options PDFOptions) error {
 	if len(imageFiles) == 0 {
 		return fmt.Errorf("no images provided")
@@ -44,7 +116,61 @@ func (g *DefaultPDFGenerator) CreatePDF(imageFiles []string, outputPath string,
 		}
 	}
 
-	// Create PDF without specifying page size (we'll set it per page)
+	if err := ValidateLayout(options); err != nil {
+		return err
+	}
+
+	// Identical pages (section dividers, blank pages) share one embedded image
+	imageFiles, err := dedupeImages(imageFiles)
+	if err != nil {
+		return err
+	}
+
+	pdf := newDocument(options)
+	if err := addPages(pdf, imageFiles, options); err != nil {
+		return err
+	}
+
+	// Output PDF
+	if err := pdf.OutputFileAndClose(outputPath); err != nil {
+		return fmt.Errorf("failed to create PDF: %w", err)
+	}
+
+	return nil
+}
+
+// CreatePDFFromImages creates a PDF from decoded pages
+// Each page is encoded in memory (PNG, or JPEG with JPEGQuality) and
+// registered under a page name, so the layout is the same as CreatePDF's.
+// Encoded images carry no DPI tag: set DPI for a predictable "auto" page size
+func (g *DefaultPDFGenerator) CreatePDFFromImages(images []image.Image, outputPath string, options PDFOptions) error {
+	if len(images) == 0 {
+		return fmt.Errorf("no images provided")
+	}
+
+	if err := ValidateLayout(options); err != nil {
+		return err
+	}
+
+	pdf := newDocument(options)
+	names, err := registerDecodedImages(pdf, images, options.JPEGQuality)
+	if err != nil {
+		return err
+	}
+	if err := addPages(pdf, names, options); err != nil {
+		return err
+	}
+
+	if err := pdf.OutputFileAndClose(outputPath); err != nil {
+		return fmt.Errorf("failed to create PDF: %w", err)
+	}
+
+	return nil
+}
+
+// newDocument creates an empty PDF with the compression and producer options
+// Pages are added without a default size; each page sets its own
+func newDocument(options PDFOptions) *gofpdf.Fpdf {
 	pdf := gofpdf.New("P", "pt", "", "")
 
 	// Set compression based on options
@@ -52,50 +178,267 @@ func (g *DefaultPDFGenerator) CreatePDF(imageFiles []string, outputPath string,
 		pdf.SetCompression(true)
 	}
 
+	if options.Producer != "" {
+		pdf.SetProducer(options.Producer, false)
+	}
+	return pdf
+}
+
+// addPages lays out the images as pages: contact sheets, n-up sheets or one
+// image per page. Images are image files, or names registered beforehand
+func addPages(pdf *gofpdf.Fpdf, imageFiles []string, options PDFOptions) error {
+	if options.ContactSheetColumns > 0 {
+		return addContactSheetPages(pdf, imageFiles, options)
+	}
+	if options.NUp > 1 {
+		return addNUpPages(pdf, imageFiles, options)
+	}
+
+	fixedSize, hasFixedSize := pageSizes[strings.ToLower(options.PageSize)]
+
 	// Add each image as a page
 	for _, imgPath := range imageFiles {
-		// Get image type from extension
-		ext := filepath.Ext(imgPath)
-		var imgType string
-		switch ext {
-		case ".jpg", ".jpeg":
-			imgType = "JPEG"
-		case ".png":
-			imgType = "PNG"
-		default:
-			return fmt.Errorf("unsupported image format: %s", ext)
+		opts, imgWidth, imgHeight, err := registerImage(pdf, imgPath, options.DPI)
+		if err != nil {
+			return err
+		}
+
+		if !hasFixedSize {
+			// Add page with image dimensions
+			pdf.AddPageFormat("P", gofpdf.SizeType{Wd: imgWidth, Ht: imgHeight})
+
+			// Add image to fill the page exactly
+			pdf.ImageOptions(imgPath, 0, 0, imgWidth, imgHeight, false, opts, 0, "")
+
+			if options.StampPageNumbers {
+				stampPageNumber(pdf, pdf.PageNo(), imgWidth, imgHeight, options)
+			}
+			continue
+		}
+
+		// Fixed page size: landscape images get landscape pages
+		// (gofpdf swaps width and height itself for "L")
+		page := fixedSize
+		orientation := "P"
+		if imgWidth > imgHeight {
+			page = gofpdf.SizeType{Wd: fixedSize.Ht, Ht: fixedSize.Wd}
+			orientation = "L"
 		}
+		pdf.AddPageFormat(orientation, fixedSize)
+		fillBackground(pdf, page, options.Background)
 
-		// Register image to get dimensions
-		opts := gofpdf.ImageOptions{
-			ImageType: imgType,
-			ReadDpi:   true,
+		x, y, w, h := fitImage(imgWidth, imgHeight, page, options.PageMargin)
+		pdf.ImageOptions(imgPath, x, y, w, h, false, opts, 0, "")
+
+		if options.StampPageNumbers {
+			stampPageNumber(pdf, pdf.PageNo(), page.Wd, page.Ht, options)
 		}
+	}
+	return nil
+}
 
-		info := pdf.RegisterImageOptions(imgPath, opts)
+// registerDecodedImages encodes every page and registers it with the PDF
+// under a name addPages can place ("page_0001.png" etc.). Like dedupeImages,
+// identical pages get the name of the first one, so they are embedded once
+func registerDecodedImages(pdf *gofpdf.Fpdf, images []image.Image, jpegQuality int) ([]string, error) {
+	imgType, ext := "PNG", ".png"
+	if jpegQuality > 0 {
+		imgType, ext = "JPEG", ".jpg"
+	}
+
+	firstByHash := make(map[[sha256.Size]byte]string)
+	names := make([]string, len(images))
+	var buf bytes.Buffer
+	for i, img := range images {
+		buf.Reset()
+		var err error
+		if jpegQuality > 0 {
+			err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality})
+		} else {
+			err = png.Encode(&buf, img)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode page %d: %w", i+1, err)
+		}
+
+		sum := sha256.Sum256(buf.Bytes())
+		if first, ok := firstByHash[sum]; ok {
+			names[i] = first
+			continue
+		}
+		names[i] = fmt.Sprintf("page_%04d%s", i+1, ext)
+		firstByHash[sum] = names[i]
+
+		pdf.RegisterImageOptionsReader(names[i], gofpdf.ImageOptions{ImageType: imgType}, &buf)
 		if pdf.Error() != nil {
-			return fmt.Errorf("failed to register image %s: %w", imgPath, pdf.Error())
+			return nil, fmt.Errorf("failed to register page %d: %w", i+1, pdf.Error())
+		}
+	}
+	return names, nil
+}
+
+// dedupeImages maps every image to the first file with identical content
+// gofpdf registers images by name, so reusing that name places the already
+// embedded image again instead of embedding the same bytes twice
+func dedupeImages(imageFiles []string) ([]string, error) {
+	firstByHash := make(map[[sha256.Size]byte]string)
+	names := make([]string, len(imageFiles))
+
+	for i, imgPath := range imageFiles {
+		file, err := os.Open(imgPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open image %s: %w", imgPath, err)
+		}
+		hash := sha256.New()
+		_, err = io.Copy(hash, file)
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read image %s: %w", imgPath, err)
 		}
 
-		// Get image dimensions in points
-		imgWidth := info.Width()
-		imgHeight := info.Height()
+		var sum [sha256.Size]byte
+		copy(sum[:], hash.Sum(nil))
+		if first, ok := firstByHash[sum]; ok {
+			names[i] = first
+		} else {
+			firstByHash[sum] = imgPath
+			names[i] = imgPath
+		}
+	}
 
-		// Add page with image dimensions
-		pdf.AddPageFormat("P", gofpdf.SizeType{Wd: imgWidth, Ht: imgHeight})
+	return names, nil
+}
 
-		// Add image to fill the page exactly
-		pdf.ImageOptions(imgPath, 0, 0, imgWidth, imgHeight, false, opts, 0, "")
+// ValidateLayout checks the page size, margin and n-up settings
+// Callers can use it to reject a layout before capturing any pages
+func ValidateLayout(options PDFOptions) error {
+	sizeName := strings.ToLower(options.PageSize)
+	size, hasFixedSize := pageSizes[sizeName]
+	if !hasFixedSize && sizeName != "" && sizeName != "auto" {
+		return fmt.Errorf("unsupported page size: %s", options.PageSize)
 	}
 
-	// Output PDF
-	if err := pdf.OutputFileAndClose(outputPath); err != nil {
-		return fmt.Errorf("failed to create PDF: %w", err)
+	if hasFixedSize && (options.PageMargin < 0 || 2*options.PageMargin >= size.Wd) {
+		return fmt.Errorf("page margin %.0fpt does not fit on %s pages", options.PageMargin, options.PageSize)
+	}
+
+	if options.ContactSheetColumns > 0 {
+		return validateContactSheet(options)
+	}
+
+	switch options.NUp {
+	case 0, 1:
+		return nil
+	case 2, 4:
+	default:
+		return fmt.Errorf("n-up must be 1, 2 or 4, got %d", options.NUp)
+	}
+
+	sheet, cols, rows := nUpGrid(options)
+	cellWidth, cellHeight := nUpCellSize(sheet, cols, rows, options.PageMargin)
+	if cellWidth < minNUpCellSize || cellHeight < minNUpCellSize {
+		return fmt.Errorf("%d-up cells would be %.0fx%.0fpt on %s pages (minimum %.0fpt); use a larger page size or smaller margin",
+			options.NUp, cellWidth, cellHeight, nUpSheetName(options), minNUpCellSize)
 	}
 
 	return nil
 }
 
+// registerImage registers an image with the PDF and returns its options and
+// dimensions in points
+func registerImage(pdf *gofpdf.Fpdf, imgPath string, dpi float64) (gofpdf.ImageOptions, float64, float64, error) {
+	// Get image type from extension
+	ext := filepath.Ext(imgPath)
+	var imgType string
+	switch ext {
+	case ".jpg", ".jpeg":
+		imgType = "JPEG"
+	case ".png":
+		imgType = "PNG"
+	default:
+		return gofpdf.ImageOptions{}, 0, 0, fmt.Errorf("unsupported image format: %s", ext)
+	}
+
+	// Register image to get dimensions
+	opts := gofpdf.ImageOptions{
+		ImageType: imgType,
+		ReadDpi:   dpi <= 0,
+	}
+
+	info := pdf.RegisterImageOptions(imgPath, opts)
+	if pdf.Error() != nil {
+		return opts, 0, 0, fmt.Errorf("failed to register image %s: %w", imgPath, pdf.Error())
+	}
+
+	// Screenshots carry no meaningful DPI tag; an explicit DPI makes the
+	// printed size predictable
+	if dpi > 0 {
+		info.SetDpi(dpi)
+	}
+
+	return opts, info.Width(), info.Height(), nil
+}
+
+// fillBackground paints the current page in bg (nil: left white)
+func fillBackground(pdf *gofpdf.Fpdf, page gofpdf.SizeType, bg color.Color) {
+	if bg == nil {
+		return
+	}
+	r, g, b, _ := bg.RGBA()
+	pdf.SetFillColor(int(r>>8), int(g>>8), int(b>>8))
+	pdf.Rect(0, 0, page.Wd, page.Ht, "F")
+}
+
+// fitImage scales an image to fit inside the page margins, keeping its aspect
+// ratio, and centers it. Returns the position and size in points
+func fitImage(imgWidth, imgHeight float64, page gofpdf.SizeType, margin float64) (x, y, w, h float64) {
+	availWidth := page.Wd - 2*margin
+	availHeight := page.Ht - 2*margin
+
+	scale := availWidth / imgWidth
+	if s := availHeight / imgHeight; s < scale {
+		scale = s
+	}
+
+	w = imgWidth * scale
+	h = imgHeight * scale
+	x = (page.Wd - w) / 2
+	y = (page.Ht - h) / 2
+	return x, y, w, h
+}
+
+// stampPageNumber draws the page number in the configured corner of the page
+func stampPageNumber(pdf *gofpdf.Fpdf, pageNum int, pageWidth, pageHeight float64, options PDFOptions) {
+	fontSize := options.StampFontSize
+	if fontSize <= 0 {
+		fontSize = 10
+	}
+	pdf.SetFont("Helvetica", "", fontSize)
+	pdf.SetTextColor(0, 0, 0)
+
+	text := fmt.Sprintf("%d", pageNum)
+	textWidth := pdf.GetStringWidth(text)
+	margin := fontSize
+
+	var x, y float64
+	switch options.StampPosition {
+	case "bottom-left", "top-left":
+		x = margin
+	case "bottom-center", "top-center":
+		x = (pageWidth - textWidth) / 2
+	default:
+		x = pageWidth - margin - textWidth
+	}
+	switch options.StampPosition {
+	case "top-right", "top-left", "top-center":
+		y = margin + fontSize // Text() positions the baseline
+	default:
+		y = pageHeight - margin
+	}
+
+	pdf.Text(x, y, text)
+}
+
 // GetQualitySettings returns compression settings based on quality level
 func GetQualitySettings(quality string) PDFOptions {
 	switch quality {
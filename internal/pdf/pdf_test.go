@@ -1,8 +1,18 @@
 package pdf
 
 import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/jung-kurt/gofpdf"
 )
 
 func TestCreatePDF(t *testing.T) {
@@ -36,6 +46,358 @@ func TestCreatePDF(t *testing.T) {
 	})
 }
 
+func TestCreatePDFProducer(t *testing.T) {
+	tmpDir := t.TempDir()
+	imgPath := filepath.Join(tmpDir, "page.png")
+	if err := createDummyImage(imgPath, 20, 20, "png"); err != nil {
+		t.Fatalf("failed to create test image: %v", err)
+	}
+
+	outputPath := filepath.Join(tmpDir, "out.pdf")
+	opts := PDFOptions{Quality: "high", Producer: "k2p v1.2.3 (abc1234)"}
+	if err := NewPDFGenerator().CreatePDF([]string{imgPath}, outputPath, opts); err != nil {
+		t.Fatalf("CreatePDF failed: %v", err)
+	}
+
+	data, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("failed to read PDF: %v", err)
+	}
+	// Parentheses are escaped inside PDF strings
+	if !strings.Contains(string(data), `/Producer (k2p v1.2.3 \(abc1234\))`) {
+		t.Error("Producer metadata not found in PDF")
+	}
+}
+
+func TestCreatePDFStampPageNumbers(t *testing.T) {
+	tmpDir := t.TempDir()
+	var images []string
+	for i := 0; i < 2; i++ {
+		imgPath := filepath.Join(tmpDir, fmt.Sprintf("page_%d.png", i))
+		if err := createDummyImage(imgPath, 200, 300, "png"); err != nil {
+			t.Fatalf("failed to create test image: %v", err)
+		}
+		images = append(images, imgPath)
+	}
+
+	for _, stamp := range []bool{false, true} {
+		outputPath := filepath.Join(tmpDir, fmt.Sprintf("out_%v.pdf", stamp))
+		opts := PDFOptions{Quality: "high", StampPageNumbers: stamp, StampPosition: "top-left"}
+		if err := NewPDFGenerator().CreatePDF(images, outputPath, opts); err != nil {
+			t.Fatalf("CreatePDF failed: %v", err)
+		}
+
+		data, err := os.ReadFile(outputPath)
+		if err != nil {
+			t.Fatalf("failed to read PDF: %v", err)
+		}
+		// Page streams are compressed; the stamp font is only embedded when used
+		hasStamp := strings.Contains(string(data), "/BaseFont /Helvetica")
+		if hasStamp != stamp {
+			t.Errorf("StampPageNumbers=%v: page number text present=%v", stamp, hasStamp)
+		}
+	}
+}
+
+func TestCreatePDFDeduplicatesIdenticalPages(t *testing.T) {
+	tmpDir := t.TempDir()
+	var images []string
+	for i, size := range []int{100, 100, 120, 100} {
+		imgPath := filepath.Join(tmpDir, fmt.Sprintf("page_%d.png", i))
+		if err := createDummyImage(imgPath, size, size, "png"); err != nil {
+			t.Fatalf("failed to create test image: %v", err)
+		}
+		images = append(images, imgPath)
+	}
+
+	outputPath := filepath.Join(tmpDir, "out.pdf")
+	if err := NewPDFGenerator().CreatePDF(images, outputPath, PDFOptions{Quality: "high"}); err != nil {
+		t.Fatalf("CreatePDF failed: %v", err)
+	}
+
+	data, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("failed to read PDF: %v", err)
+	}
+	// Four pages, but only two distinct images are embedded
+	if got := strings.Count(string(data), "/Subtype /Image"); got != 2 {
+		t.Errorf("expected 2 embedded images, got %d", got)
+	}
+	if got := strings.Count(string(data), "/Type /Page\n"); got != 4 {
+		t.Errorf("expected 4 pages, got %d", got)
+	}
+}
+
+// Decoded pages get the same layout and deduplication as image files
+func TestCreatePDFFromImages(t *testing.T) {
+	page := func(w, h int) image.Image {
+		img := image.NewRGBA(image.Rect(0, 0, w, h))
+		for i := range img.Pix {
+			img.Pix[i] = uint8(i % 251)
+			if i%4 == 3 {
+				img.Pix[i] = 255
+			}
+		}
+		return img
+	}
+	images := []image.Image{page(100, 150), page(100, 150), page(120, 80)}
+
+	for _, jpegQuality := range []int{0, 80} {
+		outputPath := filepath.Join(t.TempDir(), "out.pdf")
+		options := PDFOptions{Quality: "high", DPI: 72, JPEGQuality: jpegQuality}
+		if err := NewPDFGenerator().CreatePDFFromImages(images, outputPath, options); err != nil {
+			t.Fatalf("JPEGQuality=%d: CreatePDFFromImages failed: %v", jpegQuality, err)
+		}
+
+		data, err := os.ReadFile(outputPath)
+		if err != nil {
+			t.Fatalf("failed to read PDF: %v", err)
+		}
+		if got := strings.Count(string(data), "/Type /Page\n"); got != 3 {
+			t.Errorf("JPEGQuality=%d: expected 3 pages, got %d", jpegQuality, got)
+		}
+		if got := strings.Count(string(data), "/Subtype /Image"); got != 2 {
+			t.Errorf("JPEGQuality=%d: expected 2 embedded images, got %d", jpegQuality, got)
+		}
+		if box := "/MediaBox [0 0 120.00 80.00]"; !strings.Contains(string(data), box) {
+			t.Errorf("JPEGQuality=%d: expected page with %s", jpegQuality, box)
+		}
+		if filter := strings.Contains(string(data), "/DCTDecode"); filter != (jpegQuality > 0) {
+			t.Errorf("JPEGQuality=%d: unexpected JPEG encoding %v", jpegQuality, filter)
+		}
+	}
+
+	if err := NewPDFGenerator().CreatePDFFromImages(nil, filepath.Join(t.TempDir(), "empty.pdf"), PDFOptions{}); err == nil {
+		t.Error("expected error for no images")
+	}
+}
+
+func TestCreatePDFPageSize(t *testing.T) {
+	tmpDir := t.TempDir()
+	portrait := filepath.Join(tmpDir, "portrait.png")
+	landscape := filepath.Join(tmpDir, "landscape.png")
+	if err := createDummyImage(portrait, 200, 300, "png"); err != nil {
+		t.Fatalf("failed to create test image: %v", err)
+	}
+	if err := createDummyImage(landscape, 300, 200, "png"); err != nil {
+		t.Fatalf("failed to create test image: %v", err)
+	}
+
+	outputPath := filepath.Join(tmpDir, "a4.pdf")
+	opts := PDFOptions{Quality: "high", PageSize: "A4", PageMargin: 36}
+	if err := NewPDFGenerator().CreatePDF([]string{portrait, landscape}, outputPath, opts); err != nil {
+		t.Fatalf("CreatePDF failed: %v", err)
+	}
+
+	data, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("failed to read PDF: %v", err)
+	}
+	for _, box := range []string{"/MediaBox [0 0 595.28 841.89]", "/MediaBox [0 0 841.89 595.28]"} {
+		if !strings.Contains(string(data), box) {
+			t.Errorf("expected page with %s", box)
+		}
+	}
+
+	t.Run("unknown page size", func(t *testing.T) {
+		opts := PDFOptions{PageSize: "B5"}
+		if err := NewPDFGenerator().CreatePDF([]string{portrait}, outputPath, opts); err == nil {
+			t.Error("expected error for unknown page size")
+		}
+	})
+
+	t.Run("margin too large", func(t *testing.T) {
+		opts := PDFOptions{PageSize: "letter", PageMargin: 306}
+		if err := NewPDFGenerator().CreatePDF([]string{portrait}, outputPath, opts); err == nil {
+			t.Error("expected error for margin wider than the page")
+		}
+	})
+}
+
+func TestCreatePDFBackground(t *testing.T) {
+	tmpDir := t.TempDir()
+	imgPath := filepath.Join(tmpDir, "page.png")
+	if err := createDummyImage(imgPath, 200, 300, "png"); err != nil {
+		t.Fatalf("failed to create test image: %v", err)
+	}
+
+	for _, tt := range []struct {
+		name string
+		opts PDFOptions
+		fill bool
+	}{
+		{"fixed size", PDFOptions{PageSize: "A4", Background: color.Black}, true},
+		{"n-up", PDFOptions{PageSize: "A4", NUp: 2, Background: color.Black}, true},
+		{"auto size has no letterbox", PDFOptions{Background: color.Black}, false},
+		{"default white", PDFOptions{PageSize: "A4"}, false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			outputPath := filepath.Join(tmpDir, "bg.pdf")
+			if err := NewPDFGenerator().CreatePDF([]string{imgPath}, outputPath, tt.opts); err != nil {
+				t.Fatalf("CreatePDF failed: %v", err)
+			}
+			data, err := os.ReadFile(outputPath)
+			if err != nil {
+				t.Fatalf("failed to read PDF: %v", err)
+			}
+			if got := strings.Contains(inflateStreams(data), " re f"); got != tt.fill {
+				t.Errorf("expected filled background %v, got %v", tt.fill, got)
+			}
+		})
+	}
+}
+
+// inflateStreams returns the decompressed content of every stream in a PDF
+// Image streams decode to binary noise, which is harmless for substring checks
+func inflateStreams(data []byte) string {
+	var out strings.Builder
+	for _, part := range bytes.Split(data, []byte("stream\n"))[1:] {
+		r, err := zlib.NewReader(bytes.NewReader(part))
+		if err != nil {
+			continue
+		}
+		content, _ := io.ReadAll(r)
+		out.Write(content)
+	}
+	return out.String()
+}
+
+func TestCreatePDFDPI(t *testing.T) {
+	tmpDir := t.TempDir()
+	imgPath := filepath.Join(tmpDir, "page.png")
+	if err := createDummyImage(imgPath, 288, 432, "png"); err != nil {
+		t.Fatalf("failed to create test image: %v", err)
+	}
+
+	// 288x432 pixels at 144 DPI is 2x3 inches, i.e. 144x216 points
+	outputPath := filepath.Join(tmpDir, "dpi.pdf")
+	if err := NewPDFGenerator().CreatePDF([]string{imgPath}, outputPath, PDFOptions{DPI: 144}); err != nil {
+		t.Fatalf("CreatePDF failed: %v", err)
+	}
+	data, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("failed to read PDF: %v", err)
+	}
+	if box := "/MediaBox [0 0 144.00 216.00]"; !strings.Contains(string(data), box) {
+		t.Errorf("expected page with %s", box)
+	}
+}
+
+func TestCreatePDFNUp(t *testing.T) {
+	tmpDir := t.TempDir()
+	var images []string
+	for i := 0; i < 3; i++ {
+		imgPath := filepath.Join(tmpDir, fmt.Sprintf("page_%d.png", i))
+		if err := createDummyImage(imgPath, 200, 300, "png"); err != nil {
+			t.Fatalf("failed to create test image: %v", err)
+		}
+		images = append(images, imgPath)
+	}
+
+	outputPath := filepath.Join(tmpDir, "2up.pdf")
+	opts := PDFOptions{Quality: "high", PageSize: "A4", NUp: 2}
+	if err := NewPDFGenerator().CreatePDF(images, outputPath, opts); err != nil {
+		t.Fatalf("CreatePDF failed: %v", err)
+	}
+
+	data, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("failed to read PDF: %v", err)
+	}
+	// Three pages on landscape sheets: two full, the last with one empty cell
+	if got := strings.Count(string(data), "/MediaBox [0 0 841.89 595.28]"); got != 2 {
+		t.Errorf("expected 2 landscape sheets, got %d", got)
+	}
+}
+
+func TestCreatePDFContactSheet(t *testing.T) {
+	tmpDir := t.TempDir()
+	var images []string
+	for i := 0; i < 30; i++ {
+		imgPath := filepath.Join(tmpDir, fmt.Sprintf("page_%d.png", i))
+		if err := createDummyImage(imgPath, 200, 300, "png"); err != nil {
+			t.Fatalf("failed to create test image: %v", err)
+		}
+		images = append(images, imgPath)
+	}
+
+	outputPath := filepath.Join(tmpDir, "sheet.pdf")
+	opts := PDFOptions{Quality: "high", ContactSheetColumns: 4, PageLabels: []string{"p. 1", "p. 4"}}
+	if err := NewPDFGenerator().CreatePDF(images, outputPath, opts); err != nil {
+		t.Fatalf("CreatePDF failed: %v", err)
+	}
+
+	data, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("failed to read PDF: %v", err)
+	}
+	// 4 columns of 2:3 cells leave room for 3 rows on A4: 12 per sheet
+	// (A4 is the document default, so the sheets carry no MediaBox of their own)
+	if got := strings.Count(string(data), "/Type /Page\n"); got != 3 {
+		t.Errorf("expected 3 sheets, got %d", got)
+	}
+	// Captions fall back to the image position
+	if got := contactSheetLabel(opts, 1); got != "p. 4" {
+		t.Errorf("expected the given label, got %q", got)
+	}
+	if got := contactSheetLabel(opts, 29); got != "30" {
+		t.Errorf("expected the position as label, got %q", got)
+	}
+}
+
+func TestValidateLayout(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    PDFOptions
+		wantErr bool
+	}{
+		{"defaults", PDFOptions{}, false},
+		{"fixed page size", PDFOptions{PageSize: "Letter", PageMargin: 36}, false},
+		{"unknown page size", PDFOptions{PageSize: "B5"}, true},
+		{"margin wider than page", PDFOptions{PageSize: "A5", PageMargin: 300}, true},
+		{"2-up on auto uses A4", PDFOptions{NUp: 2}, false},
+		{"4-up on A4", PDFOptions{PageSize: "A4", NUp: 4, PageMargin: 36}, false},
+		{"unsupported n-up", PDFOptions{PageSize: "A4", NUp: 3}, true},
+		{"4-up cells too small", PDFOptions{PageSize: "A5", NUp: 4, PageMargin: 100}, true},
+		{"contact sheet", PDFOptions{ContactSheetColumns: 6, NUp: 3}, false},
+		{"contact sheet columns too narrow", PDFOptions{PageSize: "A5", ContactSheetColumns: 12}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateLayout(tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateLayout() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFitImage(t *testing.T) {
+	page := gofpdf.SizeType{Wd: 600, Ht: 800}
+	tests := []struct {
+		name                       string
+		imgWidth, imgHeight        float64
+		margin                     float64
+		wantX, wantY, wantW, wantH float64
+	}{
+		{"width limited", 300, 300, 0, 0, 100, 600, 600},
+		{"height limited", 100, 400, 0, 200, 0, 200, 800},
+		{"with margin", 300, 300, 50, 50, 150, 500, 500},
+		{"upscaled", 60, 80, 0, 0, 0, 600, 800},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			x, y, w, h := fitImage(tt.imgWidth, tt.imgHeight, page, tt.margin)
+			if x != tt.wantX || y != tt.wantY || w != tt.wantW || h != tt.wantH {
+				t.Errorf("fitImage() = (%v, %v, %v, %v), want (%v, %v, %v, %v)",
+					x, y, w, h, tt.wantX, tt.wantY, tt.wantW, tt.wantH)
+			}
+		})
+	}
+}
+
 func TestGetQualitySettings(t *testing.T) {
 	tests := []struct {
 		quality      string
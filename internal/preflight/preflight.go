@@ -0,0 +1,93 @@
+package preflight
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/oumi/k2p/internal/hint"
+	"github.com/oumi/k2p/internal/imageprocessing"
+)
+
+// Permission errors; both carry a hint naming the System Settings pane to open
+var (
+	ErrScreenRecordingDenied = hint.New(
+		"Screen Recording permission appears to be missing (test capture was completely black)",
+		"Open System Settings → Privacy & Security → Screen Recording, enable k2p (or your terminal), then restart k2p",
+	)
+	ErrAccessibilityDenied = hint.New(
+		"Accessibility permission appears to be missing (System Events UI scripting is disabled)",
+		"Open System Settings → Privacy & Security → Accessibility, enable k2p (or your terminal), then restart k2p",
+	)
+)
+
+// PermissionChecker verifies the macOS permissions k2p needs before a run
+type PermissionChecker interface {
+	// CheckScreenRecording captures a test frame and fails if it is entirely black
+	CheckScreenRecording() error
+
+	// CheckAccessibility fails if keystrokes cannot be sent through System Events
+	CheckAccessibility() error
+}
+
+// MacOSPermissionChecker implements PermissionChecker with screencapture and osascript
+type MacOSPermissionChecker struct{}
+
+// NewPermissionChecker creates a new PermissionChecker instance
+func NewPermissionChecker() PermissionChecker {
+	return &MacOSPermissionChecker{}
+}
+
+// CheckScreenRecording captures a test frame and fails if it is entirely black
+// Without Screen Recording permission, screencapture succeeds but returns a black image
+func (c *MacOSPermissionChecker) CheckScreenRecording() error {
+	tmpDir, err := os.MkdirTemp("", "k2p-preflight-")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	framePath := filepath.Join(tmpDir, "preflight.png")
+	if output, err := exec.Command("screencapture", "-x", "-t", "png", framePath).CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: screencapture failed: %v (output: %s)", ErrScreenRecordingDenied, err, output)
+	}
+
+	info, err := imageprocessing.DetectBlankFile(framePath)
+	if err != nil {
+		return fmt.Errorf("failed to inspect test capture: %w", err)
+	}
+	if info.Black {
+		return ErrScreenRecordingDenied
+	}
+
+	return nil
+}
+
+// CheckAccessibility fails if keystrokes cannot be sent through System Events
+// "UI elements enabled" is false until Accessibility access is granted
+func (c *MacOSPermissionChecker) CheckAccessibility() error {
+	script := `tell application "System Events" to return UI elements enabled`
+	output, err := exec.Command("osascript", "-e", script).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%w: %v (output: %s)", ErrAccessibilityDenied, err, strings.TrimSpace(string(output)))
+	}
+	if strings.TrimSpace(string(output)) != "true" {
+		return ErrAccessibilityDenied
+	}
+
+	return nil
+}
+
+// Run performs all permission checks and returns every problem found
+func Run(checker PermissionChecker) []error {
+	var problems []error
+	if err := checker.CheckScreenRecording(); err != nil {
+		problems = append(problems, err)
+	}
+	if err := checker.CheckAccessibility(); err != nil {
+		problems = append(problems, err)
+	}
+	return problems
+}
@@ -0,0 +1,37 @@
+package screenshot
+
+import (
+	"fmt"
+	"image"
+	"os"
+)
+
+// CaptureCurrentPage captures the current Kindle page and returns it decoded
+// The capture goes through a temporary PNG file that is removed before returning,
+// so callers can process pages without managing files. Kindle must already be
+// in the foreground, as with CaptureWithoutActivation
+func CaptureCurrentPage(c Capturer) (image.Image, error) {
+	tmpFile, err := os.CreateTemp("", "k2p-page-*.png")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	tmpPath := tmpFile.Name()
+	tmpFile.Close()
+	defer os.Remove(tmpPath)
+
+	if err := c.CaptureWithoutActivation(tmpPath); err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(tmpPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open capture: %w", err)
+	}
+	defer file.Close()
+
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode capture: %w", err)
+	}
+	return img, nil
+}
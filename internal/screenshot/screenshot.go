@@ -2,10 +2,20 @@ package screenshot
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"image"
+	"image/jpeg"
+	_ "image/png"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 	"time"
+
+	"github.com/oumi/k2p/internal/automation"
+	_ "golang.org/x/image/bmp"
 )
 
 // Capturer handles screenshot capture operations
@@ -17,93 +27,256 @@ type Capturer interface {
 	// CaptureWithoutActivation captures a screenshot without activating Kindle
 	// Returns error if Kindle is not already in the foreground
 	CaptureWithoutActivation(outputPath string) error
+
+	// Configure applies capture settings for the current conversion
+	Configure(options CaptureOptions)
 }
 
+// CaptureOptions contains settings applied to every capture
+type CaptureOptions struct {
+	// Screenshot quality (1-100)
+	// Quality 100 captures lossless PNG; lower values are applied as JPEG quality
+	// to output paths with a .jpg/.jpeg extension
+	Quality int
+
+	// Maximum time to wait for Kindle to become frontmost after activation
+	ActivationTimeout time.Duration
+
+	// Activations tried before CaptureFrontmostWindow fails (default: 1)
+	ActivationAttempts int
+
+	// Extra wait after Kindle becomes frontmost (default: 0)
+	// Fullscreen apps live in their own Space and on some machines the switch
+	// animation is still running when the frontmost flag flips
+	SpaceSwitchSettle time.Duration
+
+	// Format screencapture writes: "png" (default) or "bmp"
+	// BMP is uncompressed and much faster to write on large displays; JPEG
+	// pages are encoded from it and BMP pages are stored as PNG before output
+	Format string
+
+	// Maximum run time of a single osascript/screencapture call
+	// A call blocked on a permission dialog fails instead of hanging the run
+	CommandTimeout time.Duration
+
+	// Reader app that must be frontmost (zero: Kindle)
+	Target automation.Target
+
+	// Foreground cache and script log of the run, shared with the automation
+	// (nil: no cache, no log)
+	Session *automation.Session
+}
+
+// DefaultCaptureOptions returns the default capture settings
+func DefaultCaptureOptions() CaptureOptions {
+	return CaptureOptions{
+		Quality:            100,
+		Format:             FormatPNG,
+		ActivationTimeout:  5 * time.Second,
+		ActivationAttempts: 1,
+		CommandTimeout:     10 * time.Second,
+	}
+}
+
+// Capture formats written by screencapture
+const (
+	FormatPNG = "png"
+	FormatBMP = "bmp"
+)
+
+// activationRetryBackoff is the pause before the second activation attempt;
+// it doubles for every further attempt
+const activationRetryBackoff = 1 * time.Second
+
 // MacOSCapturer implements screenshot capture for macOS
-type MacOSCapturer struct{}
+type MacOSCapturer struct {
+	options CaptureOptions
+
+	// frontmost checks focus before a capture; nil uses the session's IsAppFrontmost
+	frontmost func(target automation.Target, timeout time.Duration) (bool, error)
+}
 
 // NewCapturer creates a new screenshot capturer
 func NewCapturer() Capturer {
-	return &MacOSCapturer{}
+	return &MacOSCapturer{options: DefaultCaptureOptions()}
 }
 
-// CaptureFrontmostWindow captures a screenshot of the Kindle window
-// Since Kindle should be in fullscreen mode, we activate it and capture the frontmost window
-func (c *MacOSCapturer) CaptureFrontmostWindow(outputPath string) error {
-	// Activate Kindle to bring it to front
-	// Note: Application name is "Amazon Kindle" but process name is "Kindle"
-	activateScript := `
-tell application "Amazon Kindle"
-	activate
-end tell
-`
-	activateCmd := exec.Command("osascript", "-e", activateScript)
-	var activateStderr bytes.Buffer
-	activateCmd.Stderr = &activateStderr
-	if err := activateCmd.Run(); err != nil {
-		return fmt.Errorf("failed to activate Kindle: %w, stderr: %s", err, activateStderr.String())
-	}
-
-	// Wait longer for Kindle to come to front and for Space to switch
-	// Fullscreen apps are in separate Spaces, so we need time for the switch
-	time.Sleep(2 * time.Second)
-
-	// Verify Kindle is in foreground
-	checkScript := `
-tell application "System Events"
-	set frontApp to name of first application process whose frontmost is true
-	return frontApp is "Kindle"
-end tell
-`
-	checkCmd := exec.Command("osascript", "-e", checkScript)
-	output, err := checkCmd.Output()
-	if err != nil {
-		return fmt.Errorf("failed to verify Kindle is frontmost: %w", err)
+// FileExtension returns the screenshot file extension for a quality setting
+// Quality 100 keeps the lossless capture format (PNG or BMP), anything lower
+// is stored as JPEG
+func FileExtension(quality int, format string) string {
+	if quality > 0 && quality < 100 {
+		return ".jpg"
 	}
+	if format == FormatBMP {
+		return ".bmp"
+	}
+	return ".png"
+}
 
-	if strings.TrimSpace(string(output)) != "true" {
-		return fmt.Errorf("Kindle is not in foreground after activation")
+// Configure applies capture settings for the current conversion
+// Zero values keep the defaults
+func (c *MacOSCapturer) Configure(options CaptureOptions) {
+	defaults := DefaultCaptureOptions()
+	if options.Quality == 0 {
+		options.Quality = defaults.Quality
 	}
+	if options.Format == "" {
+		options.Format = defaults.Format
+	}
+	if options.ActivationTimeout <= 0 {
+		options.ActivationTimeout = defaults.ActivationTimeout
+	}
+	if options.ActivationAttempts <= 0 {
+		options.ActivationAttempts = defaults.ActivationAttempts
+	}
+	if options.CommandTimeout <= 0 {
+		options.CommandTimeout = defaults.CommandTimeout
+	}
+	c.options = options
+}
 
-	// Capture entire screen
-	// With screen recording permission, this captures the active Space (Kindle fullscreen)
-	// -x: disable sound
-	captureCmd := exec.Command("screencapture", "-x", outputPath)
-	if err := captureCmd.Run(); err != nil {
-		return fmt.Errorf("failed to capture screenshot: %w", err)
+// CaptureFrontmostWindow captures a screenshot of the Kindle window
+// Since Kindle should be in fullscreen mode, we activate it and capture the frontmost window
+func (c *MacOSCapturer) CaptureFrontmostWindow(outputPath string) error {
+	if err := c.activate(); err != nil {
+		return err
 	}
+	return c.captureScreen(outputPath)
+}
 
-	return nil
+// activate brings Kindle to front
+// Fullscreen apps are in separate Spaces, so the switch can take a while on
+// slow machines: Kindle is polled instead of waited for a fixed time, checked
+// again after SpaceSwitchSettle, and activated again with backoff up to
+// ActivationAttempts times
+func (c *MacOSCapturer) activate() error {
+	backoff := activationRetryBackoff
+	for attempt := 1; ; attempt++ {
+		err := c.options.Session.ActivateApp(c.options.Target, c.options.ActivationTimeout, c.options.CommandTimeout)
+		if err == nil {
+			time.Sleep(c.options.SpaceSwitchSettle)
+			var frontmost bool
+			if frontmost, err = c.options.Session.IsAppFrontmost(c.options.Target, c.options.CommandTimeout); err == nil && !frontmost {
+				err = fmt.Errorf("%w after the Space switch", automation.ErrKindleNotForeground)
+			}
+		}
+		if err == nil {
+			return nil
+		}
+		if attempt >= c.options.ActivationAttempts {
+			if attempt > 1 {
+				return fmt.Errorf("%w (after %d activation attempts)", err, attempt)
+			}
+			return err
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
 }
 
 // CaptureWithoutActivation captures a screenshot without activating Kindle
 // This is much faster than CaptureFrontmostWindow as it skips activation and waiting
-// Returns error if Kindle is not in the foreground
+// Returns an error wrapping automation.ErrKindleNotForeground if Kindle is not
+// in the foreground
 func (c *MacOSCapturer) CaptureWithoutActivation(outputPath string) error {
 	// Verify Kindle is in foreground (fail fast if not)
-	checkScript := `
-tell application "System Events"
-	set frontApp to name of first application process whose frontmost is true
-	return frontApp is "Kindle"
-end tell
-`
-	checkCmd := exec.Command("osascript", "-e", checkScript)
-	output, err := checkCmd.Output()
+	isFrontmost := c.frontmost
+	if isFrontmost == nil {
+		isFrontmost = c.options.Session.IsAppFrontmost
+	}
+	frontmost, err := isFrontmost(c.options.Target, c.options.CommandTimeout)
 	if err != nil {
-		return fmt.Errorf("failed to verify Kindle is frontmost: %w", err)
+		return err
+	}
+	if !frontmost {
+		name := c.options.Target.Process
+		if name == "" {
+			name = automation.KindleTarget.Process
+		}
+		return fmt.Errorf("%w: %s lost focus. Please keep %s active during conversion", automation.ErrKindleNotForeground, name, name)
 	}
 
-	if strings.TrimSpace(string(output)) != "true" {
-		return fmt.Errorf("Kindle is not in foreground. Please keep Kindle active during conversion")
+	return c.captureScreen(outputPath)
+}
+
+// captureScreen captures the entire screen to outputPath
+// With screen recording permission, this captures the active Space (Kindle fullscreen)
+// JPEG output paths are re-encoded at the configured quality
+func (c *MacOSCapturer) captureScreen(outputPath string) error {
+	ext := strings.ToLower(filepath.Ext(outputPath))
+	if ext != ".jpg" && ext != ".jpeg" {
+		// -x: disable sound; -t follows the extension (.png or .bmp)
+		format := FormatPNG
+		if ext == ".bmp" {
+			format = FormatBMP
+		}
+		if _, err := runCommand(c.options.CommandTimeout, "screencapture", "-x", "-t", format, outputPath); err != nil {
+			return fmt.Errorf("failed to capture screenshot: %w", err)
+		}
+		return nil
 	}
 
-	// Capture entire screen
-	// With screen recording permission, this captures the active Space (Kindle fullscreen)
-	// -x: disable sound
-	captureCmd := exec.Command("screencapture", "-x", outputPath)
-	if err := captureCmd.Run(); err != nil {
+	// screencapture has no quality flag, so capture lossless and encode ourselves
+	rawPath := outputPath + ".raw." + c.options.Format
+	defer os.Remove(rawPath)
+
+	if _, err := runCommand(c.options.CommandTimeout, "screencapture", "-x", "-t", c.options.Format, rawPath); err != nil {
 		return fmt.Errorf("failed to capture screenshot: %w", err)
 	}
 
+	return convertToJPEG(rawPath, outputPath, c.options.Quality)
+}
+
+// runCommand runs an external command and returns its stdout
+// The process is killed if it is still running after timeout, so the caller's
+// retry logic sees an error instead of waiting forever
+func runCommand(timeout time.Duration, name string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, name, args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return "", fmt.Errorf("%s timeout after %s", name, timeout)
+	}
+	if err != nil {
+		return "", fmt.Errorf("%w, stderr: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	return stdout.String(), nil
+}
+
+// convertToJPEG re-encodes a PNG or BMP capture as JPEG at the given quality (1-100)
+func convertToJPEG(inputPath, outputPath string, quality int) error {
+	in, err := os.Open(inputPath)
+	if err != nil {
+		return fmt.Errorf("failed to open screenshot: %w", err)
+	}
+	defer in.Close()
+
+	img, _, err := image.Decode(in)
+	if err != nil {
+		return fmt.Errorf("failed to decode screenshot: %w", err)
+	}
+
+	if quality < 1 || quality > 100 {
+		quality = jpeg.DefaultQuality
+	}
+
+	out, err := os.Create(outputPath)
+	if err != nil {
+		return fmt.Errorf("failed to create screenshot file: %w", err)
+	}
+	defer out.Close()
+
+	if err := jpeg.Encode(out, img, &jpeg.Options{Quality: quality}); err != nil {
+		return fmt.Errorf("failed to encode screenshot: %w", err)
+	}
+
 	return nil
 }
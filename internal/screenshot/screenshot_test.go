@@ -0,0 +1,120 @@
+package screenshot
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/oumi/k2p/internal/automation"
+	"github.com/oumi/k2p/internal/hint"
+)
+
+func TestFileExtension(t *testing.T) {
+	tests := []struct {
+		quality int
+		format  string
+		want    string
+	}{
+		{100, "", ".png"},
+		{0, "", ".png"},
+		{95, "", ".jpg"},
+		{1, "", ".jpg"},
+		{100, FormatBMP, ".bmp"},
+		{95, FormatBMP, ".jpg"},
+	}
+
+	for _, tt := range tests {
+		if got := FileExtension(tt.quality, tt.format); got != tt.want {
+			t.Errorf("FileExtension(%d, %q) = %s, want %s", tt.quality, tt.format, got, tt.want)
+		}
+	}
+}
+
+func TestConvertToJPEG(t *testing.T) {
+	tmpDir := t.TempDir()
+	pngPath := filepath.Join(tmpDir, "capture.png")
+
+	// Gradient with noise so JPEG quality visibly affects file size
+	img := image.NewRGBA(image.Rect(0, 0, 200, 200))
+	for y := 0; y < 200; y++ {
+		for x := 0; x < 200; x++ {
+			img.Set(x, y, color.RGBA{uint8(x), uint8(y), uint8((x * y) % 256), 255})
+		}
+	}
+	f, err := os.Create(pngPath)
+	if err != nil {
+		t.Fatalf("failed to create test image: %v", err)
+	}
+	if err := png.Encode(f, img); err != nil {
+		t.Fatalf("failed to encode test image: %v", err)
+	}
+	f.Close()
+
+	highPath := filepath.Join(tmpDir, "high.jpg")
+	lowPath := filepath.Join(tmpDir, "low.jpg")
+	if err := convertToJPEG(pngPath, highPath, 95); err != nil {
+		t.Fatalf("convertToJPEG(95) failed: %v", err)
+	}
+	if err := convertToJPEG(pngPath, lowPath, 10); err != nil {
+		t.Fatalf("convertToJPEG(10) failed: %v", err)
+	}
+
+	// Output must actually be JPEG
+	out, err := os.Open(lowPath)
+	if err != nil {
+		t.Fatalf("failed to open output: %v", err)
+	}
+	defer out.Close()
+	if _, format, err := image.DecodeConfig(out); err != nil || format != "jpeg" {
+		t.Errorf("expected jpeg output, got format=%q err=%v", format, err)
+	}
+
+	highInfo, _ := os.Stat(highPath)
+	lowInfo, _ := os.Stat(lowPath)
+	if lowInfo.Size() >= highInfo.Size() {
+		t.Errorf("expected lower quality to produce a smaller file: q10=%d bytes, q95=%d bytes",
+			lowInfo.Size(), highInfo.Size())
+	}
+}
+
+func TestRunCommandTimeout(t *testing.T) {
+	start := time.Now()
+	_, err := runCommand(100*time.Millisecond, "sleep", "5")
+	if err == nil || !strings.Contains(err.Error(), "timeout") {
+		t.Fatalf("expected a timeout error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("hung command was not killed (took %s)", elapsed)
+	}
+
+	out, err := runCommand(time.Second, "echo", "hello")
+	if err != nil || out != "hello\n" {
+		t.Errorf("expected %q, got %q (%v)", "hello\n", out, err)
+	}
+}
+
+// Losing focus mid-run is reported as the Kindle state error, not captured
+func TestCaptureWithoutActivationNotForeground(t *testing.T) {
+	c := &MacOSCapturer{
+		options:   DefaultCaptureOptions(),
+		frontmost: func(automation.Target, time.Duration) (bool, error) { return false, nil },
+	}
+	path := filepath.Join(t.TempDir(), "page.png")
+
+	err := c.CaptureWithoutActivation(path)
+	if !errors.Is(err, automation.ErrKindleNotForeground) {
+		t.Fatalf("expected ErrKindleNotForeground, got %v", err)
+	}
+	if got := hint.Of(err); !strings.Contains(got, "Click the Kindle window") {
+		t.Errorf("expected the foreground hint, got %q", got)
+	}
+	if _, serr := os.Stat(path); !os.IsNotExist(serr) {
+		t.Errorf("expected no capture, got %v", serr)
+	}
+}
@@ -0,0 +1,125 @@
+package screenshot
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"golang.org/x/image/bmp"
+)
+
+const (
+	// Size of synthetic pages in pixels (portrait, like a Kindle page)
+	syntheticWidth  = 300
+	syntheticHeight = 400
+
+	// syntheticBlock is the size of the random black/white blocks on each page
+	syntheticBlock = 10
+)
+
+// SyntheticCapturer produces deterministic pages without a display
+// The first captures return distinct random-block pages; once they are used up
+// every capture returns the same gray "end of book" page, which triggers end
+// detection just like Kindle's rating screens. For tests and CI only
+type SyntheticCapturer struct {
+	mu       sync.Mutex
+	pages    int
+	captured int
+	options  CaptureOptions
+}
+
+// NewSyntheticCapturer creates a Capturer that yields pages distinct pages
+func NewSyntheticCapturer(pages int) Capturer {
+	return &SyntheticCapturer{pages: pages, options: DefaultCaptureOptions()}
+}
+
+// Configure applies capture settings for the current conversion
+func (c *SyntheticCapturer) Configure(options CaptureOptions) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.options = options
+}
+
+// CaptureFrontmostWindow writes the next synthetic page
+func (c *SyntheticCapturer) CaptureFrontmostWindow(outputPath string) error {
+	return c.CaptureWithoutActivation(outputPath)
+}
+
+// CaptureWithoutActivation writes the next synthetic page
+func (c *SyntheticCapturer) CaptureWithoutActivation(outputPath string) error {
+	c.mu.Lock()
+	c.captured++
+	index := c.captured
+	quality := c.options.Quality
+	c.mu.Unlock()
+
+	var img image.Image
+	if index <= c.pages {
+		img = syntheticPage(index)
+	} else {
+		img = syntheticEndPage()
+	}
+
+	out, err := os.Create(outputPath)
+	if err != nil {
+		return fmt.Errorf("failed to create screenshot file: %w", err)
+	}
+	defer out.Close()
+
+	ext := strings.ToLower(filepath.Ext(outputPath))
+	if ext == ".jpg" || ext == ".jpeg" {
+		if quality < 1 || quality > 100 {
+			quality = jpeg.DefaultQuality
+		}
+		err = jpeg.Encode(out, img, &jpeg.Options{Quality: quality})
+	} else if ext == ".bmp" {
+		err = bmp.Encode(out, img)
+	} else {
+		err = png.Encode(out, img)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to encode screenshot: %w", err)
+	}
+	return nil
+}
+
+// syntheticPage draws random black and white blocks seeded by the page index
+// Two different pages match on about half their blocks, well below the 90%
+// similarity that counts as "the same page"
+func syntheticPage(index int) image.Image {
+	rng := rand.New(rand.NewSource(int64(index)))
+	img := image.NewRGBA(image.Rect(0, 0, syntheticWidth, syntheticHeight))
+	for by := 0; by < syntheticHeight; by += syntheticBlock {
+		for bx := 0; bx < syntheticWidth; bx += syntheticBlock {
+			c := color.RGBA{R: 255, G: 255, B: 255, A: 255}
+			if rng.Intn(2) == 0 {
+				c = color.RGBA{A: 255}
+			}
+			for y := by; y < by+syntheticBlock; y++ {
+				for x := bx; x < bx+syntheticBlock; x++ {
+					img.SetRGBA(x, y, c)
+				}
+			}
+		}
+	}
+	return img
+}
+
+// syntheticEndPage is the uniform gray page returned after the last distinct page
+func syntheticEndPage() image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, syntheticWidth, syntheticHeight))
+	gray := color.RGBA{R: 200, G: 200, B: 200, A: 255}
+	for y := 0; y < syntheticHeight; y++ {
+		for x := 0; x < syntheticWidth; x++ {
+			img.SetRGBA(x, y, gray)
+		}
+	}
+	return img
+}
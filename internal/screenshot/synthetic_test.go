@@ -0,0 +1,77 @@
+package screenshot
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/oumi/k2p/internal/imageprocessing"
+)
+
+func TestSyntheticCapturer(t *testing.T) {
+	tmpDir := t.TempDir()
+	capturer := NewSyntheticCapturer(3)
+
+	var paths []string
+	for i := 0; i < 5; i++ {
+		path := filepath.Join(tmpDir, fmt.Sprintf("page_%d.png", i))
+		if err := capturer.CaptureWithoutActivation(path); err != nil {
+			t.Fatalf("capture %d failed: %v", i, err)
+		}
+		paths = append(paths, path)
+	}
+
+	// Distinct pages must look like page turns (below 90% similarity)
+	for i := 1; i < 3; i++ {
+		similarity, err := imageprocessing.CompareImages(paths[i-1], paths[i])
+		if err != nil {
+			t.Fatalf("compare failed: %v", err)
+		}
+		if similarity >= 0.90 {
+			t.Errorf("pages %d and %d are %.2f%% similar", i-1, i, similarity*100)
+		}
+	}
+
+	// After the distinct pages every capture is the identical end page
+	similarity, err := imageprocessing.CompareImages(paths[3], paths[4])
+	if err != nil {
+		t.Fatalf("compare failed: %v", err)
+	}
+	if similarity < 0.995 {
+		t.Errorf("end pages are only %.2f%% similar", similarity*100)
+	}
+
+	t.Run("JPEG output", func(t *testing.T) {
+		capturer := NewSyntheticCapturer(1)
+		capturer.Configure(CaptureOptions{Quality: 80})
+		path := filepath.Join(tmpDir, "page.jpg")
+		if err := capturer.CaptureWithoutActivation(path); err != nil {
+			t.Fatalf("capture failed: %v", err)
+		}
+		if _, err := imageprocessing.DetectBlankFile(path); err != nil {
+			t.Errorf("JPEG page not decodable: %v", err)
+		}
+	})
+}
+
+func TestCaptureCurrentPage(t *testing.T) {
+	img, err := CaptureCurrentPage(NewSyntheticCapturer(1))
+	if err != nil {
+		t.Fatalf("CaptureCurrentPage failed: %v", err)
+	}
+	if got := img.Bounds().Size(); got.X != syntheticWidth || got.Y != syntheticHeight {
+		t.Errorf("expected %dx%d page, got %v", syntheticWidth, syntheticHeight, got)
+	}
+
+	if _, err := CaptureCurrentPage(&failingCapturer{}); err == nil {
+		t.Error("expected capture error to be returned")
+	}
+}
+
+type failingCapturer struct{}
+
+func (c *failingCapturer) CaptureFrontmostWindow(path string) error { return fmt.Errorf("no display") }
+func (c *failingCapturer) CaptureWithoutActivation(path string) error {
+	return fmt.Errorf("no display")
+}
+func (c *failingCapturer) Configure(options CaptureOptions) {}
@@ -0,0 +1,53 @@
+package version
+
+import (
+	"encoding/json"
+	"fmt"
+	"runtime"
+)
+
+// Build information, overridden at build time via
+// -ldflags "-X github.com/oumi/k2p/internal/version.Version=..."
+var (
+	Version   = "dev"
+	BuildTime = "unknown"
+	GitCommit = "unknown"
+)
+
+// Info is the version information of the running binary
+type Info struct {
+	Version   string `json:"version"`
+	BuildTime string `json:"buildTime"`
+	GitCommit string `json:"gitCommit"`
+	GoVersion string `json:"goVersion"`
+}
+
+// Get returns the version information of the running binary
+func Get() Info {
+	return Info{
+		Version:   Version,
+		BuildTime: BuildTime,
+		GitCommit: GitCommit,
+		GoVersion: runtime.Version(),
+	}
+}
+
+// String returns the human-readable version output
+func (i Info) String() string {
+	return fmt.Sprintf("k2p version %s\nBuilt: %s (commit %s)", i.Version, i.BuildTime, i.GitCommit)
+}
+
+// Short returns a one-line identifier such as "k2p v1.2.0 (abc1234)"
+// Used for the startup banner and the PDF Producer field
+func (i Info) Short() string {
+	return fmt.Sprintf("k2p %s (%s)", i.Version, i.GitCommit)
+}
+
+// JSON returns the machine-readable version output
+func (i Info) JSON() (string, error) {
+	data, err := json.Marshal(i)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode version info: %w", err)
+	}
+	return string(data), nil
+}
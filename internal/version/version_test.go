@@ -0,0 +1,27 @@
+package version
+
+import (
+	"encoding/json"
+	"runtime"
+	"testing"
+)
+
+func TestInfoJSON(t *testing.T) {
+	out, err := Get().JSON()
+	if err != nil {
+		t.Fatalf("JSON() failed: %v", err)
+	}
+
+	var decoded map[string]string
+	if err := json.Unmarshal([]byte(out), &decoded); err != nil {
+		t.Fatalf("output is not valid JSON: %v\n%s", err, out)
+	}
+	for _, key := range []string{"version", "buildTime", "gitCommit", "goVersion"} {
+		if decoded[key] == "" {
+			t.Errorf("missing %q in %s", key, out)
+		}
+	}
+	if decoded["goVersion"] != runtime.Version() {
+		t.Errorf("expected goVersion %s, got %s", runtime.Version(), decoded["goVersion"])
+	}
+}